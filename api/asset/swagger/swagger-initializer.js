@@ -15,6 +15,14 @@ window.onload = function() {
       {
         url: "/docs/health_swagger.yaml",
         name: "Health Endpoint"
+      },
+      {
+        url: "/docs/master_swagger.yaml",
+        name: "Master Data"
+      },
+      {
+        url: "/docs/transaction_swagger.yaml",
+        name: "Transactions"
       }
     ],
     dom_id: '#swagger-ui',
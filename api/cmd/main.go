@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
-	"github.com/joho/godotenv"
 	"github.com/gofiber/adaptor/v2"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
@@ -21,92 +26,245 @@ import (
 	authRoutes "api/internal/routes/auth"
 	authServices "api/internal/services/auth"
 	"api/internal/services/database"
+
+	// Master imports
+	masterHandlers "api/internal/handlers/master"
+	masterRepositories "api/internal/repositories/master"
+	masterRoutes "api/internal/routes/master"
+	masterServices "api/internal/services/master"
+
+	// Transaction imports
+	transactionHandlers "api/internal/handlers/transaction"
+	transactionRepositories "api/internal/repositories/transaction"
+	transactionRoutes "api/internal/routes/transaction"
+	transactionServices "api/internal/services/transaction"
 )
 
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
+	// Load configuration from the environment, .env and CONFIG_FILE; lists every invalid key
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Stops what main starts, in reverse order, on SIGTERM or SIGINT
+	lifecycle := pkg.NewLifecycle()
+
+	// Structured logger for the app, GORM and the standard log package
+	appLogger, logFile, err := pkg.NewLogger(pkg.LoggerConfig{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+		Stdout: cfg.Log.Stdout,
+		File: pkg.RotatingFileConfig{
+			Path:       cfg.Log.Location,
+			MaxSize:    int64(cfg.Log.MaxSize) << 20,
+			MaxAge:     cfg.Log.MaxAge,
+			MaxBackups: cfg.Log.MaxBackups,
+		},
+	})
+	if err != nil {
+		log.Fatal("Failed to set up logging:", err)
 	}
+	slog.SetDefault(appLogger)
+	lifecycle.OnStop("log file", func(ctx context.Context) error {
+		return logFile.Close()
+	})
+	log.Printf("Effective configuration:\n%s", cfg)
 
 	// Initialize database
-	config.InitDatabase()
-	defer config.CloseDatabase()
+	config.InitDatabase(cfg)
+	lifecycle.OnStop("database", func(ctx context.Context) error {
+		config.CloseDatabase()
+		return nil
+	})
 
 	// Test database connection
 	if err := config.TestConnection(); err != nil {
-		log.Fatal("Database connection test failed:", err)
+		fatal(lifecycle, cfg.App.ShutdownTimeout, "Database connection test failed:", err)
 	}
 
-	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
+	// Refuse to start when the tables drift from the models
+	if err := database.NewSchemaService(config.GetDB()).Verify(); err != nil {
+		fatal(lifecycle, cfg.App.ShutdownTimeout, err)
+	}
+
+	// Initialize Fiber app; c.IP() is the client address forwarded by a trusted proxy
+	app := fiber.New(middlewares.TrustProxy(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
 			}
 
-			// Log error
-			pkg.LogErrorf(err, "Fiber error on %s %s", c.Method(), c.Path())
+			// The error is logged with the request by RequestLogger
 
 			return c.Status(code).JSON(fiber.Map{
-				"error":   true,
-				"message": err.Error(),
+				"error":      true,
+				"message":    err.Error(),
+				"request_id": c.Locals(middlewares.RequestIDLocal),
 			})
 		},
-	})
+	}, cfg.Proxy))
+
+	// Tag every request with X-Request-ID and log it; first so errors and panics are logged too
+	app.Use(middlewares.RequestID())
+	app.Use(middlewares.RequestLogger(appLogger))
 
 	// Add panic recovery middleware
 	app.Use(recover.New(recover.Config{
-		EnableStackTrace: os.Getenv("APP_DEBUG") == "true",
+		EnableStackTrace: cfg.App.Debug,
 	}))
 
 	// Add CORS middleware
-	app.Use(middlewares.NewCORS())
+	app.Use(middlewares.NewCORS(cfg.CORS))
 
 	// Add Prometheus metrics middleware
 	app.Use(middlewares.PrometheusMiddleware())
 
-	// Add logger middleware if debug is enabled
-	if os.Getenv("APP_DEBUG") == "true" {
-		app.Use(logger.New())
-	}
-
 	// Setup auth dependencies
 	userRepo := authRepositories.NewUserRepository(config.GetDB())
-	jwtService := authServices.NewJWTService()
-	authService := authServices.NewAuthService(userRepo, jwtService)
-	authHandler := authHandlers.NewAuthHandler(authService)
-	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService)
+	// Token revocation store: database by default, AUTH_TOKEN_STORE=memory for a single instance
+	tokenStore := authRepositories.NewTokenStore(config.GetDB())
+	if cfg.Auth.TokenStore == "memory" {
+		tokenStore = authRepositories.NewMemoryTokenStore()
+	}
+	roleRepo := authRepositories.NewRoleRepository(config.GetDB())
+	// Refuses default HS256 secrets unless APP_DEBUG is true
+	signingKeys, err := authServices.LoadSigningKeys(cfg.JWT, cfg.App.Debug)
+	if err != nil {
+		fatal(lifecycle, cfg.App.ShutdownTimeout, "Failed to load JWT signing keys:", err)
+	}
+	jwtService := authServices.NewJWTService(tokenStore, signingKeys, cfg.JWT)
+	jwksHandler := authHandlers.NewJWKSHandler(signingKeys)
+	mailer := pkg.NewMailer(cfg.Mail.Driver, cfg.Mail.LogPath, pkg.SMTPConfig{
+		Host:     cfg.Mail.SMTPHost,
+		Port:     strconv.Itoa(cfg.Mail.SMTPPort),
+		Username: cfg.Mail.SMTPUsername,
+		Password: cfg.Mail.SMTPPassword,
+		From:     cfg.Mail.From,
+	})
+	verificationPolicy := authServices.VerificationPolicy(cfg.EmailVerification.Policy)
+	emailVerificationRepo := authRepositories.NewEmailVerificationRepository(config.GetDB())
+	emailVerificationService := authServices.NewEmailVerificationService(userRepo, emailVerificationRepo, jwtService, mailer, cfg.EmailVerification)
+	emailVerificationHandler := authHandlers.NewEmailVerificationHandler(emailVerificationService)
+	loginAttemptStore := authRepositories.NewLoginAttemptStore(config.GetDB())
+	if cfg.Auth.LoginAttemptStore == "memory" {
+		loginAttemptStore = authRepositories.NewMemoryLoginAttemptStore()
+	}
+	loginAttemptService := authServices.NewLoginAttemptService(loginAttemptStore, userRepo, cfg.LoginAttempts)
+	twoFactorRepo := authRepositories.NewTwoFactorRepository(config.GetDB())
+	twoFactorService := authServices.NewTwoFactorService(twoFactorRepo, userRepo, jwtService, loginAttemptService, cfg.TOTP)
+	twoFactorHandler := authHandlers.NewTwoFactorHandler(twoFactorService)
+	authService := authServices.NewAuthService(userRepo, roleRepo, jwtService, emailVerificationService, verificationPolicy, twoFactorService)
+	loginAttemptHandler := authHandlers.NewLoginAttemptHandler(loginAttemptService)
+	authHandler := authHandlers.NewAuthHandler(authService, loginAttemptService)
+	roleService := authServices.NewRoleService(roleRepo, userRepo, jwtService)
+	roleHandler := authHandlers.NewRoleHandler(roleService)
+	userWarehouseRepo := authRepositories.NewUserWarehouseRepository(config.GetDB())
+	userWarehouseService := authServices.NewUserWarehouseService(userWarehouseRepo, userRepo, jwtService)
+	userWarehouseHandler := authHandlers.NewUserWarehouseHandler(userWarehouseService)
+	passwordResetRepo := authRepositories.NewPasswordResetRepository(config.GetDB())
+	passwordResetService := authServices.NewPasswordResetService(userRepo, passwordResetRepo, jwtService, mailer, cfg.PasswordReset)
+	passwordResetHandler := authHandlers.NewPasswordResetHandler(passwordResetService)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, roleService, verificationPolicy)
+	apiKeyRepo := authRepositories.NewAPIKeyRepository(config.GetDB())
+	apiKeyService := authServices.NewAPIKeyService(apiKeyRepo, userRepo, roleService)
+	apiKeyHandler := authHandlers.NewAPIKeyHandler(apiKeyService)
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(apiKeyService, roleService, verificationPolicy)
+	sessionHandler := authHandlers.NewSessionHandler(authServices.NewSessionService(tokenStore))
+	// OIDC sign-in stays disabled unless OIDC_ISSUER_URL is set
+	var oidcProvider *pkg.OIDCProvider
+	if cfg.OIDC.IssuerURL != "" {
+		oidcProvider = pkg.NewOIDCProvider(pkg.OIDCConfig{
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		})
+	}
+	oidcRepo := authRepositories.NewOIDCRepository(config.GetDB())
+	oidcService := authServices.NewOIDCService(oidcProvider, oidcRepo, userRepo, roleRepo, jwtService, twoFactorService)
+	oidcHandler := authHandlers.NewOIDCHandler(oidcService)
+
+	// Setup master dependencies
+	warehouseRepo := masterRepositories.NewWarehouseRepository(config.GetDB())
+	warehouseService := masterServices.NewWarehouseService(warehouseRepo)
+	warehouseHandler := masterHandlers.NewWarehouseHandler(warehouseService)
+	productRepo := masterRepositories.NewProductRepository(config.GetDB())
+	productService := masterServices.NewProductService(productRepo, filepath.Join(cfg.Upload.Dir, "images", "products"), int64(cfg.Upload.MaxImageSize))
+	productHandler := masterHandlers.NewProductHandler(productService)
+
+	// Setup transaction dependencies
+	transactionRepo := transactionRepositories.NewTransactionRepository(config.GetDB())
+	transactionService := transactionServices.NewTransactionService(transactionRepo)
+	transactionHandler := transactionHandlers.NewTransactionHandler(transactionService)
+	stockRepo := transactionRepositories.NewStockRepository(config.GetDB())
+	stockService := transactionServices.NewStockService(stockRepo)
+	stockHandler := transactionHandlers.NewStockHandler(stockService)
+	transferRepo := transactionRepositories.NewTransferRepository(config.GetDB())
+	transferService := transactionServices.NewTransferService(transferRepo)
+	transferHandler := transactionHandlers.NewTransferHandler(transferService)
 
 	// Initialize and start database metrics collection
 	metricsService := database.NewMetricsService(config.GetDB())
-	metricsService.StartMetricsCollection()
+	lifecycle.Go("database metrics collection", metricsService.RunMetricsCollection)
+
+	// Expired revocations, refresh tokens and sessions are deleted here instead of on every sign-in
+	tokenPurgeService := authServices.NewTokenPurgeService(tokenStore, 15*time.Minute)
+	lifecycle.Go("expired token purge", tokenPurgeService.Run)
 
 	// Setup routes
-	setupRoutes(app, authHandler, jwtMiddleware)
+	setupRoutes(app, cfg, authHandler, passwordResetHandler, emailVerificationHandler, twoFactorHandler, jwksHandler, apiKeyHandler, sessionHandler, oidcHandler, roleHandler, userWarehouseHandler, loginAttemptHandler, warehouseHandler, productHandler, transactionHandler, stockHandler, transferHandler, jwtMiddleware, apiKeyMiddleware)
 
-	// Get server configuration
-	host := os.Getenv("APP_HOST")
-	port := os.Getenv("APP_PORT")
-	if host == "" {
-		host = "localhost"
-	}
-	if port == "" {
-		port = "8080"
-	}
+	// Shutting the server down stops accepting connections and waits for in-flight requests
+	lifecycle.OnStop("HTTP server", app.ShutdownWithContext)
 
 	// Start server
-	address := host + ":" + port
+	address := cfg.App.Address()
 	log.Printf("Server starting on http://%s", address)
-	
-	if err := app.Listen(address); err != nil {
-		log.Fatal("Failed to start server:", err)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.Listen(address)
+	}()
+
+	exitCode := 0
+	select {
+	case <-signals.Done():
+		log.Printf("Shutdown signal received, draining requests for up to %s", cfg.App.ShutdownTimeout)
+	case err := <-serverErr:
+		log.Printf("Failed to start server: %v", err)
+		exitCode = 1
 	}
+	// A second signal kills the process without waiting
+	stopSignals()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	if err := lifecycle.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown incomplete: %v", err)
+		exitCode = 1
+	}
+	cancel()
+	log.Println("Server stopped")
+	os.Exit(exitCode)
+}
+
+// fatal logs v like log.Fatal, but first stops what lifecycle has started so the
+// database is closed and the log file flushed before exiting with status 1
+func fatal(lifecycle *pkg.Lifecycle, timeout time.Duration, v ...any) {
+	log.Print(v...)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	if err := lifecycle.Shutdown(ctx); err != nil {
+		log.Printf("Shutdown incomplete: %v", err)
+	}
+	cancel()
+	os.Exit(1)
 }
 
 // setupRoutes configures all application routes
-func setupRoutes(app *fiber.App, authHandler *authHandlers.AuthHandler, jwtMiddleware *middlewares.JWTMiddleware) {
+func setupRoutes(app *fiber.App, cfg *config.Config, authHandler *authHandlers.AuthHandler, passwordResetHandler *authHandlers.PasswordResetHandler, emailVerificationHandler *authHandlers.EmailVerificationHandler, twoFactorHandler *authHandlers.TwoFactorHandler, jwksHandler *authHandlers.JWKSHandler, apiKeyHandler *authHandlers.APIKeyHandler, sessionHandler *authHandlers.SessionHandler, oidcHandler *authHandlers.OIDCHandler, roleHandler *authHandlers.RoleHandler, userWarehouseHandler *authHandlers.UserWarehouseHandler, loginAttemptHandler *authHandlers.LoginAttemptHandler, warehouseHandler *masterHandlers.WarehouseHandler, productHandler *masterHandlers.ProductHandler, transactionHandler *transactionHandlers.TransactionHandler, stockHandler *transactionHandlers.StockHandler, transferHandler *transactionHandlers.TransferHandler, jwtMiddleware *middlewares.JWTMiddleware, apiKeyMiddleware *middlewares.APIKeyMiddleware) {
 	// Prometheus metrics endpoint
 	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
 
@@ -117,9 +275,21 @@ func setupRoutes(app *fiber.App, authHandler *authHandlers.AuthHandler, jwtMiddl
 	
 	// Serve YAML documentation files
 	app.Static("/docs", "./internal/docs")
+
+	// Serve uploaded images (e.g. /assets/images/products/<file>)
+	app.Static("/assets/images", filepath.Join(cfg.Upload.Dir, "images"))
 	
 	// Setup auth routes
-	authRoutes.SetupAuthRoutes(app, authHandler, jwtMiddleware)
+	authRoutes.SetupAuthRoutes(app, authHandler, passwordResetHandler, emailVerificationHandler, twoFactorHandler, jwksHandler, apiKeyHandler, sessionHandler, oidcHandler, jwtMiddleware)
+
+	// Setup admin routes
+	authRoutes.SetupAdminRoutes(app, roleHandler, userWarehouseHandler, loginAttemptHandler, jwtMiddleware)
+
+	// Setup master routes
+	masterRoutes.SetupMasterRoutes(app, warehouseHandler, productHandler, jwtMiddleware, apiKeyMiddleware)
+
+	// Setup transaction routes
+	transactionRoutes.SetupTransactionRoutes(app, transactionHandler, stockHandler, transferHandler, jwtMiddleware, apiKeyMiddleware)
 	
 	// API v1 group
 	v1 := app.Group("/api/v1")
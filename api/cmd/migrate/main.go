@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"api/config"
+	"api/internal/services/database"
+)
+
+const usage = `Usage: go run ./cmd/migrate [flags] <command> [args]
+
+Commands:
+  up [N]            apply all pending migrations, or only the next N
+  down [N]          roll back the last N applied migrations (default 1, "all" for every one)
+  status            list migrations and whether they are applied
+  create <name>     create an empty up/down migration pair
+  force <version>   record <version> as the current clean version without running SQL
+
+Flags:
+`
+
+// Applies the numbered SQL files in database/migrations and records them in schema_migrations.
+//
+//	go run ./cmd/migrate up
+//	go run ./cmd/migrate status
+//	go run ./cmd/migrate create add_supplier_table
+func main() {
+	path := flag.String("path", "database/migrations", "directory holding the migration files")
+	lockTimeout := flag.Duration("lock-timeout", 30*time.Second, "how long to wait for another migration process")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command, args := flag.Arg(0), flag.Args()[1:]
+
+	// create only touches the filesystem
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal("create requires a migration name")
+		}
+		migration, err := database.CreateMigration(*path, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Printf("Created %s\n", migration.UpPath)
+		fmt.Printf("Created %s\n", migration.DownPath)
+		return
+	}
+
+	// Load configuration from the environment, .env and CONFIG_FILE
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize database
+	config.InitDatabase(cfg)
+	defer config.CloseDatabase()
+
+	migrationService := database.NewMigrationService(config.GetDB(), *path, *lockTimeout)
+
+	if err := run(migrationService, command, args); err != nil {
+		config.CloseDatabase()
+		log.Fatalf("Migration %s failed: %v", command, err)
+	}
+}
+
+func run(migrationService *database.MigrationService, command string, args []string) error {
+	switch command {
+	case "up":
+		steps, err := parseSteps(args, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := migrationService.Up(steps)
+		for _, migration := range applied {
+			fmt.Printf("Applied %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+		return err
+
+	case "down":
+		steps, err := parseSteps(args, 1)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrationService.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Rolled back %06d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+		return err
+
+	case "status":
+		statuses, err := migrationService.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Dirty:
+				state = "DIRTY"
+			case status.Applied:
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Missing {
+				state += " (file missing)"
+			}
+			fmt.Printf("%06d_%-40s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	case "force":
+		if len(args) != 1 {
+			return fmt.Errorf("force requires a version")
+		}
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		if err := migrationService.Force(version); err != nil {
+			return err
+		}
+		fmt.Printf("Forced version %d\n", version)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// parseSteps reads the optional step count; "all" means no limit
+func parseSteps(args []string, fallback int) (int, error) {
+	if len(args) == 0 {
+		return fallback, nil
+	}
+	if args[0] == "all" {
+		return 0, nil
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid step count %q", args[0])
+	}
+	return steps, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"api/pkg/oidctest"
+)
+
+// Runs a local OpenID Connect provider that signs in any email without a password, so the
+// OIDC login can be tried without a real identity provider. Development only.
+//
+//	go run ./cmd/oidc-stub
+//
+// It uses OIDC_ISSUER_URL (default http://localhost:9000), OIDC_CLIENT_ID and OIDC_CLIENT_SECRET
+// from .env and listens on the port of the issuer URL.
+func main() {
+	// Load environment variables
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found, using system environment variables")
+	}
+
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		issuer = "http://localhost:9000"
+	}
+	issuerURL, err := url.Parse(issuer)
+	if err != nil || issuerURL.Port() == "" {
+		log.Fatalf("OIDC_ISSUER_URL must be a URL with a port, got %q", issuer)
+	}
+
+	provider, err := oidctest.New(issuer, os.Getenv("OIDC_CLIENT_ID"), os.Getenv("OIDC_CLIENT_SECRET"))
+	if err != nil {
+		log.Fatalf("Failed to create OIDC stub: %v", err)
+	}
+
+	log.Printf("OIDC stub issuer %s listening on :%s", issuer, issuerURL.Port())
+	log.Fatal(http.ListenAndServe(":"+issuerURL.Port(), provider.Handler()))
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"api/config"
+	transactionRepositories "api/internal/repositories/transaction"
+	"api/internal/services/database"
+	transactionServices "api/internal/services/transaction"
+)
+
+// Rebuilds per-warehouse stock balances from the transaction ledger and refreshes Product.Stock.
+//
+//	go run ./cmd/reconcile            # rebuild balances
+//	go run ./cmd/reconcile -dry-run   # only report differences
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report differences without writing balances")
+	flag.Parse()
+
+	// Load configuration from the environment, .env and CONFIG_FILE
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize database
+	config.InitDatabase(cfg)
+	defer config.CloseDatabase()
+
+	// stock_balances only exists after migration 000003; fail before touching it otherwise
+	if err := database.NewSchemaService(config.GetDB()).Verify(); err != nil {
+		config.CloseDatabase()
+		log.Fatal(err)
+	}
+
+	stockRepo := transactionRepositories.NewStockRepository(config.GetDB())
+	stockService := transactionServices.NewStockService(stockRepo)
+
+	result, err := stockService.Reconcile(context.Background(), *dryRun)
+	if err != nil {
+		config.CloseDatabase()
+		log.Fatalf("Stock reconciliation failed: %v", err)
+	}
+
+	fmt.Printf("Ledger balances: %d\n", result.Balances)
+	fmt.Printf("Differences: %d\n", len(result.Differences))
+	for _, diff := range result.Differences {
+		fmt.Printf("  product %d / warehouse %d: recorded %.2f, ledger %.2f\n",
+			diff.ProductID, diff.WarehouseID, diff.Recorded, diff.Ledger)
+	}
+	for _, negative := range result.Negative {
+		fmt.Printf("Warning: product %d / warehouse %d has negative ledger stock %.2f\n",
+			negative.ProductID, negative.WarehouseID, negative.Ledger)
+	}
+
+	if result.Applied {
+		fmt.Println("Stock balances rebuilt and product stock synced")
+	} else {
+		fmt.Println("Dry run: no changes written")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"api/config"
+	"api/internal/services/database"
+	transactionRepositories "api/internal/repositories/transaction"
+	transactionServices "api/internal/services/transaction"
+)
+
+// Seeds fixture data. Sets build on each other: minimal < demo < load-test.
+//
+//	go run ./cmd/seed -set demo
+//	go run ./cmd/seed -set load-test -size 5   # 5000 extra products, 10000 transactions
+func main() {
+	// Load configuration from the environment, .env and CONFIG_FILE
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defaultPassword := os.Getenv("SEED_PASSWORD")
+	if defaultPassword == "" {
+		defaultPassword = "password"
+	}
+
+	set := flag.String("set", database.SeedSetMinimal, "seed set: minimal, demo or load-test")
+	size := flag.Int("size", 1, "load-test products in thousands")
+	password := flag.String("password", defaultPassword, "password for seeded users (env SEED_PASSWORD)")
+	flag.Parse()
+
+	if *size < 1 {
+		log.Fatal("-size must be at least 1")
+	}
+
+	// Initialize database
+	config.InitDatabase(cfg)
+	defer config.CloseDatabase()
+
+	seedService := database.NewSeedService(config.GetDB())
+	result, err := seedService.Run(*set, database.SeedOptions{Password: *password, LoadTestSize: *size})
+	if err != nil {
+		config.CloseDatabase()
+		log.Fatalf("Seeding %s failed: %v", *set, err)
+	}
+
+	fmt.Printf("Seed set %q applied\n", *set)
+	fmt.Printf("  users: %d, warehouses: %d, products: %d, transactions: %d created\n",
+		result.Users, result.Warehouses, result.Products, result.Transactions)
+
+	// Seeded transactions bypass the stock service, so rebuild balances from the ledger
+	if result.Transactions > 0 {
+		stockService := transactionServices.NewStockService(transactionRepositories.NewStockRepository(config.GetDB()))
+		if _, err := stockService.Reconcile(context.Background(), false); err != nil {
+			config.CloseDatabase()
+			log.Fatalf("Stock reconciliation failed: %v", err)
+		}
+		fmt.Println("Stock balances rebuilt")
+	}
+
+	fmt.Printf("Sign in as admin@pseudo.com with password %q\n", *password)
+}
@@ -0,0 +1,431 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Config is the validated application configuration returned by Load
+type Config struct {
+	App      AppConfig
+	Database DatabaseConfig
+	JWT      JWTConfig
+	Auth     AuthConfig
+	CORS     CORSConfig
+	Proxy    ProxyConfig
+	Upload   UploadConfig
+	Log      LogConfig
+
+	Mail              MailConfig
+	PasswordReset     PasswordResetConfig
+	EmailVerification EmailVerificationConfig
+	LoginAttempts     LoginAttemptsConfig
+	TOTP              TOTPConfig
+	OIDC              OIDCConfig
+
+	// settings records every key with its effective value and source, for String
+	settings []Setting
+}
+
+type AppConfig struct {
+	Host  string
+	Port  int
+	Debug bool
+	// ShutdownTimeout bounds draining in-flight requests, stopping workers and closing the
+	// database after SIGTERM or SIGINT
+	ShutdownTimeout time.Duration
+}
+
+// Address returns the host:port the server listens on
+func (c AppConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+type DatabaseConfig struct {
+	Host            string
+	Port            int
+	User            string
+	Password        string
+	Name            string
+	MaxIdleConns    int
+	MaxOpenConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// JWTConfig holds the token signing settings. Without PrivateKeyFile tokens are signed with the
+// HS256 secrets.
+type JWTConfig struct {
+	Secret         string
+	RefreshSecret  string
+	PrivateKeyFile string
+	PublicKeyFiles []string
+	AccessTTL      time.Duration
+	RefreshTTL     time.Duration
+}
+
+// AuthConfig selects where revocations and sign-in failures are stored: "database" or "memory"
+type AuthConfig struct {
+	TokenStore        string
+	LoginAttemptStore string
+}
+
+// CORSConfig is the CORS policy for every route, with overrides for route groups
+type CORSConfig struct {
+	CORSPolicy
+	Groups []CORSGroup
+}
+
+// CORSPolicy lists the allowed origins, which may be "*" or contain a wildcard subdomain such
+// as https://*.example.com
+type CORSPolicy struct {
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           int // seconds
+}
+
+// CORSGroup replaces the policy for requests under Path, e.g. a stricter policy on /api/v1/auth.
+// Keys it does not set are inherited from the top-level policy.
+type CORSGroup struct {
+	Name string
+	Path string
+	CORSPolicy
+}
+
+// ProxyConfig tells the server which reverse proxies report the client address. Requests from
+// other addresses keep their connection IP, so clients cannot pick their own IP with the header.
+type ProxyConfig struct {
+	// Header carries the client IP set by the proxy, e.g. X-Real-IP; empty uses the connection IP
+	Header string
+	// TrustedProxies are the IP addresses or CIDR ranges of the proxies whose Header is used
+	TrustedProxies []string
+}
+
+type UploadConfig struct {
+	Dir          string // uploaded images are stored in <Dir>/images/<folder>
+	MaxImageSize int    // bytes
+}
+
+type LogConfig struct {
+	Level      string // debug, info, warn or error
+	Format     string // json or text
+	Location   string // log file; empty logs to stdout only
+	Stdout     bool   // also log to stdout when Location is set
+	MaxSize    int    // megabytes before the file is rotated
+	MaxAge     time.Duration
+	MaxBackups int           // rotated files to keep; 0 keeps all
+	SlowQuery  time.Duration // SQL statements slower than this are logged as warnings
+}
+
+// MailConfig selects how emails are delivered: "log" appends them to LogPath, "smtp" sends them
+// through the SMTP server
+type MailConfig struct {
+	Driver       string
+	LogPath      string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// PasswordResetConfig holds the frontend page the reset link points to and how long it is valid
+type PasswordResetConfig struct {
+	URL string
+	TTL time.Duration
+}
+
+// EmailVerificationConfig holds what unverified users may do (allow, read_only or block), the
+// frontend page the verification link points to and how long it is valid
+type EmailVerificationConfig struct {
+	Policy string
+	URL    string
+	TTL    time.Duration
+}
+
+// LoginAttemptsConfig holds the sign-in failure limits per email and per IP, and the lock that
+// starts at LockoutBase and doubles up to LockoutMax
+type LoginAttemptsConfig struct {
+	MaxAttempts   int
+	MaxIPAttempts int
+	LockoutBase   time.Duration
+	LockoutMax    time.Duration
+	Window        time.Duration // failures older than this are forgotten
+}
+
+// TOTPConfig holds the issuer authenticator apps show for two-factor sign-in
+type TOTPConfig struct {
+	Issuer string
+}
+
+// OIDCConfig holds the OpenID Connect provider; sign-in with it is disabled without IssuerURL
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Setting is one configuration key with its effective value and where it came from:
+// "env", ".env", the CONFIG_FILE path, "default", or "CORS_*" for a CORS group key inherited
+// from the top-level policy
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// ValidationError lists every missing or invalid configuration key
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Default returns the configuration used for keys that are not set
+func Default() Config {
+	return Config{
+		App: AppConfig{
+			Host:            "localhost",
+			Port:            8080,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Port:            3306,
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: time.Hour,
+		},
+		JWT: JWTConfig{
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 7 * 24 * time.Hour,
+		},
+		Auth: AuthConfig{
+			TokenStore:        "database",
+			LoginAttemptStore: "database",
+		},
+		CORS: CORSConfig{
+			CORSPolicy: CORSPolicy{
+				AllowOrigins:  []string{"*"},
+				AllowMethods:  []string{"GET", "POST", "HEAD", "PUT", "DELETE", "PATCH", "OPTIONS"},
+				AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-API-Key", "X-Client-ID", "X-Client-Version", "X-Request-ID"},
+				ExposeHeaders: []string{"Content-Length", "Content-Range", "X-Total-Count", "X-Page-Count", "X-Request-ID"},
+				MaxAge:        86400, // 24 hours
+			},
+		},
+		Upload: UploadConfig{
+			Dir:          "./asset",
+			MaxImageSize: 2 << 20, // 2 MB
+		},
+		Log: LogConfig{
+			Level:      "info",
+			Format:     "json",
+			Stdout:     true,
+			MaxSize:    100,
+			MaxAge:     24 * time.Hour,
+			MaxBackups: 7,
+			SlowQuery:  200 * time.Millisecond,
+		},
+		Mail: MailConfig{
+			Driver:   "log",
+			LogPath:  "logger/mail.log",
+			SMTPPort: 587,
+		},
+		PasswordReset: PasswordResetConfig{
+			URL: "http://localhost:3000/reset-password",
+			TTL: time.Hour,
+		},
+		EmailVerification: EmailVerificationConfig{
+			Policy: "read_only",
+			URL:    "http://localhost:3000/verify-email",
+			TTL:    24 * time.Hour,
+		},
+		LoginAttempts: LoginAttemptsConfig{
+			MaxAttempts:   5,
+			MaxIPAttempts: 20,
+			LockoutBase:   30 * time.Second,
+			LockoutMax:    15 * time.Minute,
+			Window:        time.Hour,
+		},
+		TOTP: TOTPConfig{
+			Issuer: "pseudo-app",
+		},
+		OIDC: OIDCConfig{
+			Scopes: []string{"openid", "email", "profile"},
+		},
+	}
+}
+
+// Load reads the configuration from environment variables, then .env in the working directory,
+// then the YAML file named by CONFIG_FILE, falling back to Default. Every missing or invalid key
+// is reported at once in a *ValidationError.
+func Load() (*Config, error) {
+	processEnv := make(map[string]bool)
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		processEnv[key] = value != ""
+	}
+
+	// .env never overrides variables that are already set
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	l := &loader{processEnv: processEnv, used: make(map[string]bool)}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := l.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := Default()
+
+	l.string(&cfg.App.Host, "APP_HOST", "app.host")
+	l.int(&cfg.App.Port, "APP_PORT", "app.port", 1, 65535)
+	l.bool(&cfg.App.Debug, "APP_DEBUG", "app.debug")
+	l.duration(&cfg.App.ShutdownTimeout, "APP_SHUTDOWN_TIMEOUT", "app.shutdown_timeout")
+	if cfg.App.ShutdownTimeout <= 0 {
+		l.problem("APP_SHUTDOWN_TIMEOUT must be greater than 0")
+	}
+
+	l.required(&cfg.Database.Host, "DB_HOST", "database.host")
+	l.int(&cfg.Database.Port, "DB_PORT", "database.port", 1, 65535)
+	l.required(&cfg.Database.User, "DB_USER", "database.user")
+	l.secret(&cfg.Database.Password, "DB_PASSWORD", "database.password")
+	l.required(&cfg.Database.Name, "DB_NAME", "database.name")
+	l.int(&cfg.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS", "database.max_idle_conns", 0, 0)
+	l.int(&cfg.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS", "database.max_open_conns", 1, 0)
+	l.duration(&cfg.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME", "database.conn_max_lifetime")
+	if cfg.Database.MaxIdleConns > cfg.Database.MaxOpenConns {
+		l.problem("DB_MAX_IDLE_CONNS (%d) must not exceed DB_MAX_OPEN_CONNS (%d)", cfg.Database.MaxIdleConns, cfg.Database.MaxOpenConns)
+	}
+
+	l.secret(&cfg.JWT.Secret, "JWT_SECRET", "jwt.secret")
+	l.secret(&cfg.JWT.RefreshSecret, "JWT_REFRESH_SECRET", "jwt.refresh_secret")
+	l.string(&cfg.JWT.PrivateKeyFile, "JWT_PRIVATE_KEY_FILE", "jwt.private_key_file")
+	l.list(&cfg.JWT.PublicKeyFiles, "JWT_PUBLIC_KEY_FILES", "jwt.public_key_files")
+	l.durationIn(&cfg.JWT.AccessTTL, time.Minute, "JWT_ACCESS_TTL", "jwt.access_ttl")
+	l.durationIn(&cfg.JWT.RefreshTTL, time.Hour, "JWT_REFRESH_TTL", "jwt.refresh_ttl")
+
+	l.oneOf(&cfg.Auth.TokenStore, "AUTH_TOKEN_STORE", "auth.token_store", "database", "memory")
+	l.oneOf(&cfg.Auth.LoginAttemptStore, "AUTH_LOGIN_ATTEMPT_STORE", "auth.login_attempt_store", "database", "memory")
+
+	l.corsPolicy(&cfg.CORS.CORSPolicy, "CORS_", "cors.")
+	var groups []string
+	l.list(&groups, "CORS_GROUPS", "cors.groups")
+	for _, name := range groups {
+		l.corsGroup(&cfg.CORS, name)
+	}
+
+	l.proxy(&cfg.Proxy)
+
+	l.string(&cfg.Upload.Dir, "UPLOAD_DIR", "upload.dir")
+	l.int(&cfg.Upload.MaxImageSize, "UPLOAD_MAX_IMAGE_SIZE", "upload.max_image_size", 1, 0)
+
+	l.oneOf(&cfg.Log.Level, "LOG_LEVEL", "log.level", "debug", "info", "warn", "error")
+	l.oneOf(&cfg.Log.Format, "LOG_FORMAT", "log.format", "json", "text")
+	l.string(&cfg.Log.Location, "APP_LOGGER_LOCATION", "log.location")
+	l.bool(&cfg.Log.Stdout, "LOG_STDOUT", "log.stdout")
+	l.int(&cfg.Log.MaxSize, "LOG_MAX_SIZE", "log.max_size", 1, 0)
+	l.duration(&cfg.Log.MaxAge, "LOG_MAX_AGE", "log.max_age")
+	l.int(&cfg.Log.MaxBackups, "LOG_MAX_BACKUPS", "log.max_backups", 0, 0)
+	l.duration(&cfg.Log.SlowQuery, "LOG_SLOW_QUERY", "log.slow_query")
+	if cfg.Log.Location == "" && !cfg.Log.Stdout {
+		l.problem("LOG_STDOUT cannot be false without APP_LOGGER_LOCATION, logs would go nowhere")
+	}
+
+	l.oneOf(&cfg.Mail.Driver, "MAIL_DRIVER", "mail.driver", "log", "smtp")
+	l.string(&cfg.Mail.LogPath, "MAIL_LOG_PATH", "mail.log_path")
+	l.string(&cfg.Mail.From, "MAIL_FROM", "mail.from")
+	l.string(&cfg.Mail.SMTPHost, "SMTP_HOST", "mail.smtp_host")
+	l.int(&cfg.Mail.SMTPPort, "SMTP_PORT", "mail.smtp_port", 1, 65535)
+	l.string(&cfg.Mail.SMTPUsername, "SMTP_USERNAME", "mail.smtp_username")
+	l.secret(&cfg.Mail.SMTPPassword, "SMTP_PASSWORD", "mail.smtp_password")
+	if cfg.Mail.Driver == "smtp" {
+		if cfg.Mail.SMTPHost == "" {
+			l.problem("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+		if cfg.Mail.From == "" {
+			l.problem("MAIL_FROM is required when MAIL_DRIVER is smtp")
+		}
+	}
+
+	l.absoluteURL(&cfg.PasswordReset.URL, "PASSWORD_RESET_URL", "password_reset.url")
+	l.durationIn(&cfg.PasswordReset.TTL, time.Minute, "PASSWORD_RESET_TTL", "password_reset.ttl")
+
+	l.oneOf(&cfg.EmailVerification.Policy, "EMAIL_VERIFICATION_POLICY", "email_verification.policy", "allow", "read_only", "block")
+	l.absoluteURL(&cfg.EmailVerification.URL, "EMAIL_VERIFICATION_URL", "email_verification.url")
+	l.durationIn(&cfg.EmailVerification.TTL, time.Minute, "EMAIL_VERIFICATION_TTL", "email_verification.ttl")
+
+	l.int(&cfg.LoginAttempts.MaxAttempts, "LOGIN_MAX_ATTEMPTS", "login_attempts.max_attempts", 1, 0)
+	l.int(&cfg.LoginAttempts.MaxIPAttempts, "LOGIN_IP_MAX_ATTEMPTS", "login_attempts.ip_max_attempts", 1, 0)
+	l.durationIn(&cfg.LoginAttempts.LockoutBase, time.Second, "LOGIN_LOCKOUT_BASE_SECONDS", "login_attempts.lockout_base_seconds")
+	l.durationIn(&cfg.LoginAttempts.LockoutMax, time.Second, "LOGIN_LOCKOUT_MAX_SECONDS", "login_attempts.lockout_max_seconds")
+	l.durationIn(&cfg.LoginAttempts.Window, time.Minute, "LOGIN_ATTEMPT_WINDOW_MINUTES", "login_attempts.window_minutes")
+	if cfg.LoginAttempts.LockoutBase > cfg.LoginAttempts.LockoutMax {
+		l.problem("LOGIN_LOCKOUT_BASE_SECONDS (%d) must not exceed LOGIN_LOCKOUT_MAX_SECONDS (%d)",
+			int(cfg.LoginAttempts.LockoutBase.Seconds()), int(cfg.LoginAttempts.LockoutMax.Seconds()))
+	}
+
+	l.string(&cfg.TOTP.Issuer, "TOTP_ISSUER", "totp.issuer")
+	if cfg.TOTP.Issuer == "" {
+		l.problem("TOTP_ISSUER must not be empty")
+	}
+
+	l.absoluteURL(&cfg.OIDC.IssuerURL, "OIDC_ISSUER_URL", "oidc.issuer_url")
+	l.string(&cfg.OIDC.ClientID, "OIDC_CLIENT_ID", "oidc.client_id")
+	l.secret(&cfg.OIDC.ClientSecret, "OIDC_CLIENT_SECRET", "oidc.client_secret")
+	l.absoluteURL(&cfg.OIDC.RedirectURL, "OIDC_REDIRECT_URL", "oidc.redirect_url")
+	l.scopes(&cfg.OIDC.Scopes, "OIDC_SCOPES", "oidc.scopes")
+	if cfg.OIDC.IssuerURL != "" {
+		if cfg.OIDC.ClientID == "" {
+			l.problem("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+		}
+		if cfg.OIDC.RedirectURL == "" {
+			l.problem("OIDC_REDIRECT_URL is required when OIDC_ISSUER_URL is set")
+		}
+		if !slices.Contains(cfg.OIDC.Scopes, "openid") {
+			l.problem("OIDC_SCOPES must include openid, got %q", strings.Join(cfg.OIDC.Scopes, " "))
+		}
+	}
+
+	l.unknownFileKeys()
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
+
+	cfg.settings = l.settings
+	return &cfg, nil
+}
+
+// String lists the effective configuration with its sources. Secrets are redacted, so the
+// result is safe to log.
+func (c *Config) String() string {
+	width := 0
+	for _, setting := range c.settings {
+		width = max(width, len(setting.Key))
+	}
+
+	var b strings.Builder
+	for _, setting := range c.settings {
+		fmt.Fprintf(&b, "  %-*s = %s (%s)\n", width, setting.Key, setting.Value, setting.Source)
+	}
+	return b.String()
+}
+
+// Settings returns the effective configuration keys in load order, with secrets redacted
+func (c *Config) Settings() []Setting {
+	return append([]Setting(nil), c.settings...)
+}
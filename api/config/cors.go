@@ -0,0 +1,78 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var corsGroupName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// corsPolicy reads the CORS keys under the given prefixes and validates the result
+func (l *loader) corsPolicy(policy *CORSPolicy, keyPrefix, pathPrefix string) {
+	l.list(&policy.AllowOrigins, keyPrefix+"ALLOW_ORIGINS", pathPrefix+"allow_origins")
+	l.list(&policy.AllowMethods, keyPrefix+"ALLOW_METHODS", pathPrefix+"allow_methods")
+	l.list(&policy.AllowHeaders, keyPrefix+"ALLOW_HEADERS", pathPrefix+"allow_headers")
+	l.list(&policy.ExposeHeaders, keyPrefix+"EXPOSE_HEADERS", pathPrefix+"expose_headers")
+	l.bool(&policy.AllowCredentials, keyPrefix+"ALLOW_CREDENTIALS", pathPrefix+"allow_credentials")
+	l.int(&policy.MaxAge, keyPrefix+"MAX_AGE", pathPrefix+"max_age", 0, 0)
+
+	originsKey := keyPrefix + "ALLOW_ORIGINS"
+	if len(policy.AllowOrigins) == 0 {
+		l.problem("%s must list at least one origin", originsKey)
+	}
+	for _, origin := range policy.AllowOrigins {
+		if origin == "*" {
+			// Fiber panics on startup when * is listed next to other origins
+			if len(policy.AllowOrigins) > 1 {
+				l.problem("%s cannot combine * with other origins; use * alone or list the origins", originsKey)
+			}
+			if policy.AllowCredentials {
+				l.problem("%s cannot contain * when %sALLOW_CREDENTIALS is true; list the origins instead", originsKey, keyPrefix)
+			}
+			continue
+		}
+		if !validOrigin(origin) {
+			l.problem("%s has invalid origin %q, expected scheme://host[:port] such as https://app.example.com or https://*.example.com", originsKey, origin)
+		}
+	}
+}
+
+// corsGroup reads the group named in CORS_GROUPS. The group starts from the top-level policy,
+// so it only needs the keys it changes, e.g. CORS_AUTH_PATH and CORS_AUTH_ALLOW_ORIGINS.
+func (l *loader) corsGroup(cors *CORSConfig, name string) {
+	if !corsGroupName.MatchString(name) {
+		l.problem("CORS_GROUPS has invalid group name %q, use lowercase letters, digits and _", name)
+		return
+	}
+	keyPrefix := "CORS_" + strings.ToUpper(name) + "_"
+	pathPrefix := "cors." + name + "."
+
+	group := CORSGroup{Name: name, CORSPolicy: cors.CORSPolicy}
+	l.required(&group.Path, keyPrefix+"PATH", pathPrefix+"path")
+	if group.Path != "" && !strings.HasPrefix(group.Path, "/") {
+		l.problem("%sPATH must start with /, got %q", keyPrefix, group.Path)
+	}
+	first := len(l.settings)
+	l.corsPolicy(&group.CORSPolicy, keyPrefix, pathPrefix)
+	for i := first; i < len(l.settings); i++ {
+		if l.settings[i].Source == "default" {
+			l.settings[i].Source = "CORS_*"
+		}
+	}
+	cors.Groups = append(cors.Groups, group)
+}
+
+// validOrigin accepts scheme://host[:port] where the host may start with a "*." wildcard label
+func validOrigin(origin string) bool {
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return false
+	}
+	host = strings.TrimPrefix(host, "*.")
+	if host == "" || strings.ContainsAny(host, "*/?#@") {
+		return false
+	}
+	parsed, err := url.Parse(scheme + "://" + host)
+	return err == nil && parsed.Hostname() != ""
+}
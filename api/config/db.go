@@ -1,10 +1,10 @@
 package config
 
 import (
+	"api/pkg"
 	"fmt"
 	"log"
-	"os"
-	"time"
+	"log/slog"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -13,33 +13,22 @@ import (
 
 var DB *gorm.DB
 
-// InitDatabase initializes the database connection
-func InitDatabase() {
+// InitDatabase initializes the database connection from the loaded configuration
+func InitDatabase(cfg *Config) {
 	var err error
-	
-	// Get database configuration from environment variables
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPassword := os.Getenv("DB_PASSWORD")
-	dbName := os.Getenv("DB_NAME")
-	
-	// Validate required environment variables
-	if dbHost == "" || dbPort == "" || dbUser == "" || dbName == "" {
-		log.Fatal("Missing required database environment variables")
-	}
+	db := cfg.Database
 	
 	// Build DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		db.User, db.Password, db.Host, db.Port, db.Name)
 	
-	// Configure GORM logger based on debug mode
-	var gormLogger logger.Interface
-	if os.Getenv("APP_DEBUG") == "true" {
-		gormLogger = logger.Default.LogMode(logger.Info)
-	} else {
-		gormLogger = logger.Default.LogMode(logger.Silent)
+	// Route GORM through the application logger: failed and slow queries always, every query
+	// at debug level in debug mode
+	logLevel := logger.Warn
+	if cfg.App.Debug {
+		logLevel = logger.Info
 	}
+	gormLogger := pkg.NewGormLogger(slog.Default(), logLevel, cfg.Log.SlowQuery)
 	
 	// Open database connection
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
@@ -57,9 +46,9 @@ func InitDatabase() {
 	}
 	
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxIdleConns(db.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(db.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(db.ConnMaxLifetime)
 	
 	log.Println("Database connected successfully")
 }
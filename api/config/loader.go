@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+// loader resolves each key from its sources and collects every problem instead of stopping at the first
+type loader struct {
+	processEnv map[string]bool   // keys set before .env was loaded
+	file       map[string]string // flattened CONFIG_FILE, e.g. "database.max_open_conns"
+	fileName   string
+	used       map[string]bool // file paths that belong to a key
+	problems   []string
+	settings   []Setting
+}
+
+// readFile loads the YAML file; nested keys are flattened with dots and lists joined with commas
+func (l *loader) readFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+	}
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("invalid CONFIG_FILE %s: %w", path, err)
+	}
+
+	l.file = make(map[string]string)
+	l.fileName = path
+	flatten("", document, l.file)
+	return nil
+}
+
+func flatten(prefix string, node map[string]interface{}, into map[string]string) {
+	for key, value := range node {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch value := value.(type) {
+		case map[string]interface{}:
+			flatten(path, value, into)
+		case []interface{}:
+			items := make([]string, 0, len(value))
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+			into[path] = strings.Join(items, ",")
+		case nil:
+			into[path] = ""
+		default:
+			into[path] = fmt.Sprint(value)
+		}
+	}
+}
+
+// lookup returns the raw value of a key; empty environment variables count as unset
+func (l *loader) lookup(key, path string) (value, source string, ok bool) {
+	l.used[path] = true
+	if value := os.Getenv(key); value != "" {
+		if l.processEnv[key] {
+			return value, "env", true
+		}
+		return value, ".env", true
+	}
+	if value, ok := l.file[path]; ok && value != "" {
+		return value, l.fileName, true
+	}
+	return "", "default", false
+}
+
+func (l *loader) problem(format string, args ...interface{}) {
+	l.problems = append(l.problems, fmt.Sprintf(format, args...))
+}
+
+func (l *loader) record(key, value, source string) {
+	l.settings = append(l.settings, Setting{Key: key, Value: value, Source: source})
+}
+
+func (l *loader) string(target *string, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		*target = strings.TrimSpace(value)
+	}
+	l.record(key, *target, source)
+}
+
+func (l *loader) required(target *string, key, path string) {
+	l.string(target, key, path)
+	if *target == "" {
+		l.problem("%s is required", key)
+	}
+}
+
+// secret is a string that is redacted in the effective configuration
+func (l *loader) secret(target *string, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		*target = value
+	}
+	shown := ""
+	if *target != "" {
+		shown = redacted
+	}
+	l.record(key, shown, source)
+}
+
+// int parses a whole number of at least min and, unless max is 0, at most max
+func (l *loader) int(target *int, key, path string, min, max int) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		switch {
+		case err != nil:
+			l.problem("%s must be a whole number, got %q", key, value)
+		case number < min && max == 0:
+			l.problem("%s must be at least %d, got %d", key, min, number)
+		case number < min || (max != 0 && number > max):
+			l.problem("%s must be between %d and %d, got %d", key, min, max, number)
+		default:
+			*target = number
+		}
+	}
+	l.record(key, strconv.Itoa(*target), source)
+}
+
+func (l *loader) bool(target *bool, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			l.problem("%s must be true or false, got %q", key, value)
+		} else {
+			*target = parsed
+		}
+	}
+	l.record(key, strconv.FormatBool(*target), source)
+}
+
+// duration parses a Go duration such as 30m or 1h
+func (l *loader) duration(target *time.Duration, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		parsed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || parsed < 0 {
+			l.problem("%s must be a duration such as 30m or 1h, got %q", key, value)
+		} else {
+			*target = parsed
+		}
+	}
+	l.record(key, target.String(), source)
+}
+
+// durationIn parses a positive whole number of unit, e.g. JWT_ACCESS_TTL in minutes
+func (l *loader) durationIn(target *time.Duration, unit time.Duration, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || number <= 0 {
+			l.problem("%s must be a positive whole number of %s, got %q", key, unitName(unit), value)
+		} else {
+			*target = time.Duration(number) * unit
+		}
+	}
+	l.record(key, strconv.Itoa(int(*target/unit)), source)
+}
+
+func unitName(unit time.Duration) string {
+	switch unit {
+	case time.Second:
+		return "seconds"
+	case time.Minute:
+		return "minutes"
+	case time.Hour:
+		return "hours"
+	default:
+		return unit.String()
+	}
+}
+
+// list parses comma separated values, dropping empty entries
+func (l *loader) list(target *[]string, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*target = items
+	}
+	l.record(key, strings.Join(*target, ","), source)
+}
+
+// absoluteURL is an http or https URL with a host; empty values are left to the caller
+func (l *loader) absoluteURL(target *string, key, path string) {
+	l.string(target, key, path)
+	if *target == "" {
+		return
+	}
+	parsed, err := url.Parse(*target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		l.problem("%s must be an absolute http or https URL, got %q", key, *target)
+	}
+}
+
+// scopes parses values separated by spaces or commas, e.g. OIDC_SCOPES="openid email profile"
+func (l *loader) scopes(target *[]string, key, path string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		*target = strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+	l.record(key, strings.Join(*target, " "), source)
+}
+
+func (l *loader) oneOf(target *string, key, path string, allowed ...string) {
+	value, source, ok := l.lookup(key, path)
+	if ok {
+		value = strings.ToLower(strings.TrimSpace(value))
+		valid := false
+		for _, option := range allowed {
+			valid = valid || value == option
+		}
+		if valid {
+			*target = value
+		} else {
+			l.problem("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+		}
+	}
+	l.record(key, *target, source)
+}
+
+// unknownFileKeys reports file keys no setting reads, which are usually typos
+func (l *loader) unknownFileKeys() {
+	var unknown []string
+	for path := range l.file {
+		if !l.used[path] {
+			unknown = append(unknown, path)
+		}
+	}
+	sort.Strings(unknown)
+	for _, path := range unknown {
+		l.problem("unknown key %q in %s", path, l.fileName)
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import "net"
+
+// proxy reads the trusted reverse proxies. The header is only believed from those addresses,
+// so one of the two keys without the other is a mistake.
+func (l *loader) proxy(proxy *ProxyConfig) {
+	l.string(&proxy.Header, "PROXY_HEADER", "proxy.header")
+	l.list(&proxy.TrustedProxies, "TRUSTED_PROXIES", "proxy.trusted_proxies")
+
+	for _, entry := range proxy.TrustedProxies {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				l.problem("TRUSTED_PROXIES has invalid entry %q, expected an IP address or CIDR range such as 172.16.0.0/12", entry)
+			}
+		}
+	}
+	switch {
+	case proxy.Header != "" && len(proxy.TrustedProxies) == 0:
+		l.problem("TRUSTED_PROXIES is required when PROXY_HEADER is set, otherwise any client could set its own IP")
+	case proxy.Header == "" && len(proxy.TrustedProxies) > 0:
+		l.problem("PROXY_HEADER is required when TRUSTED_PROXIES is set")
+	}
+}
@@ -0,0 +1,26 @@
+ALTER TABLE transactions
+    ADD COLUMN total_price DECIMAL(20,2) DEFAULT NULL AFTER quantity,
+    ADD COLUMN date TIMESTAMP DEFAULT CURRENT_TIMESTAMP AFTER total_price,
+    ADD INDEX idx_transactions_date (date);
+
+UPDATE transactions t
+LEFT JOIN products p ON p.id = t.product_id
+SET t.date = t.created_at,
+    t.total_price = t.quantity * p.price,
+    t.quantity = IF(t.type = 'out', -t.quantity, t.quantity);
+
+ALTER TABLE transactions
+    DROP INDEX idx_transactions_reversal_of,
+    DROP COLUMN reversal_of,
+    DROP COLUMN type;
+
+ALTER TABLE products
+    DROP INDEX idx_products_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE warehouses
+    DROP INDEX idx_warehouses_deleted_at,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE users
+    DROP INDEX idx_users_email;
@@ -0,0 +1,30 @@
+-- Bring the baseline tables to the shape of the GORM models:
+-- soft delete on warehouses/products, movement type and reversal link on transactions,
+-- and no more total_price/date columns (prices live on products, created_at is the posting time).
+
+ALTER TABLE users
+    ADD UNIQUE INDEX idx_users_email (email);
+
+ALTER TABLE warehouses
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_warehouses_deleted_at (deleted_at);
+
+ALTER TABLE products
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL,
+    ADD INDEX idx_products_deleted_at (deleted_at);
+
+ALTER TABLE transactions
+    ADD COLUMN type ENUM('in','out') DEFAULT NULL AFTER warehouse_id,
+    ADD COLUMN reversal_of BIGINT UNSIGNED DEFAULT NULL AFTER quantity,
+    ADD INDEX idx_transactions_reversal_of (reversal_of);
+
+-- Legacy rows have no direction: a negative quantity was an issue, anything else a receipt
+UPDATE transactions
+SET type = IF(quantity < 0, 'out', 'in'),
+    quantity = ABS(quantity)
+WHERE type IS NULL;
+
+ALTER TABLE transactions
+    DROP INDEX idx_transactions_date,
+    DROP COLUMN total_price,
+    DROP COLUMN date;
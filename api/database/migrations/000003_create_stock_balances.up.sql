@@ -0,0 +1,57 @@
+-- Per-warehouse stock; products.stock is kept as the total over all warehouses
+CREATE TABLE stock_balances (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    product_id BIGINT UNSIGNED NOT NULL,
+    warehouse_id BIGINT UNSIGNED NOT NULL,
+    quantity DECIMAL(20,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_stock_balances_product_warehouse (product_id, warehouse_id),
+    INDEX idx_stock_balances_warehouse_id (warehouse_id),
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- products.stock was edited directly before the ledger existed (pseudo.sql, manual fixes), so a
+-- rebuild from transactions alone would drop that stock. Post the difference between each product's
+-- stock and its net ledger as an opening transaction in the lowest active warehouse first, creating
+-- "Main Warehouse" when stock has to be posted and no active warehouse exists.
+INSERT INTO warehouses (name)
+SELECT 'Main Warehouse' FROM DUAL
+WHERE NOT EXISTS (SELECT 1 FROM warehouses WHERE deleted_at IS NULL)
+  AND EXISTS (
+    SELECT 1 FROM products
+    WHERE COALESCE(products.stock, 0) <> COALESCE((
+        SELECT SUM(IF(transactions.type = 'in', transactions.quantity, -transactions.quantity))
+        FROM transactions
+        WHERE transactions.product_id = products.id AND transactions.deleted_at IS NULL AND transactions.warehouse_id IS NOT NULL
+    ), 0)
+  );
+
+INSERT INTO transactions (product_id, warehouse_id, type, quantity)
+SELECT opening.product_id,
+       (SELECT MIN(id) FROM warehouses WHERE deleted_at IS NULL),
+       IF(opening.difference > 0, 'in', 'out'),
+       ABS(opening.difference)
+FROM (
+    SELECT products.id AS product_id,
+           COALESCE(products.stock, 0) - COALESCE((
+               SELECT SUM(IF(transactions.type = 'in', transactions.quantity, -transactions.quantity))
+               FROM transactions
+               WHERE transactions.product_id = products.id AND transactions.deleted_at IS NULL AND transactions.warehouse_id IS NOT NULL
+           ), 0) AS difference
+    FROM products
+) AS opening
+WHERE opening.difference <> 0;
+
+-- Same rebuild as cmd/reconcile: balances come from the non-deleted ledger
+INSERT INTO stock_balances (product_id, warehouse_id, quantity)
+SELECT product_id, warehouse_id, SUM(IF(type = 'in', quantity, -quantity))
+FROM transactions
+WHERE deleted_at IS NULL AND product_id IS NOT NULL AND warehouse_id IS NOT NULL
+GROUP BY product_id, warehouse_id;
+
+UPDATE products
+SET stock = COALESCE((SELECT SUM(stock_balances.quantity) FROM stock_balances WHERE stock_balances.product_id = products.id), 0);
@@ -0,0 +1,6 @@
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_transfer,
+    DROP INDEX idx_transactions_transfer_id,
+    DROP COLUMN transfer_id;
+
+DROP TABLE IF EXISTS transfers;
@@ -0,0 +1,30 @@
+-- Inter-warehouse transfers; both movement legs point back via transactions.transfer_id
+CREATE TABLE transfers (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED DEFAULT NULL,
+    product_id BIGINT UNSIGNED NOT NULL,
+    from_warehouse_id BIGINT UNSIGNED NOT NULL,
+    to_warehouse_id BIGINT UNSIGNED NOT NULL,
+    quantity DECIMAL(20,2) NOT NULL,
+    received_quantity DECIMAL(20,2) DEFAULT NULL,
+    status VARCHAR(20) NOT NULL,
+    received_by BIGINT UNSIGNED DEFAULT NULL,
+    received_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_transfers_user_id (user_id),
+    INDEX idx_transfers_product_id (product_id),
+    INDEX idx_transfers_from_warehouse_id (from_warehouse_id),
+    INDEX idx_transfers_to_warehouse_id (to_warehouse_id),
+    INDEX idx_transfers_status (status),
+
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    FOREIGN KEY (from_warehouse_id) REFERENCES warehouses(id) ON DELETE RESTRICT ON UPDATE CASCADE,
+    FOREIGN KEY (to_warehouse_id) REFERENCES warehouses(id) ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+ALTER TABLE transactions
+    ADD COLUMN transfer_id BIGINT UNSIGNED DEFAULT NULL AFTER reversal_of,
+    ADD INDEX idx_transactions_transfer_id (transfer_id),
+    ADD CONSTRAINT fk_transactions_transfer FOREIGN KEY (transfer_id) REFERENCES transfers(id) ON DELETE SET NULL ON UPDATE CASCADE;
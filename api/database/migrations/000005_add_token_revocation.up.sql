@@ -0,0 +1,13 @@
+-- Per-user token version (logout all sessions) and per-token revocation by jti (logout this session)
+ALTER TABLE users
+    ADD COLUMN token_version INT UNSIGNED NOT NULL DEFAULT 0 AFTER password;
+
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) NOT NULL PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_revoked_tokens_user_id (user_id),
+    INDEX idx_revoked_tokens_expires_at (expires_at)
+);
@@ -0,0 +1,16 @@
+-- Server-side refresh tokens grouped into rotation families.
+-- Refresh tokens issued before this migration have no record and stop working.
+CREATE TABLE refresh_tokens (
+    jti VARCHAR(64) NOT NULL PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL,
+    parent_jti VARCHAR(64) NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_refresh_tokens_family_id (family_id),
+    INDEX idx_refresh_tokens_user_id (user_id),
+    INDEX idx_refresh_tokens_expires_at (expires_at)
+);
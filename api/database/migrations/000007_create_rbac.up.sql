@@ -0,0 +1,72 @@
+-- Roles, permissions and their assignments.
+-- Existing users keep the access they had before (warehouse_manager); the oldest user
+-- additionally becomes admin so someone can assign roles.
+CREATE TABLE roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    description VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_roles_name (name)
+);
+
+CREATE TABLE permissions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(255) DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_permissions_name (name)
+);
+
+CREATE TABLE role_permissions (
+    role_id BIGINT UNSIGNED NOT NULL,
+    permission_id BIGINT UNSIGNED NOT NULL,
+
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE user_roles (
+    user_id BIGINT UNSIGNED NOT NULL,
+    role_id BIGINT UNSIGNED NOT NULL,
+
+    PRIMARY KEY (user_id, role_id),
+    INDEX idx_user_roles_role_id (role_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access including user and role management'),
+    ('warehouse_manager', 'Manages master data, stock movements and transfers'),
+    ('clerk', 'Records stock movements and transfers'),
+    ('viewer', 'Read-only access');
+
+INSERT INTO permissions (name, description) VALUES
+    ('warehouses:read', 'List and view warehouses'),
+    ('warehouses:write', 'Create, update, delete and restore warehouses'),
+    ('products:read', 'List and view products'),
+    ('products:write', 'Create, update, delete and restore products'),
+    ('transactions:read', 'List and view stock transactions'),
+    ('transactions:write', 'Record, reverse and delete stock transactions'),
+    ('stock:read', 'View per-warehouse stock balances'),
+    ('transfers:read', 'List and view transfers'),
+    ('transfers:write', 'Create and receive transfers'),
+    ('users:manage', 'Assign roles to users');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+WHERE r.name = 'admin'
+   OR (r.name = 'warehouse_manager' AND p.name <> 'users:manage')
+   OR (r.name = 'clerk' AND p.name IN ('warehouses:read', 'products:read', 'transactions:read', 'transactions:write', 'stock:read', 'transfers:read', 'transfers:write'))
+   OR (r.name = 'viewer' AND p.name LIKE '%:read');
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u JOIN roles r ON r.name = 'warehouse_manager';
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id FROM users u JOIN roles r ON r.name = 'admin'
+WHERE u.id = (SELECT MIN(id) FROM users WHERE deleted_at IS NULL);
@@ -0,0 +1,18 @@
+-- Warehouses each user may work in; admins are not limited by this table.
+-- Existing users keep seeing everything they saw before by being assigned every warehouse.
+CREATE TABLE user_warehouses (
+    user_id BIGINT UNSIGNED NOT NULL,
+    warehouse_id BIGINT UNSIGNED NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, warehouse_id),
+    INDEX idx_user_warehouses_warehouse_id (warehouse_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY (warehouse_id) REFERENCES warehouses(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+INSERT INTO user_warehouses (user_id, warehouse_id)
+SELECT users.id, warehouses.id
+FROM users
+CROSS JOIN warehouses
+WHERE users.deleted_at IS NULL AND warehouses.deleted_at IS NULL;
@@ -0,0 +1,14 @@
+-- Single-use password reset tokens; only the SHA-256 hash of each token is stored.
+CREATE TABLE password_reset_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_password_reset_tokens_token_hash (token_hash),
+    INDEX idx_password_reset_tokens_user_id (user_id),
+    INDEX idx_password_reset_tokens_expires_at (expires_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,18 @@
+-- Accounts created before email verification existed are treated as verified.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP NULL AFTER password;
+UPDATE users SET email_verified_at = created_at;
+
+-- Single-use email verification tokens; only the SHA-256 hash of each token is stored.
+CREATE TABLE email_verification_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_email_verification_tokens_token_hash (token_hash),
+    INDEX idx_email_verification_tokens_user_id (user_id),
+    INDEX idx_email_verification_tokens_expires_at (expires_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
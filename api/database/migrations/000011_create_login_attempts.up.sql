@@ -0,0 +1,10 @@
+-- Failed sign-in tracking per email and per client IP, shared by every API replica.
+CREATE TABLE login_attempts (
+    attempt_key VARCHAR(191) NOT NULL PRIMARY KEY,
+    failures INT UNSIGNED NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_login_attempts_last_failure_at (last_failure_at)
+);
@@ -0,0 +1,22 @@
+-- TOTP enrollment per user; two-factor sign-in is enabled once confirmed_at is set.
+CREATE TABLE user_totp (
+    user_id BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Single-use recovery codes; only the SHA-256 hash of each code is stored.
+CREATE TABLE recovery_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_recovery_codes_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,18 @@
+-- One row per sign-in (refresh token family) so users can list and revoke their sessions.
+-- Sign-ins from before this migration have no session row and are not listed.
+CREATE TABLE sessions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    family_id VARCHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_sessions_family_id (family_id),
+    INDEX idx_sessions_user_id (user_id),
+    INDEX idx_sessions_expires_at (expires_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
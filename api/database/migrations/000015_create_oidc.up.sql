@@ -0,0 +1,25 @@
+-- Pending OIDC sign-ins (state, PKCE verifier and nonce) and links between users and
+-- identity provider accounts.
+CREATE TABLE oidc_states (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    state_hash VARCHAR(64) NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_oidc_states_state_hash (state_hash),
+    INDEX idx_oidc_states_expires_at (expires_at)
+);
+
+CREATE TABLE user_identities (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_user_identities_issuer_subject (issuer, subject),
+    INDEX idx_user_identities_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
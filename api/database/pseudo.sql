@@ -1,5 +1,8 @@
 -- Database: pseudo
 -- Created for Pseudo App Project
+--
+-- Baseline schema used to initialise the MySQL container. Schema changes are
+-- versioned in database/migrations; apply them after this file (see README.md).
 
 SET FOREIGN_KEY_CHECKS=0;
 
@@ -63,18 +66,7 @@ CREATE INDEX idx_transactions_warehouse_id ON transactions(warehouse_id);
 CREATE INDEX idx_transactions_date ON transactions(date);
 CREATE INDEX idx_transactions_deleted_at ON transactions(deleted_at);
 
--- Insert sample data (optional)
-INSERT INTO users (name, email, password) VALUES 
-('Admin User', 'admin@pseudo.com', '$2a$10$example_hashed_password'),
-('Test User', 'test@pseudo.com', '$2a$10$example_hashed_password');
-
-INSERT INTO warehouses (name) VALUES 
-('Main Warehouse'),
-('Secondary Warehouse');
-
-INSERT INTO products (name, price, stock) VALUES 
-('Product A', 100.00, 50.00),
-('Product B', 200.00, 30.00),
-('Product C', 150.00, 75.00);
+-- Sample data is no longer inserted here: run `go run ./cmd/seed -set demo`
+-- to create users with real password hashes, warehouses, products and stock.
 
 SET FOREIGN_KEY_CHECKS=1;
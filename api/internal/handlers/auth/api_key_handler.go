@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler struct {
+	apiKeyService auth.APIKeyService
+	validator     *validator.Validate
+}
+
+func NewAPIKeyHandler(apiKeyService auth.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		validator:     validator.New(),
+	}
+}
+
+// Create handles issuing a personal API key
+// @Summary Create API key
+// @Description Issue a personal API key for machine clients, sent in the X-API-Key header. Scopes must be permissions the user's roles grant. The key in the response is shown only once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAPIKeyRequest true "Create API key request"
+// @Success 201 {object} models.CreatedAPIKeyResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/api-keys [post]
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	var req models.CreateAPIKeyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.apiKeyService.Create(c.UserContext(), userID, &req)
+	if err != nil {
+		if errors.Is(err, auth.ErrScopeNotGranted) || errors.Is(err, auth.ErrUserNotFound) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// List handles listing the user's API keys
+// @Summary List API keys
+// @Description List the current user's API keys without their secrets
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.APIKeyResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/api-keys [get]
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	keys, err := h.apiKeyService.List(c.UserContext(), userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    keys,
+	})
+}
+
+// Revoke handles deleting one of the user's API keys
+// @Summary Revoke API key
+// @Description Revoke one of the current user's API keys; it stops working immediately
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	if err := h.apiKeyService.Revoke(c.UserContext(), userID, id); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "API key revoked",
+	})
+}
@@ -2,24 +2,26 @@ package auth
 
 import (
 	"api/internal/middlewares"
+	"errors"
 	"api/internal/models"
 	"api/internal/services/auth"
 	"net/http"
-	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type AuthHandler struct {
-	authService auth.AuthService
-	validator   *validator.Validate
+	authService         auth.AuthService
+	loginAttemptService auth.LoginAttemptService
+	validator           *validator.Validate
 }
 
-func NewAuthHandler(authService auth.AuthService) *AuthHandler {
+func NewAuthHandler(authService auth.AuthService, loginAttemptService auth.LoginAttemptService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		validator:   validator.New(),
+		authService:         authService,
+		loginAttemptService: loginAttemptService,
+		validator:           validator.New(),
 	}
 }
 
@@ -52,12 +54,12 @@ func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.authService.Register(&req)
+	response, err := h.authService.Register(c.UserContext(), &req, clientInfo(c))
 	if err != nil {
 		// Record failed registration attempt
 		middlewares.RecordAuthAttempt("signup", "failure")
 		
-		if err.Error() == "email already registered" {
+		if errors.Is(err, auth.ErrEmailAlreadyRegistered) {
 			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
 				"message": "failed",
 				"error":   err.Error(),
@@ -71,7 +73,10 @@ func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 
 	// Record successful registration attempt
 	middlewares.RecordAuthAttempt("signup", "success")
-	middlewares.RecordJWTTokenIssued()
+	// No tokens are issued when the email must be verified before signing in
+	if response.AccessToken != "" {
+		middlewares.RecordJWTTokenIssued()
+	}
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "success",
@@ -81,13 +86,15 @@ func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 
 // SignIn handles user login
 // @Summary Login user
-// @Description Login user with email and password
+// @Description Login user with email and password. Users with two-factor sign-in get an mfa_token to exchange at /auth/2fa/verify instead of tokens. Repeated failures for an email or client IP lock sign-in for a growing period.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body models.AuthRequest true "Login request"
 // @Success 200 {object} models.AuthResponse
+// @Failure 403 {object} map[string]interface{}
 // @Failure 422 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/auth/signin [post]
 func (h *AuthHandler) SignIn(c *fiber.Ctx) error {
@@ -108,17 +115,47 @@ func (h *AuthHandler) SignIn(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.authService.Login(&req)
+	retryAfter, err := h.loginAttemptService.Check(c.UserContext(), req.Email, c.IP())
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+	if retryAfter > 0 {
+		middlewares.RecordAuthAttempt("signin", "locked")
+		return tooManyAttemptsResponse(c, retryAfter)
+	}
+
+	response, err := h.authService.Login(c.UserContext(), &req, clientInfo(c))
 	if err != nil {
 		// Record failed signin attempt
 		middlewares.RecordAuthAttempt("signin", "failure")
 		
-		if err.Error() == "invalid email or password" {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			lock, recordErr := h.loginAttemptService.RecordFailure(c.UserContext(), req.Email, c.IP())
+			if recordErr != nil {
+				return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+					"message": "failed",
+					"error":   "Internal server error",
+				})
+			}
+			// The failure that causes the lock already reports it
+			if lock > 0 {
+				return tooManyAttemptsResponse(c, lock)
+			}
 			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
 				"message": "failed",
 				"error":   err.Error(),
 			})
 		}
+		if errors.Is(err, auth.ErrEmailNotVerified) {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Forbidden",
+				"details": err.Error(),
+			})
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed",
 			"error":   "Internal server error",
@@ -127,7 +164,16 @@ func (h *AuthHandler) SignIn(c *fiber.Ctx) error {
 
 	// Record successful signin attempt
 	middlewares.RecordAuthAttempt("signin", "success")
-	middlewares.RecordJWTTokenIssued()
+	if err := h.loginAttemptService.RecordSuccess(c.UserContext(), req.Email); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+	// Two-factor sign-in returns an mfa_pending token instead
+	if response.AccessToken != "" {
+		middlewares.RecordJWTTokenIssued()
+	}
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "success",
@@ -142,22 +188,19 @@ func (h *AuthHandler) SignIn(c *fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
 // @Failure 422 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/auth/me [get]
 func (h *AuthHandler) Me(c *fiber.Ctx) error {
-	userIDStr := c.Locals("userID").(string)
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
-			"message": "failed",
-			"error":   "Invalid user ID",
-		})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
 	}
 
-	user, err := h.authService.GetUserByID(uint(userID))
+	user, err := h.authService.GetUserByID(c.UserContext(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, auth.ErrUserNotFound) {
 			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
 				"message": "failed",
 				"error":   err.Error(),
@@ -171,13 +214,13 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 
 	return c.Status(http.StatusOK).JSON(fiber.Map{
 		"message": "success",
-		"user":    user,
+		"data":    user,
 	})
 }
 
 // RefreshToken handles token refresh
 // @Summary Refresh access token
-// @Description Refresh access token using refresh token
+// @Description Exchange a refresh token for a new token pair; the presented refresh token can no longer be used
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -204,45 +247,60 @@ func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	response, err := h.authService.RefreshToken(&req)
+	response, err := h.authService.RefreshToken(c.UserContext(), &req, clientInfo(c))
 	if err != nil {
-		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Invalid refresh token",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed",
-			"error":   "Invalid refresh token",
+			"error":   "Internal server error",
 		})
 	}
 
-	return c.Status(http.StatusOK).JSON(response)
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
 }
 
 // Logout handles user logout
 // @Summary Logout user
-// @Description Logout current authenticated user
+// @Description Revoke the access token of the current session; pass refresh_token to revoke it as well
 // @Tags Authentication
+// @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body models.LogoutRequest false "Refresh token of this session"
 // @Success 200 {object} map[string]interface{}
-// @Failure 422 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	userIDStr := c.Locals("userID").(string)
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
-			"message": "failed",
-			"error":   "Invalid user ID",
-		})
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
 	}
+	accessToken, _ := c.Locals("accessToken").(string)
 
-	err = h.authService.Logout(uint(userID))
-	if err != nil {
-		if err.Error() == "user not found" {
+	var req models.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
 			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
 				"message": "failed",
-				"error":   err.Error(),
+				"error":   "Invalid request body",
 			})
 		}
+	}
+
+	err := h.authService.Logout(c.UserContext(), userID, accessToken, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return unauthorizedResponse(c)
+		}
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"message": "failed",
 			"error":   "Internal server error",
@@ -253,4 +311,33 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		"message": "success",
 		"data":    "Successfully logged out",
 	})
-}
\ No newline at end of file
+}
+
+// LogoutAll handles logging out every session of the user
+// @Summary Logout all sessions
+// @Description Revoke every access and refresh token issued to the current user
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	if err := h.authService.LogoutAll(c.UserContext(), userID); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Successfully logged out from all sessions",
+	})
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type EmailVerificationHandler struct {
+	emailVerificationService auth.EmailVerificationService
+	validator                *validator.Validate
+}
+
+func NewEmailVerificationHandler(emailVerificationService auth.EmailVerificationService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		emailVerificationService: emailVerificationService,
+		validator:                validator.New(),
+	}
+}
+
+// VerifyEmail handles confirming an email address
+// @Summary Verify email
+// @Description Confirm an email address with the token from the verification email. Existing sessions of the user are revoked so the next sign-in carries the verified state.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyEmailRequest true "Verify email request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/verify-email [post]
+func (h *EmailVerificationHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	if err := h.emailVerificationService.VerifyEmail(c.UserContext(), &req); err != nil {
+		if errors.Is(err, auth.ErrInvalidVerificationToken) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Email has been verified, please sign in again",
+	})
+}
+
+// ResendVerification handles sending a new verification link
+// @Summary Resend verification email
+// @Description Send a new verification link. The response is the same whether or not the email is registered or already verified.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ResendVerificationRequest true "Resend verification request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/resend-verification [post]
+func (h *EmailVerificationHandler) ResendVerification(c *fiber.Ctx) error {
+	var req models.ResendVerificationRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	if err := h.emailVerificationService.ResendVerification(c.UserContext(), &req); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "If the email is registered and not yet verified, a verification link has been sent",
+	})
+}
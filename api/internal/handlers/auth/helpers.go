@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"api/internal/models"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseID parses the ":id" route parameter
+func parseID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid id")
+	}
+	return uint(id), nil
+}
+
+// invalidIDResponse returns the standard response for a malformed ":id" parameter
+func invalidIDResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Invalid ID",
+	})
+}
+
+// currentUserID reads the user ID stored by the JWT middleware
+func currentUserID(c *fiber.Ctx) (uint, bool) {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return 0, false
+	}
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(userID), true
+}
+
+// clientInfo describes the client of the request for the session it signs in to
+func clientInfo(c *fiber.Ctx) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+func unauthorizedResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Unauthorized",
+	})
+}
+
+// tooManyAttemptsResponse returns 429 with Retry-After in whole seconds
+func tooManyAttemptsResponse(c *fiber.Ctx, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+	return c.Status(http.StatusTooManyRequests).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Too many failed sign-in attempts",
+		"details": fmt.Sprintf("try again in %d seconds", seconds),
+	})
+}
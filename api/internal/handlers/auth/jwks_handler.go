@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"api/internal/services/auth"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type JWKSHandler struct {
+	signingKeys *auth.SigningKeys
+}
+
+func NewJWKSHandler(signingKeys *auth.SigningKeys) *JWKSHandler {
+	return &JWKSHandler{
+		signingKeys: signingKeys,
+	}
+}
+
+// JWKS handles publishing the token verification keys
+// @Summary JSON Web Key Set
+// @Description Public keys that verify the tokens issued by this API, matched by the kid header. Retired keys stay listed until their tokens expire. The set is empty when tokens are signed with HS256.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} models.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) JWKS(c *fiber.Ctx) error {
+	// Verifiers may cache the set; a new signing key must be listed before it is used
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(http.StatusOK).JSON(h.signingKeys.JWKS())
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type LoginAttemptHandler struct {
+	loginAttemptService auth.LoginAttemptService
+}
+
+func NewLoginAttemptHandler(loginAttemptService auth.LoginAttemptService) *LoginAttemptHandler {
+	return &LoginAttemptHandler{
+		loginAttemptService: loginAttemptService,
+	}
+}
+
+// Unlock handles lifting the sign-in lockout of a user
+// @Summary Unlock user sign-in
+// @Description Clear the failed sign-in count and lockout of a user's email. Lockouts of client IPs expire on their own.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/unlock [post]
+func (h *LoginAttemptHandler) Unlock(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	if err := h.loginAttemptService.Unlock(c.UserContext(), id); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Sign-in unlocked",
+	})
+}
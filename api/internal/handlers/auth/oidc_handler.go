@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"api/internal/middlewares"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OIDCHandler struct {
+	oidcService auth.OIDCService
+	validator   *validator.Validate
+}
+
+func NewOIDCHandler(oidcService auth.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		validator:   validator.New(),
+	}
+}
+
+// Authorize handles starting an OIDC sign-in
+// @Summary Start OIDC sign-in
+// @Description Start a sign-in with the configured OpenID Connect provider (authorization code flow with PKCE). Redirect the browser to authorization_url; the provider redirects back to OIDC_REDIRECT_URL with code and state for /auth/oidc/callback.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} models.OIDCAuthorizationResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/oidc/authorize [get]
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	response, err := h.oidcService.AuthorizationURL(c.UserContext())
+	if err != nil {
+		if errors.Is(err, auth.ErrOIDCNotConfigured) {
+			return oidcNotConfiguredResponse(c)
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Callback handles finishing an OIDC sign-in
+// @Summary Finish OIDC sign-in
+// @Description Exchange the code and state from the provider redirect for tokens. The provider account is matched by link, then by verified email, and a user with the default role is created on first sign-in. Users with two-factor sign-in get an mfa_token to exchange at /auth/2fa/verify instead of tokens.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.OIDCCallbackRequest true "OIDC callback request"
+// @Success 200 {object} models.AuthResponse
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/oidc/callback [post]
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	var req models.OIDCCallbackRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.oidcService.Callback(c.UserContext(), &req, clientInfo(c))
+	if err != nil {
+		middlewares.RecordAuthAttempt("oidc", "failure")
+
+		switch {
+		case errors.Is(err, auth.ErrOIDCNotConfigured):
+			return oidcNotConfiguredResponse(c)
+		case errors.Is(err, auth.ErrInvalidOIDCState), errors.Is(err, auth.ErrOIDCAuthenticationFailed), errors.Is(err, auth.ErrOIDCEmailNotVerified):
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	middlewares.RecordAuthAttempt("oidc", "success")
+	// Two-factor sign-in returns an mfa_pending token instead
+	if response.AccessToken != "" {
+		middlewares.RecordJWTTokenIssued()
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+func oidcNotConfiguredResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusNotFound).JSON(fiber.Map{
+		"message": "failed",
+		"error":   auth.ErrOIDCNotConfigured.Error(),
+	})
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"api/internal/middlewares"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService auth.PasswordResetService
+	validator            *validator.Validate
+}
+
+func NewPasswordResetHandler(passwordResetService auth.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{
+		passwordResetService: passwordResetService,
+		validator:            validator.New(),
+	}
+}
+
+// ForgotPassword handles requesting a password reset link
+// @Summary Request password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Forgot password request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/forgot-password [post]
+func (h *PasswordResetHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	if err := h.passwordResetService.ForgotPassword(c.UserContext(), &req); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+// @Summary Reset password
+// @Description Set a new password with the token from the reset email. Every existing session of the user is revoked.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset password request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/reset-password [post]
+func (h *PasswordResetHandler) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.UserContext(), &req); err != nil {
+		middlewares.RecordAuthAttempt("reset_password", "failure")
+
+		if errors.Is(err, auth.ErrInvalidResetToken) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	middlewares.RecordAuthAttempt("reset_password", "success")
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Password has been reset, please sign in again",
+	})
+}
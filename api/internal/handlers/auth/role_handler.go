@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleHandler struct {
+	roleService auth.RoleService
+	validator   *validator.Validate
+}
+
+func NewRoleHandler(roleService auth.RoleService) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		validator:   validator.New(),
+	}
+}
+
+// ListRoles handles listing roles
+// @Summary List roles
+// @Description List every role with its permissions
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.RoleResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) ListRoles(c *fiber.Ctx) error {
+	response, err := h.roleService.ListRoles(c.UserContext())
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// GetUserRoles handles getting the roles of a user
+// @Summary Get user roles
+// @Description Get the roles assigned to a user
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserRolesResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/roles [get]
+func (h *RoleHandler) GetUserRoles(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.roleService.GetUserRoles(c.UserContext(), id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// AssignRoles handles replacing the roles of a user
+// @Summary Assign user roles
+// @Description Replace the roles of a user. The user's tokens are revoked so the next sign-in carries the new roles.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AssignRolesRequest true "Roles to assign"
+// @Success 200 {object} models.UserRolesResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/roles [put]
+func (h *RoleHandler) AssignRoles(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	var req models.AssignRolesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.roleService.AssignRoles(c.UserContext(), id, &req)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// errorResponse maps role service errors to HTTP responses
+func (h *RoleHandler) errorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, auth.ErrUserNotFound) || errors.Is(err, auth.ErrRoleNotFound) || errors.Is(err, auth.ErrLastAdmin) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Internal server error",
+	})
+}
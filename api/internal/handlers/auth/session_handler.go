@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	sessionService auth.SessionService
+}
+
+func NewSessionHandler(sessionService auth.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// List handles listing the user's active sessions
+// @Summary List sessions
+// @Description List where the current user is signed in, most recently used first. The session of the request has current set.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/sessions [get]
+func (h *SessionHandler) List(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+	sessionID, _ := c.Locals("sessionID").(string)
+
+	sessions, err := h.sessionService.List(c.UserContext(), userID, sessionID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    sessions,
+	})
+}
+
+// Revoke handles signing out one of the user's sessions
+// @Summary Revoke session
+// @Description Sign one of the current user's sessions out; its refresh and access tokens stop working immediately
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *SessionHandler) Revoke(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	if err := h.sessionService.Revoke(c.UserContext(), userID, id); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Session revoked",
+	})
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"api/internal/middlewares"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService auth.TwoFactorService
+	validator        *validator.Validate
+}
+
+func NewTwoFactorHandler(twoFactorService auth.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		validator:        validator.New(),
+	}
+}
+
+// Enroll handles starting a TOTP enrollment
+// @Summary Enroll two-factor authentication
+// @Description Create a new TOTP secret and return its otpauth:// URI for an authenticator app. Two-factor sign-in stays off until the enrollment is confirmed with a first code.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorEnrollResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	response, err := h.twoFactorService.Enroll(c.UserContext(), userID)
+	if err != nil {
+		if errors.Is(err, auth.ErrTwoFactorAlreadyEnabled) || errors.Is(err, auth.ErrUserNotFound) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Confirm handles enabling two-factor sign-in
+// @Summary Confirm two-factor authentication
+// @Description Enable two-factor sign-in with a first code from the authenticator app. The recovery codes in the response are shown only once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	var req models.TwoFactorCodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.twoFactorService.Confirm(c.UserContext(), userID, &req)
+	if err != nil {
+		if errors.Is(err, auth.ErrTwoFactorNotEnrolled) || errors.Is(err, auth.ErrTwoFactorAlreadyEnabled) || errors.Is(err, auth.ErrInvalidTwoFactorCode) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Disable handles turning two-factor sign-in off
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor sign-in off and delete the recovery codes. Needs a current TOTP code or an unused recovery code.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return unauthorizedResponse(c)
+	}
+
+	var req models.TwoFactorCodeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	if err := h.twoFactorService.Disable(c.UserContext(), userID, &req); err != nil {
+		if errors.Is(err, auth.ErrTwoFactorNotEnabled) || errors.Is(err, auth.ErrInvalidTwoFactorCode) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Two-factor authentication disabled",
+	})
+}
+
+// Verify handles the code step of a two-factor sign-in
+// @Summary Verify two-factor sign-in
+// @Description Exchange the mfa_token from /auth/signin and a TOTP or recovery code for an access/refresh token pair. Wrong codes count towards the sign-in lockout.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorVerifyRequest true "Two-factor verify request"
+// @Success 200 {object} models.AuthResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(c *fiber.Ctx) error {
+	var req models.TwoFactorVerifyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.twoFactorService.Verify(c.UserContext(), &req, clientInfo(c))
+	if err != nil {
+		var lockedErr *auth.LockedError
+		if errors.As(err, &lockedErr) {
+			middlewares.RecordAuthAttempt("2fa_verify", "locked")
+			return tooManyAttemptsResponse(c, lockedErr.RetryAfter)
+		}
+
+		middlewares.RecordAuthAttempt("2fa_verify", "failure")
+		if errors.Is(err, auth.ErrInvalidMFAToken) {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		if errors.Is(err, auth.ErrInvalidTwoFactorCode) {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"message": "failed",
+				"error":   err.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	middlewares.RecordAuthAttempt("2fa_verify", "success")
+	middlewares.RecordJWTTokenIssued()
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
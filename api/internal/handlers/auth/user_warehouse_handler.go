@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserWarehouseHandler struct {
+	userWarehouseService auth.UserWarehouseService
+	validator            *validator.Validate
+}
+
+func NewUserWarehouseHandler(userWarehouseService auth.UserWarehouseService) *UserWarehouseHandler {
+	return &UserWarehouseHandler{
+		userWarehouseService: userWarehouseService,
+		validator:            validator.New(),
+	}
+}
+
+// GetUserWarehouses handles getting the warehouses of a user
+// @Summary Get user warehouses
+// @Description Get the warehouses a user is assigned to. Admins see every warehouse regardless of assignment.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.UserWarehousesResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/warehouses [get]
+func (h *UserWarehouseHandler) GetUserWarehouses(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.userWarehouseService.GetUserWarehouses(c.UserContext(), id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// AssignWarehouses handles replacing the warehouses of a user
+// @Summary Assign user warehouses
+// @Description Replace the warehouses of a user. An empty list removes every assignment. The user's tokens are revoked so the next sign-in carries the new assignment.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.AssignWarehousesRequest true "Warehouses to assign"
+// @Success 200 {object} models.UserWarehousesResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/admin/users/{id}/warehouses [put]
+func (h *UserWarehouseHandler) AssignWarehouses(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	var req models.AssignWarehousesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.userWarehouseService.AssignWarehouses(c.UserContext(), id, &req)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// errorResponse maps user warehouse service errors to HTTP responses
+func (h *UserWarehouseHandler) errorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, auth.ErrUserNotFound) || errors.Is(err, auth.ErrWarehouseNotFound) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Internal server error",
+	})
+}
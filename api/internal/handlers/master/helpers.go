@@ -0,0 +1,63 @@
+package master
+
+import (
+	"api/internal/models"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseID parses the ":id" route parameter
+func parseID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid id")
+	}
+	return uint(id), nil
+}
+
+// warehouseScope returns the warehouses the caller may access, as stored by JWTAuth.
+// Without one the scope allows nothing.
+func warehouseScope(c *fiber.Ctx) models.WarehouseScope {
+	scope, _ := c.Locals("warehouseScope").(models.WarehouseScope)
+	return scope
+}
+
+// invalidIDResponse returns the standard response for a malformed ":id" parameter
+func invalidIDResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Invalid ID",
+	})
+}
+
+// formImage returns the uploaded file for field, or nil when the request has no such file
+func formImage(c *fiber.Ctx, field string) (*multipart.FileHeader, error) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return nil, nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, err
+	}
+
+	files := form.File[field]
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return files[0], nil
+}
+
+// forbiddenResponse returns the standard response for access outside the caller's warehouses
+func forbiddenResponse(c *fiber.Ctx, err error) error {
+	return c.Status(http.StatusForbidden).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Forbidden",
+		"details": err.Error(),
+	})
+}
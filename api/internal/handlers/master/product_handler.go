@@ -0,0 +1,290 @@
+package master
+
+import (
+	"api/internal/models"
+	"api/internal/services/master"
+	"api/pkg"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductHandler struct {
+	productService master.ProductService
+	validator      *validator.Validate
+}
+
+func NewProductHandler(productService master.ProductService) *ProductHandler {
+	return &ProductHandler{
+		productService: productService,
+		validator:      validator.New(),
+	}
+}
+
+// List handles listing products
+// @Summary List products
+// @Description List products with search, pagination and soft-delete filter. Non-admin users see products stocked in their warehouses and products not stocked anywhere yet.
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search by name"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size (max 100)"
+// @Param trashed query string false "with|only"
+// @Success 200 {object} models.ProductListResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/products [get]
+func (h *ProductHandler) List(c *fiber.Ctx) error {
+	var query models.ListQuery
+
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid query parameters",
+		})
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.productService.List(c.UserContext(), warehouseScope(c), &query)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(response.Meta.Total, 10))
+	c.Set("X-Page-Count", strconv.FormatInt(response.Meta.TotalPage, 10))
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Get handles getting a single product
+// @Summary Get product
+// @Description Get product by ID
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.ProductResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/products/{id} [get]
+func (h *ProductHandler) Get(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.productService.GetByID(c.UserContext(), warehouseScope(c), id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Create handles creating a product with an optional image upload
+// @Summary Create product
+// @Description Create a new product, optionally uploading an image (jpeg/png/gif/webp, max 2 MB)
+// @Tags Master
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param name formData string true "Product name"
+// @Param price formData number true "Product price"
+// @Param image formData file false "Product image"
+// @Success 200 {object} models.ProductResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/products [post]
+func (h *ProductHandler) Create(c *fiber.Ctx) error {
+	var req models.ProductRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	image, err := formImage(c, "image")
+	if err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid image upload",
+		})
+	}
+
+	response, err := h.productService.Create(c.UserContext(), &req, image)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Update handles updating a product, replacing or removing its image
+// @Summary Update product
+// @Description Update a product; uploading a new image replaces the old file, remove_image=true deletes it
+// @Tags Master
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param name formData string true "Product name"
+// @Param price formData number true "Product price"
+// @Param image formData file false "Product image"
+// @Param remove_image formData bool false "Remove current image"
+// @Success 200 {object} models.ProductResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/products/{id} [put]
+func (h *ProductHandler) Update(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	var req models.ProductRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	image, err := formImage(c, "image")
+	if err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid image upload",
+		})
+	}
+
+	response, err := h.productService.Update(c.UserContext(), warehouseScope(c), id, &req, image)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Delete handles soft-deleting a product and removing its image
+// @Summary Delete product
+// @Description Soft delete a product and remove its image file
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/products/{id} [delete]
+func (h *ProductHandler) Delete(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	if err := h.productService.Delete(c.UserContext(), warehouseScope(c), id); err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Product deleted",
+	})
+}
+
+// Restore handles restoring a soft-deleted product
+// @Summary Restore product
+// @Description Restore a soft-deleted product
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} models.ProductResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/products/{id}/restore [post]
+func (h *ProductHandler) Restore(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.productService.Restore(c.UserContext(), warehouseScope(c), id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// errorResponse maps product service errors to HTTP responses
+func (h *ProductHandler) errorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, master.ErrWarehouseAccessDenied) {
+		return forbiddenResponse(c, err)
+	}
+	if errors.Is(err, master.ErrProductNotFound) ||
+		errors.Is(err, master.ErrProductNotDeleted) ||
+		errors.Is(err, pkg.ErrInvalidImageType) ||
+		errors.Is(err, pkg.ErrImageTooLarge) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Internal server error",
+	})
+}
@@ -0,0 +1,258 @@
+package master
+
+import (
+	"api/internal/models"
+	"api/internal/services/master"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WarehouseHandler struct {
+	warehouseService master.WarehouseService
+	validator        *validator.Validate
+}
+
+func NewWarehouseHandler(warehouseService master.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseService: warehouseService,
+		validator:        validator.New(),
+	}
+}
+
+// List handles listing warehouses
+// @Summary List warehouses
+// @Description List warehouses with search, pagination and soft-delete filter
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search by name"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size (max 100)"
+// @Param trashed query string false "with|only"
+// @Success 200 {object} models.WarehouseListResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/warehouses [get]
+func (h *WarehouseHandler) List(c *fiber.Ctx) error {
+	var query models.ListQuery
+
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid query parameters",
+		})
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.warehouseService.List(c.UserContext(), &query)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Internal server error",
+		})
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(response.Meta.Total, 10))
+	c.Set("X-Page-Count", strconv.FormatInt(response.Meta.TotalPage, 10))
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Get handles getting a single warehouse
+// @Summary Get warehouse
+// @Description Get warehouse by ID
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} models.WarehouseResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/warehouses/{id} [get]
+func (h *WarehouseHandler) Get(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.warehouseService.GetByID(c.UserContext(), id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Create handles creating a warehouse
+// @Summary Create warehouse
+// @Description Create a new warehouse
+// @Tags Master
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.WarehouseRequest true "Warehouse request"
+// @Success 200 {object} models.WarehouseResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/warehouses [post]
+func (h *WarehouseHandler) Create(c *fiber.Ctx) error {
+	var req models.WarehouseRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.warehouseService.Create(c.UserContext(), &req)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Update handles updating a warehouse
+// @Summary Update warehouse
+// @Description Update an existing warehouse
+// @Tags Master
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Param request body models.WarehouseRequest true "Warehouse request"
+// @Success 200 {object} models.WarehouseResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/warehouses/{id} [put]
+func (h *WarehouseHandler) Update(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	var req models.WarehouseRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.warehouseService.Update(c.UserContext(), id, &req)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Delete handles soft-deleting a warehouse
+// @Summary Delete warehouse
+// @Description Soft delete a warehouse
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/warehouses/{id} [delete]
+func (h *WarehouseHandler) Delete(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	if err := h.warehouseService.Delete(c.UserContext(), id); err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Warehouse deleted",
+	})
+}
+
+// Restore handles restoring a soft-deleted warehouse
+// @Summary Restore warehouse
+// @Description Restore a soft-deleted warehouse
+// @Tags Master
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} models.WarehouseResponse
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/master/warehouses/{id}/restore [post]
+func (h *WarehouseHandler) Restore(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.warehouseService.Restore(c.UserContext(), id)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// errorResponse maps warehouse service errors to HTTP responses
+func (h *WarehouseHandler) errorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, master.ErrWarehouseNotFound) || errors.Is(err, master.ErrWarehouseNotDeleted) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   err.Error(),
+		})
+	}
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Internal server error",
+	})
+}
@@ -0,0 +1,61 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseID parses the ":id" route parameter
+func parseID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("invalid id")
+	}
+	return uint(id), nil
+}
+
+// currentUserID returns the authenticated user ID stored by JWTAuth
+func currentUserID(c *fiber.Ctx) (uint, error) {
+	userIDStr, _ := c.Locals("userID").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(userID), nil
+}
+
+// warehouseScope returns the warehouses the caller may access, as stored by JWTAuth.
+// Without one the scope allows nothing.
+func warehouseScope(c *fiber.Ctx) models.WarehouseScope {
+	scope, _ := c.Locals("warehouseScope").(models.WarehouseScope)
+	return scope
+}
+
+// invalidIDResponse returns the standard response for a malformed ":id" parameter
+func invalidIDResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Invalid ID",
+	})
+}
+
+// invalidUserResponse returns the standard response for a missing or malformed user ID
+func invalidUserResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Invalid user ID",
+	})
+}
+
+// forbiddenResponse returns the standard response for access outside the caller's warehouses
+func forbiddenResponse(c *fiber.Ctx, err error) error {
+	return c.Status(http.StatusForbidden).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Forbidden",
+		"details": err.Error(),
+	})
+}
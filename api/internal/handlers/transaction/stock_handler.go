@@ -0,0 +1,70 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"api/internal/services/transaction"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type StockHandler struct {
+	stockService transaction.StockService
+	validator    *validator.Validate
+}
+
+func NewStockHandler(stockService transaction.StockService) *StockHandler {
+	return &StockHandler{
+		stockService: stockService,
+		validator:    validator.New(),
+	}
+}
+
+// List handles listing per-warehouse stock balances
+// @Summary List stock balances
+// @Description List stock balances per product and warehouse
+// @Tags Transaction
+// @Produce json
+// @Security BearerAuth
+// @Param warehouse_id query int false "Warehouse ID"
+// @Param product_id query int false "Product ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.StockListResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/stock [get]
+func (h *StockHandler) List(c *fiber.Ctx) error {
+	var query models.StockQuery
+
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid query parameters",
+		})
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.stockService.List(c.UserContext(), warehouseScope(c), &query)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(response.Meta.Total, 10))
+	c.Set("X-Page-Count", strconv.FormatInt(response.Meta.TotalPage, 10))
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
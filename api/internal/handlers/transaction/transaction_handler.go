@@ -0,0 +1,254 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"api/internal/services/transaction"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransactionHandler struct {
+	transactionService transaction.TransactionService
+	validator          *validator.Validate
+}
+
+func NewTransactionHandler(transactionService transaction.TransactionService) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		validator:          validator.New(),
+	}
+}
+
+// List handles listing stock transactions
+// @Summary List transactions
+// @Description List stock transactions filtered by warehouse, product and type
+// @Tags Transaction
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search by product name"
+// @Param warehouse_id query int false "Warehouse ID"
+// @Param product_id query int false "Product ID"
+// @Param type query string false "in|out"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size (max 100)"
+// @Param trashed query string false "with|only"
+// @Success 200 {object} models.TransactionListResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transactions [get]
+func (h *TransactionHandler) List(c *fiber.Ctx) error {
+	var query models.TransactionListQuery
+
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid query parameters",
+		})
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.transactionService.List(c.UserContext(), warehouseScope(c), &query)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(response.Meta.Total, 10))
+	c.Set("X-Page-Count", strconv.FormatInt(response.Meta.TotalPage, 10))
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Get handles getting a single transaction
+// @Summary Get transaction
+// @Description Get transaction by ID
+// @Tags Transaction
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transactions/{id} [get]
+func (h *TransactionHandler) Get(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.transactionService.GetByID(c.UserContext(), warehouseScope(c), id)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Create handles posting a stock-in or stock-out movement
+// @Summary Create transaction
+// @Description Post a stock movement; "out" movements are rejected when stock is insufficient
+// @Tags Transaction
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TransactionRequest true "Transaction request"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transactions [post]
+func (h *TransactionHandler) Create(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return invalidUserResponse(c)
+	}
+
+	var req models.TransactionRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.transactionService.Create(c.UserContext(), warehouseScope(c), userID, &req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Reverse handles posting a compensating movement for a transaction
+// @Summary Reverse transaction
+// @Description Post an opposite movement linked to the original transaction, restoring its stock effect
+// @Tags Transaction
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} models.TransactionResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transactions/{id}/reverse [post]
+func (h *TransactionHandler) Reverse(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return invalidUserResponse(c)
+	}
+
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.transactionService.Reverse(c.UserContext(), warehouseScope(c), userID, id)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Delete handles soft-deleting a transaction
+// @Summary Delete transaction
+// @Description Soft delete a transaction and restore the stock it changed
+// @Tags Transaction
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transactions/{id} [delete]
+func (h *TransactionHandler) Delete(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	if err := h.transactionService.Delete(c.UserContext(), warehouseScope(c), id); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    "Transaction deleted",
+	})
+}
+
+// errorResponse maps transaction service errors to HTTP responses
+func errorResponse(c *fiber.Ctx, err error) error {
+	if errors.Is(err, transaction.ErrWarehouseAccessDenied) {
+		return forbiddenResponse(c, err)
+	}
+
+	var stockErr *transaction.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   transaction.ErrInsufficientStock.Error(),
+			"details": fiber.Map{
+				"product_id":   stockErr.ProductID,
+				"warehouse_id": stockErr.WarehouseID,
+				"available":    stockErr.Available,
+				"requested":    stockErr.Requested,
+			},
+		})
+	}
+
+	if errors.Is(err, transaction.ErrTransactionNotFound) ||
+		errors.Is(err, transaction.ErrTransactionAlreadyReversed) ||
+		errors.Is(err, transaction.ErrTransactionIsReversal) ||
+		errors.Is(err, transaction.ErrTransactionPartOfTransfer) ||
+		errors.Is(err, transaction.ErrTransferNotFound) ||
+		errors.Is(err, transaction.ErrTransferNotInTransit) ||
+		errors.Is(err, transaction.ErrReceivedQuantityTooLarge) ||
+		errors.Is(err, transaction.ErrWarehouseNotFound) ||
+		errors.Is(err, transaction.ErrProductNotFound) ||
+		errors.Is(err, transaction.ErrInvalidQuantity) {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Internal server error",
+	})
+}
@@ -0,0 +1,200 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"api/internal/services/transaction"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TransferHandler struct {
+	transferService transaction.TransferService
+	validator       *validator.Validate
+}
+
+func NewTransferHandler(transferService transaction.TransferService) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+		validator:       validator.New(),
+	}
+}
+
+// List handles listing inter-warehouse transfers
+// @Summary List transfers
+// @Description List transfers filtered by warehouse (source or destination), product and status
+// @Tags Transfer
+// @Produce json
+// @Security BearerAuth
+// @Param warehouse_id query int false "Source or destination warehouse ID"
+// @Param product_id query int false "Product ID"
+// @Param status query string false "in_transit|completed"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} models.TransferListResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transfers [get]
+func (h *TransferHandler) List(c *fiber.Ctx) error {
+	var query models.TransferListQuery
+
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid query parameters",
+		})
+	}
+
+	if err := h.validator.Struct(&query); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.transferService.List(c.UserContext(), warehouseScope(c), &query)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	c.Set("X-Total-Count", strconv.FormatInt(response.Meta.Total, 10))
+	c.Set("X-Page-Count", strconv.FormatInt(response.Meta.TotalPage, 10))
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Get handles getting a single transfer
+// @Summary Get transfer
+// @Description Get transfer by ID including its out/in transactions
+// @Tags Transfer
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transfer ID"
+// @Success 200 {object} models.TransferResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transfers/{id} [get]
+func (h *TransferHandler) Get(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	response, err := h.transferService.GetByID(c.UserContext(), warehouseScope(c), id)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Create handles moving stock between warehouses
+// @Summary Create transfer
+// @Description Post an "out" movement at the source and, unless in_transit is set, an "in" movement at the destination
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TransferRequest true "Transfer request"
+// @Success 200 {object} models.TransferResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transfers [post]
+func (h *TransferHandler) Create(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return invalidUserResponse(c)
+	}
+
+	var req models.TransferRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.transferService.Create(c.UserContext(), warehouseScope(c), userID, &req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
+
+// Receive handles confirming arrival of an in-transit transfer
+// @Summary Receive transfer
+// @Description Post the destination "in" movement for the received quantity and complete the transfer
+// @Tags Transfer
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transfer ID"
+// @Param request body models.TransferReceiveRequest true "Receive request"
+// @Success 200 {object} models.TransferResponse
+// @Failure 403 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /api/v1/transfers/{id}/receive [post]
+func (h *TransferHandler) Receive(c *fiber.Ctx) error {
+	userID, err := currentUserID(c)
+	if err != nil {
+		return invalidUserResponse(c)
+	}
+
+	id, err := parseID(c)
+	if err != nil {
+		return invalidIDResponse(c)
+	}
+
+	var req models.TransferReceiveRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	}
+
+	if err := h.validator.Struct(&req); err != nil {
+		return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	response, err := h.transferService.Receive(c.UserContext(), warehouseScope(c), userID, id, &req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "success",
+		"data":    response,
+	})
+}
@@ -0,0 +1,94 @@
+package middlewares
+
+import (
+	"api/internal/services/auth"
+	"errors"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHeader carries a personal API key instead of a bearer token
+const APIKeyHeader = "X-API-Key"
+
+type APIKeyMiddleware struct {
+	apiKeyService      auth.APIKeyService
+	roleService        auth.RoleService
+	verificationPolicy auth.VerificationPolicy
+}
+
+func NewAPIKeyMiddleware(apiKeyService auth.APIKeyService, roleService auth.RoleService, verificationPolicy auth.VerificationPolicy) *APIKeyMiddleware {
+	return &APIKeyMiddleware{
+		apiKeyService:      apiKeyService,
+		roleService:        roleService,
+		verificationPolicy: verificationPolicy,
+	}
+}
+
+// APIKeyAuth middleware validates the X-API-Key header and sets the same locals as JWTAuth.
+// The permissions are those of the owner's current roles, limited to the key's scopes.
+func (m *APIKeyMiddleware) APIKeyAuth() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		rawKey := c.Get(APIKeyHeader)
+		if rawKey == "" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "X-API-Key header is required",
+			})
+		}
+
+		key, user, err := m.apiKeyService.Authenticate(c.UserContext(), rawKey)
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidAPIKey) {
+				RecordAuthAttempt("api_key", "failure")
+				return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+					"message": "failed",
+					"error":   "Invalid or expired API key",
+				})
+			}
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Internal server error",
+			})
+		}
+		RecordAuthAttempt("api_key", "success")
+
+		roles := user.RoleNames()
+		permissions, err := m.roleService.Permissions(c.UserContext(), roles)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Internal server error",
+			})
+		}
+		permissions = auth.ScopedPermissions(permissions, key)
+
+		// Apply the email verification policy to users who have not verified yet
+		if user.EmailVerifiedAt == nil {
+			switch m.verificationPolicy {
+			case auth.VerificationPolicyBlock:
+				return emailNotVerifiedResponse(c)
+			case auth.VerificationPolicyReadOnly:
+				permissions = readOnlyPermissions(permissions)
+			}
+		}
+
+		storeIdentity(c, user.ID, roles, permissions, user.WarehouseIDs())
+		c.Locals("apiKeyID", key.ID)
+
+		return c.Next()
+	}
+}
+
+// JWTOrAPIKeyAuth authenticates with APIKeyAuth when the X-API-Key header is present and with
+// JWTAuth otherwise, for routes that machine clients may call
+func JWTOrAPIKeyAuth(jwtMiddleware *JWTMiddleware, apiKeyMiddleware *APIKeyMiddleware) fiber.Handler {
+	jwtAuth := jwtMiddleware.JWTAuth()
+	apiKeyAuth := apiKeyMiddleware.APIKeyAuth()
+	return func(c *fiber.Ctx) error {
+		if c.Get(APIKeyHeader) != "" {
+			return apiKeyAuth(c)
+		}
+		return jwtAuth(c)
+	}
+}
@@ -1,80 +1,56 @@
 package middlewares
 
 import (
+	"api/config"
+	"sort"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 )
 
-// CORSConfig holds the configuration for CORS middleware
-type CORSConfig struct {
-	AllowOrigins     string
-	AllowMethods     string
-	AllowHeaders     string
-	AllowCredentials bool
-	ExposeHeaders    string
-	MaxAge           int
+// corsRoute is the CORS handler for the routes under path
+type corsRoute struct {
+	path    string
+	handler fiber.Handler
 }
 
-// DefaultCORSConfig returns default CORS configuration
-func DefaultCORSConfig() CORSConfig {
-	return CORSConfig{
-		AllowOrigins:     "*",
-		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With,X-API-Key,X-Client-ID,X-Client-Version",
-		AllowCredentials: false,
-		ExposeHeaders:    "Content-Length,Content-Range,X-Total-Count,X-Page-Count",
-		MaxAge:           86400, // 24 hours
-	}
-}
+// NewCORS creates the CORS middleware from the configured policy. Requests under a group path
+// use that group's policy instead, the longest matching path winning. It is registered once on
+// the app, before the routes, so preflight requests are answered with the right policy.
+func NewCORS(cfg config.CORSConfig) fiber.Handler {
+	defaultHandler := newCORSHandler(cfg.CORSPolicy)
 
-// ProductionCORSConfig returns production-safe CORS configuration
-func ProductionCORSConfig() CORSConfig {
-	return CORSConfig{
-		AllowOrigins:     "http://localhost:3000,http://localhost:8000,https://yourdomain.com",
-		AllowMethods:     "GET,POST,HEAD,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With,X-API-Key,X-Client-ID,X-Client-Version",
-		AllowCredentials: true,
-		ExposeHeaders:    "Content-Length,Content-Range,X-Total-Count,X-Page-Count",
-		MaxAge:           3600, // 1 hour
+	routes := make([]corsRoute, 0, len(cfg.Groups))
+	for _, group := range cfg.Groups {
+		routes = append(routes, corsRoute{
+			path:    strings.TrimSuffix(group.Path, "/"),
+			handler: newCORSHandler(group.CORSPolicy),
+		})
 	}
-}
-
-// NewCORS creates a new CORS middleware with default configuration
-func NewCORS() fiber.Handler {
-	config := DefaultCORSConfig()
-	
-	return cors.New(cors.Config{
-		AllowOrigins:     config.AllowOrigins,
-		AllowMethods:     config.AllowMethods,
-		AllowHeaders:     config.AllowHeaders,
-		AllowCredentials: config.AllowCredentials,
-		ExposeHeaders:    config.ExposeHeaders,
-		MaxAge:           config.MaxAge,
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].path) > len(routes[j].path)
 	})
-}
 
-// NewCORSWithConfig creates a new CORS middleware with custom configuration
-func NewCORSWithConfig(config CORSConfig) fiber.Handler {
-	return cors.New(cors.Config{
-		AllowOrigins:     config.AllowOrigins,
-		AllowMethods:     config.AllowMethods,
-		AllowHeaders:     config.AllowHeaders,
-		AllowCredentials: config.AllowCredentials,
-		ExposeHeaders:    config.ExposeHeaders,
-		MaxAge:           config.MaxAge,
-	})
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		for _, route := range routes {
+			if path == route.path || strings.HasPrefix(path, route.path+"/") {
+				return route.handler(c)
+			}
+		}
+		return defaultHandler(c)
+	}
 }
 
-// NewProductionCORS creates a new CORS middleware with production configuration
-func NewProductionCORS() fiber.Handler {
-	config := ProductionCORSConfig()
-	
+// newCORSHandler expects a policy validated by config.Load; cors.New panics on "*" with credentials
+func newCORSHandler(policy config.CORSPolicy) fiber.Handler {
 	return cors.New(cors.Config{
-		AllowOrigins:     config.AllowOrigins,
-		AllowMethods:     config.AllowMethods,
-		AllowHeaders:     config.AllowHeaders,
-		AllowCredentials: config.AllowCredentials,
-		ExposeHeaders:    config.ExposeHeaders,
-		MaxAge:           config.MaxAge,
+		AllowOrigins:     strings.Join(policy.AllowOrigins, ","),
+		AllowMethods:     strings.Join(policy.AllowMethods, ","),
+		AllowHeaders:     strings.Join(policy.AllowHeaders, ","),
+		AllowCredentials: policy.AllowCredentials,
+		ExposeHeaders:    strings.Join(policy.ExposeHeaders, ","),
+		MaxAge:           policy.MaxAge,
 	})
-}
\ No newline at end of file
+}
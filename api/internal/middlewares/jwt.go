@@ -1,8 +1,13 @@
 package middlewares
 
 import (
+	"api/internal/models"
 	"api/internal/services/auth"
+	"api/pkg"
+	"errors"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -10,12 +15,16 @@ import (
 )
 
 type JWTMiddleware struct {
-	jwtService auth.JWTService
+	jwtService         auth.JWTService
+	roleService        auth.RoleService
+	verificationPolicy auth.VerificationPolicy
 }
 
-func NewJWTMiddleware(jwtService auth.JWTService) *JWTMiddleware {
+func NewJWTMiddleware(jwtService auth.JWTService, roleService auth.RoleService, verificationPolicy auth.VerificationPolicy) *JWTMiddleware {
 	return &JWTMiddleware{
-		jwtService: jwtService,
+		jwtService:         jwtService,
+		roleService:        roleService,
+		verificationPolicy: verificationPolicy,
 	}
 }
 
@@ -48,8 +57,16 @@ func (m *JWTMiddleware) JWTAuth() fiber.Handler {
 			})
 		}
 
-		// Validate token
-		token, err := m.jwtService.ValidateToken(tokenString)
+		// Validate token (signature, expiry and revocation)
+		token, err := m.jwtService.ValidateToken(c.UserContext(), tokenString)
+		if errors.Is(err, auth.ErrTokenRevoked) {
+			RecordJWTTokenValidation("revoked")
+
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Token has been revoked",
+			})
+		}
 		if err != nil {
 			// Record failed JWT validation
 			RecordJWTTokenValidation("invalid")
@@ -72,8 +89,55 @@ func (m *JWTMiddleware) JWTAuth() fiber.Handler {
 			})
 		}
 
+		// Resolve the roles embedded in the token for RequirePermission
+		roles, err := m.jwtService.ExtractRoles(token)
+		if err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Invalid token claims",
+			})
+		}
+		permissions, err := m.roleService.Permissions(c.UserContext(), roles)
+		if err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Internal server error",
+			})
+		}
+
+		// Apply the email verification policy to users who have not verified yet
+		emailVerified, err := m.jwtService.ExtractEmailVerified(token)
+		if err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Invalid token claims",
+			})
+		}
+		if !emailVerified {
+			switch m.verificationPolicy {
+			case auth.VerificationPolicyBlock:
+				return emailNotVerifiedResponse(c)
+			case auth.VerificationPolicyReadOnly:
+				permissions = readOnlyPermissions(permissions)
+			}
+		}
+
+		// Resolve the warehouses the user may work in
+		warehouseIDs, err := m.jwtService.ExtractWarehouseIDs(token)
+		if err != nil {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Invalid token claims",
+			})
+		}
+
 		// Store user ID in context for use in handlers
-		c.Locals("userID", strconv.FormatUint(uint64(userID), 10))
+		storeIdentity(c, userID, roles, permissions, warehouseIDs)
+		// Keep the raw token so logout can revoke this session
+		c.Locals("accessToken", tokenString)
+		// Lets the session list mark the session of this request
+		sessionID, _ := m.jwtService.ExtractSessionID(token)
+		c.Locals("sessionID", sessionID)
 
 		return c.Next()
 	}
@@ -100,7 +164,7 @@ func (m *JWTMiddleware) OptionalJWTAuth() fiber.Handler {
 		}
 
 		// Validate token
-		token, err := m.jwtService.ValidateToken(tokenString)
+		token, err := m.jwtService.ValidateToken(c.UserContext(), tokenString)
 		if err != nil {
 			return c.Next()
 		}
@@ -113,7 +177,43 @@ func (m *JWTMiddleware) OptionalJWTAuth() fiber.Handler {
 
 		// Store user ID in context for use in handlers
 		c.Locals("userID", strconv.FormatUint(uint64(userID), 10))
+		pkg.AddLogAttrs(c.UserContext(), slog.Uint64("user_id", uint64(userID)))
 
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// readOnlyPermissions keeps the "<resource>:read" permissions
+func readOnlyPermissions(permissions map[string]bool) map[string]bool {
+	readOnly := make(map[string]bool, len(permissions))
+	for permission, granted := range permissions {
+		if granted && strings.HasSuffix(permission, ":read") {
+			readOnly[permission] = true
+		}
+	}
+	return readOnly
+}
+
+// storeIdentity stores the locals read by handlers, RequirePermission and the warehouse scope.
+// Admins are not limited to their assigned warehouses.
+func storeIdentity(c *fiber.Ctx, userID uint, roles []string, permissions map[string]bool, warehouseIDs []uint) {
+	scope := models.WarehouseScope{WarehouseIDs: warehouseIDs}
+	if slices.Contains(roles, models.RoleAdmin) {
+		scope = models.AllWarehouses()
+	}
+
+	c.Locals("userID", strconv.FormatUint(uint64(userID), 10))
+	c.Locals("roles", roles)
+	c.Locals("permissions", permissions)
+	c.Locals("warehouseScope", scope)
+	// Lets RequestLogger and records logged during the request carry the user
+	pkg.AddLogAttrs(c.UserContext(), slog.Uint64("user_id", uint64(userID)))
+}
+
+func emailNotVerifiedResponse(c *fiber.Ctx) error {
+	return c.Status(http.StatusForbidden).JSON(fiber.Map{
+		"message": "failed",
+		"error":   "Forbidden",
+		"details": auth.ErrEmailNotVerified.Error(),
+	})
+}
@@ -0,0 +1,18 @@
+package middlewares
+
+import (
+	"api/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TrustProxy sets the fiber.Config fields that make c.IP() return the client IP forwarded by a
+// trusted reverse proxy. Requests from any other address keep their connection IP, and an
+// invalid header value falls back to it too.
+func TrustProxy(fiberConfig fiber.Config, cfg config.ProxyConfig) fiber.Config {
+	fiberConfig.ProxyHeader = cfg.Header
+	fiberConfig.EnableTrustedProxyCheck = true
+	fiberConfig.TrustedProxies = cfg.TrustedProxies
+	fiberConfig.EnableIPValidation = true
+	return fiberConfig
+}
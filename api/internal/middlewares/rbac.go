@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission allows the request only when the roles of the signed-in user grant permission.
+// It must be stacked after JWTAuth, which resolves the permissions of the token's roles.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, ok := c.Locals("permissions").(map[string]bool)
+		if !ok {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Unauthorized",
+			})
+		}
+
+		if !permissions[permission] {
+			return c.Status(http.StatusForbidden).JSON(fiber.Map{
+				"message": "failed",
+				"error":   "Forbidden",
+				"details": "missing permission " + permission,
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"api/pkg"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+const (
+	// RequestIDHeader carries the request ID in requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDLocal is the fiber.Ctx local holding the request ID
+	RequestIDLocal = "requestID"
+)
+
+// validRequestID keeps client IDs short and safe to log and echo
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID reuses the client's X-Request-ID or generates a UUID when it is missing or invalid.
+// The ID is stored in c.Locals(RequestIDLocal) and in c.UserContext(), where log records and
+// GORM queries pick it up, echoed in the response header and added to JSON error bodies.
+// Register it first, before RequestLogger.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = utils.UUIDv4()
+		}
+
+		c.Locals(RequestIDLocal, requestID)
+		c.SetUserContext(pkg.WithRequestID(c.UserContext(), requestID))
+		c.Set(RequestIDHeader, requestID)
+
+		err := c.Next()
+		addRequestIDToErrorBody(c, requestID)
+		return err
+	}
+}
+
+// addRequestIDToErrorBody appends "request_id" to JSON object bodies of 4xx and 5xx responses
+// that do not have one yet, so clients can quote it when reporting the error
+func addRequestIDToErrorBody(c *fiber.Ctx, requestID string) {
+	resp := c.Response()
+	if resp.StatusCode() < fiber.StatusBadRequest || resp.IsBodyStream() {
+		return
+	}
+	if !strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		return
+	}
+
+	body := bytes.TrimSpace(resp.Body())
+	if len(body) < 2 || body[0] != '{' || body[len(body)-1] != '}' || bytes.Contains(body, []byte(`"request_id"`)) {
+		return
+	}
+	value, err := json.Marshal(requestID)
+	if err != nil {
+		return
+	}
+
+	fields := bytes.TrimSpace(body[1 : len(body)-1])
+	updated := make([]byte, 0, len(body)+len(value)+16)
+	updated = append(updated, '{')
+	updated = append(updated, fields...)
+	if len(fields) > 0 {
+		updated = append(updated, ',')
+	}
+	updated = append(updated, `"request_id":`...)
+	updated = append(updated, value...)
+	updated = append(updated, '}')
+	resp.SetBodyRaw(updated)
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"api/pkg"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestLogger logs one line per request with its request ID, user ID, route, status and
+// latency. Register it right after RequestID so it also sees errors and recovered panics; it
+// runs the app's ErrorHandler itself to log the final status. Records logged with
+// c.UserContext() during the request carry the request ID, method, path and, once
+// authenticated, the user ID.
+func RequestLogger(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		ctx := pkg.WithLogAttrs(c.UserContext(),
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+		)
+		c.SetUserContext(ctx)
+
+		chainErr := c.Next()
+		if chainErr != nil {
+			if err := c.App().ErrorHandler(c, chainErr); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		fields := []slog.Attr{
+			slog.String("route", c.Route().Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("ip", c.IP()),
+			slog.Int("bytes", len(c.Response().Body())),
+		}
+		if chainErr != nil {
+			fields = append(fields, slog.String("error", chainErr.Error()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx, level, "request", fields...)
+		return nil
+	}
+}
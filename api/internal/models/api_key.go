@@ -0,0 +1,72 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey is a personal API key that lets a machine client act as its owner through the
+// X-API-Key header. Only the SHA-256 hash of the key is stored; the plain key is shown once.
+type APIKey struct {
+	ID     uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID uint   `json:"user_id" gorm:"not null;index"`
+	Name   string `json:"name" gorm:"type:varchar(100);not null"`
+	// Prefix is the start of the plain key, enough to recognise it in a list
+	Prefix  string `json:"prefix" gorm:"type:varchar(16);not null"`
+	KeyHash string `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	// Scopes is a comma separated list of permissions; the key never exceeds its owner's roles
+	Scopes     string     `json:"-" gorm:"type:varchar(500);not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList returns the permissions granted to the key
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// APIKeyResponse describes an API key without its secret
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToResponse converts APIKey to APIKeyResponse
+func (k *APIKey) ToResponse() APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.ScopeList(),
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+// CreateAPIKeyRequest creates an API key limited to scopes; it expires after ExpiresInDays (default 90)
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+// CreatedAPIKeyResponse carries the plain key; it cannot be retrieved again
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerificationToken is a single-use token proving ownership of a user's email. Only the
+// SHA-256 hash of the token is stored; the plain token exists only in the email sent to the user.
+type EmailVerificationToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for EmailVerificationToken model
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
+
+// VerifyEmailRequest confirms an email using the token from the verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest asks for a new verification link to be sent to email
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
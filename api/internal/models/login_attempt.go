@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginAttempt counts recent failed sign-ins for one key, either "email:<address>" or "ip:<address>".
+// Failures older than the tracking window no longer count; LockedUntil blocks sign-in for the key.
+type LoginAttempt struct {
+	Key           string     `json:"key" gorm:"column:attempt_key;type:varchar(191);primaryKey"`
+	Failures      uint       `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at" gorm:"not null;index"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	UpdatedAt     time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for LoginAttempt model
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
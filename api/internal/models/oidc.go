@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+)
+
+// OIDCState holds a started OIDC sign-in between the redirect to the provider and the callback.
+// Only the SHA-256 hash of the state is stored; the plain state travels through the browser.
+type OIDCState struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	StateHash    string    `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	CodeVerifier string    `json:"-" gorm:"type:varchar(128);not null"`
+	Nonce        string    `json:"-" gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for OIDCState model
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
+
+// UserIdentity links a user to an account of an external identity provider
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Issuer    string    `json:"issuer" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	Subject   string    `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for UserIdentity model
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OIDCAuthorizationResponse carries the provider URL the client redirects the browser to
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// OIDCCallbackRequest carries the code and state the provider redirected back with
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// ListQuery represents common list query parameters (search, paging and soft-delete filter)
+type ListQuery struct {
+	Search  string `query:"search" validate:"omitempty,max=100"`
+	Page    int    `query:"page" validate:"omitempty,min=1"`
+	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Trashed string `query:"trashed" validate:"omitempty,oneof=with only"` // "with" includes deleted rows, "only" returns deleted rows
+}
+
+// Normalize applies default paging values
+func (q *ListQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = 10
+	}
+}
+
+// Offset returns the row offset for the current page
+func (q *ListQuery) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+// PaginationMeta represents paging information for list responses
+type PaginationMeta struct {
+	Page      int   `json:"page"`
+	Limit     int   `json:"limit"`
+	Total     int64 `json:"total"`
+	TotalPage int64 `json:"total_page"`
+}
+
+// NewPaginationMeta builds PaginationMeta from a normalized query and total row count
+func NewPaginationMeta(q *ListQuery, total int64) PaginationMeta {
+	totalPage := total / int64(q.Limit)
+	if total%int64(q.Limit) != 0 {
+		totalPage++
+	}
+	return PaginationMeta{
+		Page:      q.Page,
+		Limit:     q.Limit,
+		Total:     total,
+		TotalPage: totalPage,
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// PasswordResetToken is a single-use password reset token. Only the SHA-256 hash of the token
+// is stored; the plain token exists only in the email sent to the user.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for PasswordResetToken model
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// ForgotPasswordRequest asks for a reset link to be sent to email
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest sets a new password using the token from the reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"api/pkg"
 	"time"
 	"gorm.io/gorm"
 )
@@ -23,18 +24,20 @@ func (Product) TableName() string {
 
 // ProductResponse represents the product data for API responses
 type ProductResponse struct {
-	ID        uint      `json:"id"`
-	Name      *string   `json:"name"`
-	Price     *float64  `json:"price"`
-	Stock     *float64  `json:"stock"`
-	Image     *string   `json:"image"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint       `json:"id"`
+	Name      *string    `json:"name"`
+	Price     *float64   `json:"price"`
+	Stock     *float64   `json:"stock"`
+	Image     *string    `json:"image"`
+	ImageURL  *string    `json:"image_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // ToResponse converts Product to ProductResponse
 func (p *Product) ToResponse() ProductResponse {
-	return ProductResponse{
+	response := ProductResponse{
 		ID:        p.ID,
 		Name:      p.Name,
 		Price:     p.Price,
@@ -43,4 +46,25 @@ func (p *Product) ToResponse() ProductResponse {
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt,
 	}
+	if p.Image != nil && *p.Image != "" {
+		imageURL := pkg.GetImageURL("products", *p.Image)
+		response.ImageURL = &imageURL
+	}
+	if p.DeletedAt.Valid {
+		response.DeletedAt = &p.DeletedAt.Time
+	}
+	return response
+}
+
+// ProductRequest represents create/update product request (multipart/form-data or JSON)
+type ProductRequest struct {
+	Name        string   `json:"name" form:"name" validate:"required,min=2,max=100"`
+	Price       *float64 `json:"price" form:"price" validate:"required,gte=0"`
+	RemoveImage bool     `json:"remove_image" form:"remove_image"` // only used on update
+}
+
+// ProductListResponse represents paginated product list response
+type ProductListResponse struct {
+	Items []ProductResponse `json:"items"`
+	Meta  PaginationMeta    `json:"meta"`
 }
\ No newline at end of file
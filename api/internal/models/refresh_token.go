@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken is the server-side record of an issued refresh token.
+// Every sign-in starts a new family; each refresh rotates the token within its family
+// and marks the presented one as used. Presenting a used token again revokes the family.
+type RefreshToken struct {
+	JTI       string     `json:"jti" gorm:"column:jti;type:varchar(64);primaryKey"`
+	FamilyID  string     `json:"family_id" gorm:"type:varchar(64);not null;index"`
+	ParentJTI *string    `json:"parent_jti" gorm:"column:parent_jti;type:varchar(64)"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,28 @@
+package models
+
+// AllModels lists every model persisted by the API.
+// The startup schema check compares each of them with its database table.
+func AllModels() []interface{} {
+	return []interface{}{
+		&User{},
+		&Warehouse{},
+		&Product{},
+		&Transaction{},
+		&StockBalance{},
+		&Transfer{},
+		&RevokedToken{},
+		&RefreshToken{},
+		&Role{},
+		&Permission{},
+		&UserWarehouse{},
+		&PasswordResetToken{},
+		&EmailVerificationToken{},
+		&LoginAttempt{},
+		&UserTOTP{},
+		&RecoveryCode{},
+		&APIKey{},
+		&Session{},
+		&OIDCState{},
+		&UserIdentity{},
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// RevokedToken blocks a single JWT by its jti until the token would have expired anyway
+type RevokedToken struct {
+	JTI       string    `json:"jti" gorm:"column:jti;type:varchar(64);primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for RevokedToken model
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
+
+// LogoutRequest represents the optional logout body; the refresh token of the session is revoked too
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+)
+
+// Built-in roles, created by migration 000007
+const (
+	RoleAdmin            = "admin"
+	RoleWarehouseManager = "warehouse_manager"
+	RoleClerk            = "clerk"
+	RoleViewer           = "viewer"
+
+	// DefaultRole is given to users who sign up themselves
+	DefaultRole = RoleViewer
+)
+
+// Permissions checked by middlewares.RequirePermission, named "<resource>:<action>"
+const (
+	PermissionWarehousesRead    = "warehouses:read"
+	PermissionWarehousesWrite   = "warehouses:write"
+	PermissionProductsRead      = "products:read"
+	PermissionProductsWrite     = "products:write"
+	PermissionTransactionsRead  = "transactions:read"
+	PermissionTransactionsWrite = "transactions:write"
+	PermissionStockRead         = "stock:read"
+	PermissionTransfersRead     = "transfers:read"
+	PermissionTransfersWrite    = "transfers:write"
+	PermissionUsersManage       = "users:manage"
+)
+
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string       `json:"name" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Description string       `json:"description" gorm:"type:varchar(255)"`
+	Permissions []Permission `json:"permissions,omitempty" gorm:"many2many:role_permissions"`
+	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+type Permission struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Description string    `json:"description" gorm:"type:varchar(255)"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for Permission model
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// RoleResponse represents a role with the names of its permissions
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// ToResponse converts Role to RoleResponse
+func (r *Role) ToResponse() RoleResponse {
+	permissions := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		permissions = append(permissions, permission.Name)
+	}
+	return RoleResponse{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: permissions,
+	}
+}
+
+// AssignRolesRequest replaces every role of a user
+type AssignRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,min=1,dive,required"`
+}
+
+// UserRolesResponse lists the roles of a user
+type UserRolesResponse struct {
+	UserID uint     `json:"user_id"`
+	Roles  []string `json:"roles"`
+}
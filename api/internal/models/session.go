@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// Session is a sign-in as seen by the user: one per refresh token family, created when the
+// first refresh token is issued and updated on every rotation. Revoking a session revokes its
+// refresh tokens and every access token issued in it.
+type Session struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	FamilyID   string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent  string     `json:"user_agent" gorm:"type:varchar(255);not null;default:''"`
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(45);not null;default:''"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null;index"`
+	LastUsedAt time.Time  `json:"last_used_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for Session model
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// ClientInfo describes the client a token is issued to, recorded on its session
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// SessionResponse describes an active session; Current marks the session of the request
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ToResponse converts Session to SessionResponse
+func (s *Session) ToResponse(currentFamilyID string) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		Current:    currentFamilyID != "" && s.FamilyID == currentFamilyID,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"time"
+)
+
+// StockBalance holds the on-hand quantity of a product in one warehouse.
+// Balances are maintained by transaction postings; Product.Stock is their total.
+type StockBalance struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ProductID   uint      `json:"product_id" gorm:"not null;uniqueIndex:idx_stock_balances_product_warehouse"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"not null;uniqueIndex:idx_stock_balances_product_warehouse;index"`
+	Quantity    float64   `json:"quantity" gorm:"type:decimal(20,2);not null;default:0"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Product   *Product   `json:"product,omitempty" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Warehouse *Warehouse `json:"warehouse,omitempty" gorm:"foreignKey:WarehouseID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+}
+
+// TableName specifies the table name for StockBalance model
+func (StockBalance) TableName() string {
+	return "stock_balances"
+}
+
+// StockBalanceResponse represents the stock balance data for API responses
+type StockBalanceResponse struct {
+	ProductID   uint               `json:"product_id"`
+	WarehouseID uint               `json:"warehouse_id"`
+	Quantity    float64            `json:"quantity"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Product     *ProductResponse   `json:"product,omitempty"`
+	Warehouse   *WarehouseResponse `json:"warehouse,omitempty"`
+}
+
+// ToResponse converts StockBalance to StockBalanceResponse
+func (b *StockBalance) ToResponse() StockBalanceResponse {
+	response := StockBalanceResponse{
+		ProductID:   b.ProductID,
+		WarehouseID: b.WarehouseID,
+		Quantity:    b.Quantity,
+		UpdatedAt:   b.UpdatedAt,
+	}
+
+	// Include related models if they are loaded
+	if b.Product != nil {
+		productResponse := b.Product.ToResponse()
+		response.Product = &productResponse
+	}
+	if b.Warehouse != nil {
+		warehouseResponse := b.Warehouse.ToResponse()
+		response.Warehouse = &warehouseResponse
+	}
+
+	return response
+}
+
+// StockQuery represents stock balance query parameters
+type StockQuery struct {
+	WarehouseID uint `query:"warehouse_id"`
+	ProductID   uint `query:"product_id"`
+	Page        int  `query:"page" validate:"omitempty,min=1"`
+	Limit       int  `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Paging returns the normalized paging part of the query
+func (q *StockQuery) Paging() *ListQuery {
+	paging := &ListQuery{Page: q.Page, Limit: q.Limit}
+	paging.Normalize()
+	return paging
+}
+
+// StockListResponse represents paginated stock balance list response
+type StockListResponse struct {
+	Items []StockBalanceResponse `json:"items"`
+	Meta  PaginationMeta         `json:"meta"`
+}
+
+// StockDifference describes a balance that does not match the transaction ledger
+type StockDifference struct {
+	ProductID   uint    `json:"product_id"`
+	WarehouseID uint    `json:"warehouse_id"`
+	Recorded    float64 `json:"recorded"`
+	Ledger      float64 `json:"ledger"`
+}
+
+// StockReconcileResult summarizes a stock balance rebuild
+type StockReconcileResult struct {
+	Balances    int               `json:"balances"`
+	Differences []StockDifference `json:"differences"`
+	Negative    []StockDifference `json:"negative"`
+	Applied     bool              `json:"applied"`
+}
@@ -5,6 +5,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Transaction types
+const (
+	TransactionTypeIn  = "in"
+	TransactionTypeOut = "out"
+)
+
 type Transaction struct {
 	ID          uint           `json:"id" gorm:"primaryKey;autoIncrement"`
 	UserID      *uint          `json:"user_id" gorm:"default:null;index"`
@@ -12,6 +18,8 @@ type Transaction struct {
 	ProductID   *uint          `json:"product_id" gorm:"default:null;index"`
 	Type        *string        `json:"type" gorm:"type:enum('in','out');default:null"`
 	Quantity    *float64       `json:"quantity" gorm:"type:decimal(20,2);default:null"`
+	ReversalOf  *uint          `json:"reversal_of" gorm:"default:null;index"` // ID of the transaction this row reverses
+	TransferID  *uint          `json:"transfer_id" gorm:"default:null;index"` // set on both legs of a warehouse transfer
 	CreatedAt   time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt   time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
@@ -35,6 +43,8 @@ type TransactionResponse struct {
 	ProductID   *uint               `json:"product_id"`
 	Type        *string             `json:"type"`
 	Quantity    *float64            `json:"quantity"`
+	ReversalOf  *uint               `json:"reversal_of"`
+	TransferID  *uint               `json:"transfer_id"`
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
 	User        *UserResponse       `json:"user,omitempty"`
@@ -51,6 +61,8 @@ func (t *Transaction) ToResponse() TransactionResponse {
 		ProductID:   t.ProductID,
 		Type:        t.Type,
 		Quantity:    t.Quantity,
+		ReversalOf:  t.ReversalOf,
+		TransferID:  t.TransferID,
 		CreatedAt:   t.CreatedAt,
 		UpdatedAt:   t.UpdatedAt,
 	}
@@ -70,4 +82,26 @@ func (t *Transaction) ToResponse() TransactionResponse {
 	}
 
 	return response
-}
\ No newline at end of file
+}
+
+// TransactionRequest represents stock-in/stock-out request
+type TransactionRequest struct {
+	WarehouseID uint    `json:"warehouse_id" validate:"required"`
+	ProductID   uint    `json:"product_id" validate:"required"`
+	Type        string  `json:"type" validate:"required,oneof=in out"`
+	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// TransactionListQuery represents transaction list query parameters
+type TransactionListQuery struct {
+	ListQuery
+	WarehouseID uint   `query:"warehouse_id"`
+	ProductID   uint   `query:"product_id"`
+	Type        string `query:"type" validate:"omitempty,oneof=in out"`
+}
+
+// TransactionListResponse represents paginated transaction list response
+type TransactionListResponse struct {
+	Items []TransactionResponse `json:"items"`
+	Meta  PaginationMeta        `json:"meta"`
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+)
+
+// Transfer statuses
+const (
+	TransferStatusInTransit = "in_transit"
+	TransferStatusCompleted = "completed"
+)
+
+// Transfer moves stock of one product between two warehouses.
+// It owns an "out" transaction at the source and, once received, an "in" transaction at the destination.
+type Transfer struct {
+	ID               uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID           *uint      `json:"user_id" gorm:"default:null;index"`
+	ProductID        uint       `json:"product_id" gorm:"not null;index"`
+	FromWarehouseID  uint       `json:"from_warehouse_id" gorm:"not null;index"`
+	ToWarehouseID    uint       `json:"to_warehouse_id" gorm:"not null;index"`
+	Quantity         float64    `json:"quantity" gorm:"type:decimal(20,2);not null"`
+	ReceivedQuantity *float64   `json:"received_quantity" gorm:"type:decimal(20,2);default:null"`
+	Status           string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	ReceivedBy       *uint      `json:"received_by" gorm:"default:null"`
+	ReceivedAt       *time.Time `json:"received_at" gorm:"default:null"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Product       *Product      `json:"product,omitempty" gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	FromWarehouse *Warehouse    `json:"from_warehouse,omitempty" gorm:"foreignKey:FromWarehouseID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	ToWarehouse   *Warehouse    `json:"to_warehouse,omitempty" gorm:"foreignKey:ToWarehouseID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;"`
+	Transactions  []Transaction `json:"transactions,omitempty" gorm:"foreignKey:TransferID"`
+}
+
+// TableName specifies the table name for Transfer model
+func (Transfer) TableName() string {
+	return "transfers"
+}
+
+// TransferResponse represents the transfer data for API responses
+type TransferResponse struct {
+	ID               uint                  `json:"id"`
+	UserID           *uint                 `json:"user_id"`
+	ProductID        uint                  `json:"product_id"`
+	FromWarehouseID  uint                  `json:"from_warehouse_id"`
+	ToWarehouseID    uint                  `json:"to_warehouse_id"`
+	Quantity         float64               `json:"quantity"`
+	ReceivedQuantity *float64              `json:"received_quantity"`
+	Status           string                `json:"status"`
+	ReceivedBy       *uint                 `json:"received_by"`
+	ReceivedAt       *time.Time            `json:"received_at"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+	Product          *ProductResponse      `json:"product,omitempty"`
+	FromWarehouse    *WarehouseResponse    `json:"from_warehouse,omitempty"`
+	ToWarehouse      *WarehouseResponse    `json:"to_warehouse,omitempty"`
+	Transactions     []TransactionResponse `json:"transactions,omitempty"`
+}
+
+// ToResponse converts Transfer to TransferResponse
+func (t *Transfer) ToResponse() TransferResponse {
+	response := TransferResponse{
+		ID:               t.ID,
+		UserID:           t.UserID,
+		ProductID:        t.ProductID,
+		FromWarehouseID:  t.FromWarehouseID,
+		ToWarehouseID:    t.ToWarehouseID,
+		Quantity:         t.Quantity,
+		ReceivedQuantity: t.ReceivedQuantity,
+		Status:           t.Status,
+		ReceivedBy:       t.ReceivedBy,
+		ReceivedAt:       t.ReceivedAt,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+	}
+
+	// Include related models if they are loaded
+	if t.Product != nil {
+		productResponse := t.Product.ToResponse()
+		response.Product = &productResponse
+	}
+	if t.FromWarehouse != nil {
+		warehouseResponse := t.FromWarehouse.ToResponse()
+		response.FromWarehouse = &warehouseResponse
+	}
+	if t.ToWarehouse != nil {
+		warehouseResponse := t.ToWarehouse.ToResponse()
+		response.ToWarehouse = &warehouseResponse
+	}
+	for i := range t.Transactions {
+		response.Transactions = append(response.Transactions, t.Transactions[i].ToResponse())
+	}
+
+	return response
+}
+
+// TransferRequest represents inter-warehouse transfer request
+type TransferRequest struct {
+	ProductID       uint    `json:"product_id" validate:"required"`
+	FromWarehouseID uint    `json:"from_warehouse_id" validate:"required"`
+	ToWarehouseID   uint    `json:"to_warehouse_id" validate:"required,nefield=FromWarehouseID"`
+	Quantity        float64 `json:"quantity" validate:"required,gt=0"`
+	InTransit       bool    `json:"in_transit"` // when true the destination must confirm arrival
+}
+
+// TransferReceiveRequest represents confirmation of an in-transit transfer
+type TransferReceiveRequest struct {
+	Quantity *float64 `json:"quantity" validate:"required,gte=0"`
+}
+
+// TransferListQuery represents transfer list query parameters
+type TransferListQuery struct {
+	WarehouseID uint   `query:"warehouse_id"` // matches source or destination
+	ProductID   uint   `query:"product_id"`
+	Status      string `query:"status" validate:"omitempty,oneof=in_transit completed"`
+	Page        int    `query:"page" validate:"omitempty,min=1"`
+	Limit       int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// Paging returns the normalized paging part of the query
+func (q *TransferListQuery) Paging() *ListQuery {
+	paging := &ListQuery{Page: q.Page, Limit: q.Limit}
+	paging.Normalize()
+	return paging
+}
+
+// TransferListResponse represents paginated transfer list response
+type TransferListResponse struct {
+	Items []TransferResponse `json:"items"`
+	Meta  PaginationMeta     `json:"meta"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// UserTOTP is the TOTP (RFC 6238) enrollment of a user. Two-factor sign-in is enabled once
+// ConfirmedAt is set; until then the secret is only a pending enrollment.
+type UserTOTP struct {
+	UserID      uint       `json:"user_id" gorm:"primaryKey"`
+	Secret      string     `json:"-" gorm:"type:varchar(64);not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	// LastUsedStep is the time step of the last accepted code, so a code cannot be replayed
+	LastUsedStep int64     `json:"-" gorm:"not null;default:0"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for UserTOTP model
+func (UserTOTP) TableName() string {
+	return "user_totp"
+}
+
+// RecoveryCode is a single-use fallback for a lost authenticator. Only its SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for RecoveryCode model
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// TwoFactorEnrollResponse carries the secret to add to an authenticator app
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// TwoFactorCodeRequest carries a TOTP code or, where accepted, a recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse lists recovery codes; they are shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorVerifyRequest completes a sign-in that returned an mfa_pending token
+type TwoFactorVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
@@ -6,13 +6,19 @@ import (
 )
 
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name      string         `json:"name" gorm:"type:varchar(100);not null"`
-	Email     string         `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"type:text;not null"`
-	CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name     string `json:"name" gorm:"type:varchar(100);not null"`
+	Email    string `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Password string `json:"-" gorm:"type:text;not null"`
+	// EmailVerifiedAt is set once the user opens the verification link; nil means unverified
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// TokenVersion is embedded in issued tokens; incrementing it revokes every token of the user
+	TokenVersion uint            `json:"-" gorm:"not null;default:0"`
+	Roles        []Role          `json:"roles,omitempty" gorm:"many2many:user_roles"`
+	Warehouses   []UserWarehouse `json:"warehouses,omitempty" gorm:"foreignKey:UserID"` // limits what non-admin users may see and move
+	CreatedAt    time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 // TableName specifies the table name for User model
@@ -22,24 +28,48 @@ func (User) TableName() string {
 
 // UserResponse represents the user data for API responses (without password)
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Roles           []string   `json:"roles"`
+	WarehouseIDs    []uint     `json:"warehouse_ids"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
 	return UserResponse{
-		ID:        u.ID,
-		Name:      u.Name,
-		Email:     u.Email,
-		CreatedAt: u.CreatedAt,
-		UpdatedAt: u.UpdatedAt,
+		ID:              u.ID,
+		Name:            u.Name,
+		Email:           u.Email,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		Roles:           u.RoleNames(),
+		WarehouseIDs:    u.WarehouseIDs(),
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
 	}
 }
 
+// WarehouseIDs returns the IDs of the loaded warehouse assignments
+func (u *User) WarehouseIDs() []uint {
+	ids := make([]uint, 0, len(u.Warehouses))
+	for _, assignment := range u.Warehouses {
+		ids = append(ids, assignment.WarehouseID)
+	}
+	return ids
+}
+
+// RoleNames returns the names of the loaded roles
+func (u *User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
 // AuthRequest represents login request
 type AuthRequest struct {
 	Email    string `json:"email" validate:"required,email"`
@@ -57,13 +87,15 @@ type RegisterRequest struct {
 type AuthResponse struct {
 	Message      string       `json:"message"`
 	User         UserResponse `json:"user"`
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	TokenType    string       `json:"token_type"`
-	ExpiresIn    int64        `json:"expires_in"`
+	AccessToken  string       `json:"access_token,omitempty"`
+	RefreshToken string       `json:"refresh_token,omitempty"`
+	TokenType    string       `json:"token_type,omitempty"`
+	ExpiresIn    int64        `json:"expires_in,omitempty"` // tokens are left out when the email must be verified before signing in
+	// MFAToken replaces the tokens when the user has two-factor sign-in enabled; exchange it at /auth/2fa/verify
+	MFAToken string `json:"mfa_token,omitempty"`
 }
 
 // RefreshTokenRequest represents refresh token request
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
-}
\ No newline at end of file
+}
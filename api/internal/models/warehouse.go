@@ -20,18 +20,34 @@ func (Warehouse) TableName() string {
 
 // WarehouseResponse represents the warehouse data for API responses
 type WarehouseResponse struct {
-	ID        uint      `json:"id"`
-	Name      *string   `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint       `json:"id"`
+	Name      *string    `json:"name"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // ToResponse converts Warehouse to WarehouseResponse
 func (w *Warehouse) ToResponse() WarehouseResponse {
-	return WarehouseResponse{
+	response := WarehouseResponse{
 		ID:        w.ID,
 		Name:      w.Name,
 		CreatedAt: w.CreatedAt,
 		UpdatedAt: w.UpdatedAt,
 	}
-}
\ No newline at end of file
+	if w.DeletedAt.Valid {
+		response.DeletedAt = &w.DeletedAt.Time
+	}
+	return response
+}
+
+// WarehouseRequest represents create/update warehouse request
+type WarehouseRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+}
+
+// WarehouseListResponse represents paginated warehouse list response
+type WarehouseListResponse struct {
+	Items []WarehouseResponse `json:"items"`
+	Meta  PaginationMeta      `json:"meta"`
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserWarehouse assigns a user to a warehouse they may work in
+type UserWarehouse struct {
+	UserID      uint      `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for UserWarehouse model
+func (UserWarehouse) TableName() string {
+	return "user_warehouses"
+}
+
+// AssignWarehousesRequest replaces every warehouse assignment of a user
+type AssignWarehousesRequest struct {
+	WarehouseIDs []uint `json:"warehouse_ids" validate:"required,dive,min=1"`
+}
+
+// UserWarehousesResponse lists the warehouses a user is assigned to
+type UserWarehousesResponse struct {
+	UserID       uint   `json:"user_id"`
+	WarehouseIDs []uint `json:"warehouse_ids"`
+}
+
+// WarehouseScope is the set of warehouses the caller may access, built by JWTAuth from the token.
+// The zero value allows nothing.
+type WarehouseScope struct {
+	All          bool   // admins are not limited
+	WarehouseIDs []uint // assigned warehouses when All is false
+}
+
+// AllWarehouses returns an unrestricted scope, for admins and internal callers
+func AllWarehouses() WarehouseScope {
+	return WarehouseScope{All: true}
+}
+
+// Allows reports whether the scope includes the warehouse
+func (s WarehouseScope) Allows(warehouseID uint) bool {
+	return s.All || slices.Contains(s.WarehouseIDs, warehouseID)
+}
+
+// AllowsAny reports whether the scope includes at least one of the warehouses
+func (s WarehouseScope) AllowsAny(warehouseIDs ...uint) bool {
+	for _, id := range warehouseIDs {
+		if s.Allows(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductStock returns a GORM scope for product queries, including preloads, that reports
+// Stock as the total over the allowed warehouses. products.stock is the total over every
+// warehouse and would reveal the stock of warehouses outside the scope.
+func (s WarehouseScope) ProductStock() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if s.All {
+			return db
+		}
+		return db.Select("products.id, products.name, products.price, products.image, products.created_at, products.updated_at, products.deleted_at, "+
+			"(SELECT COALESCE(SUM(stock_balances.quantity), 0) FROM stock_balances WHERE stock_balances.product_id = products.id AND stock_balances.warehouse_id IN ?) AS stock",
+			s.WarehouseIDs)
+	}
+}
+
+// Filter returns a GORM scope keeping rows where any of the columns is an allowed warehouse
+func (s WarehouseScope) Filter(columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if s.All {
+			return db
+		}
+		if len(s.WarehouseIDs) == 0 {
+			return db.Where("1 = 0")
+		}
+
+		conditions := make([]string, 0, len(columns))
+		args := make([]interface{}, 0, len(columns))
+		for _, column := range columns {
+			conditions = append(conditions, column+" IN ?")
+			args = append(args, s.WarehouseIDs)
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
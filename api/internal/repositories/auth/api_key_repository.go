@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	// ListByUser returns the keys of the user, newest first
+	ListByUser(ctx context.Context, userID uint) ([]models.APIKey, error)
+	// GetByHash returns the key with the given hash; unknown keys return gorm.ErrRecordNotFound
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	// Delete removes a key of the user and reports whether one was deleted
+	Delete(ctx context.Context, userID, id uint) (bool, error)
+	// TouchLastUsed sets last_used_at unless it is already newer than since, so a busy key is not written on every request
+	TouchLastUsed(ctx context.Context, id uint, usedAt, since time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) Delete(ctx context.Context, userID, id uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt, since time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, since).
+		Update("last_used_at", usedAt).Error
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailVerificationRepository interface {
+	// Create stores a verification token, replacing the unused tokens of the same user so only the latest link works
+	Create(ctx context.Context, token *models.EmailVerificationToken) error
+	// UseToken marks the token with the given hash as used and returns its state from before the call.
+	// Unknown tokens return gorm.ErrRecordNotFound.
+	UseToken(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error)
+	// MarkVerified sets email_verified_at of the user and removes the user's remaining tokens
+	MarkVerified(ctx context.Context, userID uint, verifiedAt time.Time) error
+}
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) EmailVerificationRepository {
+	return &emailVerificationRepository{
+		db: db,
+	}
+}
+
+func (r *emailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("expires_at < ? OR (user_id = ? AND used_at IS NULL)", time.Now(), token.UserID).
+			Delete(&models.EmailVerificationToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *emailVerificationRepository) UseToken(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	var token models.EmailVerificationToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&token).Error
+		if err != nil {
+			return err
+		}
+		if token.UsedAt != nil {
+			return nil
+		}
+		return tx.Model(&models.EmailVerificationToken{}).Where("id = ?", token.ID).Update("used_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *emailVerificationRepository) MarkVerified(ctx context.Context, userID uint, verifiedAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only the first verification counts, a later one must not move the timestamp
+		err := tx.Model(&models.User{}).Where("id = ? AND email_verified_at IS NULL", userID).
+			Update("email_verified_at", verifiedAt).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.EmailVerificationToken{}).Error
+	})
+}
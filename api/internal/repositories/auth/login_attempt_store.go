@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptStore keeps the failed sign-in counters behind the sign-in lockout
+type LoginAttemptStore interface {
+	// Get returns the attempt record of key, or nil when there is none
+	Get(ctx context.Context, key string) (*models.LoginAttempt, error)
+	// RecordFailure counts a failed sign-in at now and returns the updated record.
+	// Failures are counted from zero again when the previous one is older than window.
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	// Lock blocks sign-in for key until the given time
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset forgets the failures and lock of key
+	Reset(ctx context.Context, key string) error
+}
+
+type loginAttemptStore struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptStore returns the database-backed store (login_attempts), shared by every API instance
+func NewLoginAttemptStore(db *gorm.DB) LoginAttemptStore {
+	return &loginAttemptStore{
+		db: db,
+	}
+}
+
+func (s *loginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := s.db.WithContext(ctx).Where("attempt_key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *loginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Records that are out of the window and not locked can never matter again, so clear them while we are here
+		err := tx.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-window), now).
+			Delete(&models.LoginAttempt{}).Error
+		if err != nil {
+			return err
+		}
+
+		// Create the row if needed, then lock it so concurrent failures from other replicas are all counted
+		err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.LoginAttempt{Key: key, LastFailureAt: now}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("attempt_key = ?", key).First(&attempt).Error
+		if err != nil {
+			return err
+		}
+
+		if now.Sub(attempt.LastFailureAt) > window {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		return tx.Model(&models.LoginAttempt{}).Where("attempt_key = ?", key).Updates(map[string]interface{}{
+			"failures":        attempt.Failures,
+			"last_failure_at": attempt.LastFailureAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *loginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.LoginAttempt{}).Where("attempt_key = ?", key).Update("locked_until", until).Error
+}
+
+func (s *loginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("attempt_key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
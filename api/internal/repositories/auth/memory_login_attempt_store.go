@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+// NewMemoryLoginAttemptStore returns a process-local store for development, tests and single-instance setups.
+// Counters are lost on restart and are not shared between instances.
+func NewMemoryLoginAttemptStore() LoginAttemptStore {
+	return &memoryLoginAttemptStore{
+		attempts: map[string]models.LoginAttempt{},
+	}
+}
+
+func (s *memoryLoginAttemptStore) Get(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, attempt := range s.attempts {
+		if now.Sub(attempt.LastFailureAt) > window && (attempt.LockedUntil == nil || attempt.LockedUntil.Before(now)) {
+			delete(s.attempts, k)
+		}
+	}
+
+	attempt := s.attempts[key]
+	if now.Sub(attempt.LastFailureAt) > window {
+		attempt.Failures = 0
+	}
+	attempt.Key = key
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	attempt.UpdatedAt = now
+	s.attempts[key] = attempt
+	return &attempt, nil
+}
+
+func (s *memoryLoginAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil
+	}
+	attempt.LockedUntil = &until
+	s.attempts[key] = attempt
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
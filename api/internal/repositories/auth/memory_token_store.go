@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type memoryTokenStore struct {
+	mu            sync.RWMutex
+	revoked       map[string]time.Time
+	versions      map[uint]uint
+	refreshTokens map[string]models.RefreshToken
+	sessions      map[string]models.Session // by family ID
+	nextSessionID uint
+}
+
+// NewMemoryTokenStore returns a process-local store for development, tests and single-instance setups.
+// Revocations are lost on restart and are not shared between instances.
+func NewMemoryTokenStore() TokenStore {
+	return &memoryTokenStore{
+		revoked:       map[string]time.Time{},
+		versions:      map[uint]uint{},
+		refreshTokens: map[string]models.RefreshToken{},
+		sessions:      map[string]models.Session{},
+	}
+}
+
+func (s *memoryTokenStore) RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+func (s *memoryTokenStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.revoked[jti]
+	return ok, nil
+}
+
+func (s *memoryTokenStore) TokenVersion(ctx context.Context, userID uint) (uint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.versions[userID], nil
+}
+
+func (s *memoryTokenStore) IncrementTokenVersion(ctx context.Context, userID uint) (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.versions[userID]++
+	s.revokeSessions(func(session models.Session) bool { return session.UserID == userID }, time.Now())
+	return s.versions[userID], nil
+}
+
+func (s *memoryTokenStore) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token.CreatedAt = time.Now()
+	s.refreshTokens[token.JTI] = *token
+	return nil
+}
+
+func (s *memoryTokenStore) UseRefreshToken(ctx context.Context, jti string) (*models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[jti]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if token.UsedAt == nil && token.RevokedAt == nil {
+		usedAt := time.Now()
+		record := token
+		record.UsedAt = &usedAt
+		s.refreshTokens[jti] = record
+	}
+	return &token, nil
+}
+
+func (s *memoryTokenStore) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revokeFamily(familyID, time.Now())
+	return nil
+}
+
+func (s *memoryTokenStore) CreateSession(ctx context.Context, session *models.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextSessionID++
+	session.ID = s.nextSessionID
+	session.CreatedAt = time.Now()
+	s.sessions[session.FamilyID] = *session
+	return nil
+}
+
+func (s *memoryTokenStore) TouchSession(ctx context.Context, familyID string, client models.ClientInfo, usedAt, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[familyID]
+	if !ok {
+		return nil
+	}
+	session.IPAddress = client.IPAddress
+	session.UserAgent = client.UserAgent
+	session.LastUsedAt = usedAt
+	session.ExpiresAt = expiresAt
+	s.sessions[familyID] = session
+	return nil
+}
+
+func (s *memoryTokenStore) ListSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	sessions := []models.Session{}
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b models.Session) int {
+		return b.LastUsedAt.Compare(a.LastUsedAt)
+	})
+	return sessions, nil
+}
+
+func (s *memoryTokenStore) RevokeSession(ctx context.Context, userID, id uint) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for familyID, session := range s.sessions {
+		if session.ID == id && session.UserID == userID && session.RevokedAt == nil {
+			s.revokeFamily(familyID, time.Now())
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryTokenStore) IsSessionRevoked(ctx context.Context, familyID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[familyID]
+	return ok && session.RevokedAt != nil, nil
+}
+
+// revokeFamily revokes the refresh tokens and the session of a token family; the caller holds mu
+func (s *memoryTokenStore) PurgeExpired(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for jti, expiresAt := range s.revoked {
+		if expiresAt.Before(now) {
+			delete(s.revoked, jti)
+		}
+	}
+	for jti, token := range s.refreshTokens {
+		if token.ExpiresAt.Before(now) {
+			delete(s.refreshTokens, jti)
+		}
+	}
+	for familyID, session := range s.sessions {
+		if session.ExpiresAt.Before(now) {
+			delete(s.sessions, familyID)
+		}
+	}
+	return nil
+}
+
+func (s *memoryTokenStore) revokeFamily(familyID string, revokedAt time.Time) {
+	for jti, token := range s.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &revokedAt
+			s.refreshTokens[jti] = token
+		}
+	}
+	s.revokeSessions(func(session models.Session) bool { return session.FamilyID == familyID }, revokedAt)
+}
+
+// revokeSessions revokes the unrevoked sessions that match; the caller holds mu
+func (s *memoryTokenStore) revokeSessions(match func(models.Session) bool, revokedAt time.Time) {
+	for familyID, session := range s.sessions {
+		if match(session) && session.RevokedAt == nil {
+			session.RevokedAt = &revokedAt
+			s.sessions[familyID] = session
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OIDCRepository interface {
+	// SaveState stores a started sign-in and removes expired ones
+	SaveState(ctx context.Context, state *models.OIDCState) error
+	// TakeState deletes the state with the given hash and returns it, so each state is used once.
+	// Unknown or already used states return gorm.ErrRecordNotFound.
+	TakeState(ctx context.Context, stateHash string) (*models.OIDCState, error)
+	// GetIdentity returns the link of a provider account; unknown accounts return gorm.ErrRecordNotFound
+	GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+}
+
+type oidcRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) OIDCRepository {
+	return &oidcRepository{
+		db: db,
+	}
+}
+
+func (r *oidcRepository) SaveState(ctx context.Context, state *models.OIDCState) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&models.OIDCState{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(state).Error
+	})
+}
+
+func (r *oidcRepository) TakeState(ctx context.Context, stateHash string) (*models.OIDCState, error) {
+	var state models.OIDCState
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The row lock lets only one of two concurrent callbacks with the same state succeed
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("state_hash = ?", stateHash).First(&state).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.OIDCState{}, state.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *oidcRepository) GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.WithContext(ctx).Where("issuer = ? AND subject = ?", issuer, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *oidcRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PasswordResetRepository interface {
+	// Create stores a reset token, replacing the unused tokens of the same user so only the latest link works
+	Create(ctx context.Context, token *models.PasswordResetToken) error
+	// UseToken marks the token with the given hash as used and returns its state from before the call.
+	// Unknown tokens return gorm.ErrRecordNotFound.
+	UseToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	// DeleteUserTokens removes every reset token of the user
+	DeleteUserTokens(ctx context.Context, userID uint) error
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{
+		db: db,
+	}
+}
+
+func (r *passwordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("expires_at < ? OR (user_id = ? AND used_at IS NULL)", time.Now(), token.UserID).
+			Delete(&models.PasswordResetToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *passwordResetRepository) UseToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The row lock lets only one of two concurrent resets with the same token succeed
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", tokenHash).First(&token).Error
+		if err != nil {
+			return err
+		}
+		if token.UsedAt != nil {
+			return nil
+		}
+		return tx.Model(&models.PasswordResetToken{}).Where("id = ?", token.ID).Update("used_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetRepository) DeleteUserTokens(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.PasswordResetToken{}).Error
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	// List returns every role with its permissions
+	List(ctx context.Context) ([]models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	GetByNames(ctx context.Context, names []string) ([]models.Role, error)
+	GetUserRoles(ctx context.Context, userID uint) ([]models.Role, error)
+	// ReplaceUserRoles makes roles the only roles of the user
+	ReplaceUserRoles(ctx context.Context, userID uint, roles []models.Role) error
+	// CountUsersWithRole counts active users holding the role
+	CountUsersWithRole(ctx context.Context, name string) (int64, error)
+	// RolePermissions maps every role name to the names of its permissions
+	RolePermissions(ctx context.Context) (map[string][]string, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("permissions.name")
+	}).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) GetByNames(ctx context.Context, names []string) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Where("name IN ?", names).Order("id").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) GetUserRoles(ctx context.Context, userID uint) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.id").
+		Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) ReplaceUserRoles(ctx context.Context, userID uint, roles []models.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Model(&models.User{ID: userID}).Association("Roles").Replace(roles)
+	})
+}
+
+func (r *roleRepository) CountUsersWithRole(ctx context.Context, name string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ?", name).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *roleRepository) RolePermissions(ctx context.Context) (map[string][]string, error) {
+	var rows []struct {
+		RoleName       string
+		PermissionName string
+	}
+	err := r.db.WithContext(ctx).Table("role_permissions").
+		Select("roles.name AS role_name, permissions.name AS permission_name").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string][]string)
+	for _, row := range rows {
+		permissions[row.RoleName] = append(permissions[row.RoleName], row.PermissionName)
+	}
+	return permissions, nil
+}
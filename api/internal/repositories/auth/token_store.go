@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenStore keeps the revocation state checked on every token validation.
+// Single tokens are revoked by jti; all tokens of a user by bumping the user's token version.
+// Refresh tokens additionally have a record per issued token, grouped into rotation families,
+// and each family has a session record that the user can list and revoke.
+type TokenStore interface {
+	// RevokeToken blocks jti until expiresAt, after which the token is expired anyway
+	RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	TokenVersion(ctx context.Context, userID uint) (uint, error)
+	// IncrementTokenVersion invalidates every token issued with an older version and returns the new one
+	IncrementTokenVersion(ctx context.Context, userID uint) (uint, error)
+	// SaveRefreshToken records a newly issued refresh token
+	SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	// UseRefreshToken marks an unused, unrevoked refresh token as used and returns the record as it
+	// was before the call: a non-nil UsedAt or RevokedAt means the token was not consumed.
+	// Unknown tokens return gorm.ErrRecordNotFound.
+	UseRefreshToken(ctx context.Context, jti string) (*models.RefreshToken, error)
+	// RevokeTokenFamily revokes every refresh token rotated from the same sign-in, and its session
+	RevokeTokenFamily(ctx context.Context, familyID string) error
+	// CreateSession records the session of a new refresh token family
+	CreateSession(ctx context.Context, session *models.Session) error
+	// TouchSession records a refresh of the session: the client, when it happened and the new expiry
+	TouchSession(ctx context.Context, familyID string, client models.ClientInfo, usedAt, expiresAt time.Time) error
+	// ListSessions returns the unrevoked, unexpired sessions of the user, most recently used first
+	ListSessions(ctx context.Context, userID uint) ([]models.Session, error)
+	// RevokeSession revokes a session of the user and its refresh tokens and reports whether one was revoked
+	RevokeSession(ctx context.Context, userID, id uint) (bool, error)
+	// IsSessionRevoked reports whether the session of a token family was revoked; unknown families are not
+	IsSessionRevoked(ctx context.Context, familyID string) (bool, error)
+	// PurgeExpired deletes revocations, refresh tokens and sessions past their expiry. It runs on a timer
+	// (see TokenPurgeService), so the writes above never pay for the cleanup.
+	PurgeExpired(ctx context.Context) error
+}
+
+type tokenStore struct {
+	db *gorm.DB
+}
+
+// NewTokenStore returns the database-backed store (revoked_tokens and users.token_version)
+func NewTokenStore(db *gorm.DB) TokenStore {
+	return &tokenStore{
+		db: db,
+	}
+}
+
+func (s *tokenStore) RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) error {
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+func (s *tokenStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (s *tokenStore) TokenVersion(ctx context.Context, userID uint) (uint, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Select("token_version").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+func (s *tokenStore) IncrementTokenVersion(ctx context.Context, userID uint) (uint, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", userID).
+			UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+		if err != nil {
+			return err
+		}
+		// The sessions' tokens no longer match the version, so stop listing them
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return s.TokenVersion(ctx, userID)
+}
+
+func (s *tokenStore) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
+	return s.db.WithContext(ctx).Create(token).Error
+}
+
+func (s *tokenStore) UseRefreshToken(ctx context.Context, jti string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The row lock makes two concurrent refreshes with the same token look like reuse
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("jti = ?", jti).First(&token).Error
+		if err != nil {
+			return err
+		}
+		if token.UsedAt != nil || token.RevokedAt != nil {
+			return nil
+		}
+		return tx.Model(&models.RefreshToken{}).Where("jti = ?", jti).Update("used_at", time.Now()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *tokenStore) RevokeTokenFamily(ctx context.Context, familyID string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return revokeFamily(tx, familyID, time.Now())
+	})
+}
+
+func (s *tokenStore) CreateSession(ctx context.Context, session *models.Session) error {
+	return s.db.WithContext(ctx).Create(session).Error
+}
+
+func (s *tokenStore) TouchSession(ctx context.Context, familyID string, client models.ClientInfo, usedAt, expiresAt time.Time) error {
+	return s.db.WithContext(ctx).Model(&models.Session{}).Where("family_id = ?", familyID).Updates(map[string]interface{}{
+		"ip_address":   client.IPAddress,
+		"user_agent":   client.UserAgent,
+		"last_used_at": usedAt,
+		"expires_at":   expiresAt,
+	}).Error
+}
+
+func (s *tokenStore) ListSessions(ctx context.Context, userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := s.db.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (s *tokenStore) RevokeSession(ctx context.Context, userID, id uint) (bool, error) {
+	revoked := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var session models.Session
+		err := tx.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).First(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		revoked = true
+		return revokeFamily(tx, session.FamilyID, time.Now())
+	})
+	return revoked, err
+}
+
+func (s *tokenStore) IsSessionRevoked(ctx context.Context, familyID string) (bool, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.Session{}).Where("family_id = ? AND revoked_at IS NOT NULL", familyID).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// purgeBatchSize bounds each DELETE so the purge never holds locks on a large range of rows
+const purgeBatchSize = 1000
+
+func (s *tokenStore) PurgeExpired(ctx context.Context) error {
+	now := time.Now()
+	// Expired revocations can never match a valid token again, expired refresh tokens fail
+	// signature validation before their record is looked up, and expired sessions cannot be
+	// refreshed or listed any more
+	for _, model := range []interface{}{&models.RevokedToken{}, &models.RefreshToken{}, &models.Session{}} {
+		for {
+			result := s.db.WithContext(ctx).Where("expires_at < ?", now).Limit(purgeBatchSize).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected < purgeBatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// revokeFamily revokes the refresh tokens and the session of a token family
+func revokeFamily(tx *gorm.DB, familyID string, revokedAt time.Time) error {
+	err := tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.Session{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", revokedAt).Error
+}
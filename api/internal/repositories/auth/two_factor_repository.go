@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository interface {
+	// GetByUserID returns the TOTP enrollment of the user, confirmed or pending
+	GetByUserID(ctx context.Context, userID uint) (*models.UserTOTP, error)
+	// SavePending replaces any enrollment of the user with a new, unconfirmed one
+	SavePending(ctx context.Context, totp *models.UserTOTP) error
+	// Confirm enables the enrollment and replaces the user's recovery codes
+	Confirm(ctx context.Context, userID uint, confirmedAt time.Time, codes []models.RecoveryCode) error
+	// MarkStepUsed records step as the last accepted time step. It reports false when
+	// step is not newer than the stored one, meaning the code was already used.
+	MarkStepUsed(ctx context.Context, userID uint, step int64) (bool, error)
+	// UseRecoveryCode marks an unused recovery code as used and reports whether one matched
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error)
+	// Delete removes the enrollment and recovery codes of the user
+	Delete(ctx context.Context, userID uint) error
+}
+
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{
+		db: db,
+	}
+}
+
+func (r *twoFactorRepository) GetByUserID(ctx context.Context, userID uint) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&totp).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+func (r *twoFactorRepository) SavePending(ctx context.Context, totp *models.UserTOTP) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", totp.UserID).Delete(&models.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(totp).Error
+	})
+}
+
+func (r *twoFactorRepository) Confirm(ctx context.Context, userID uint, confirmedAt time.Time, codes []models.RecoveryCode) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserTOTP{}).Where("user_id = ?", userID).Update("confirmed_at", confirmedAt).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *twoFactorRepository) MarkStepUsed(ctx context.Context, userID uint, step int64) (bool, error) {
+	// The conditional update lets only one of two concurrent uses of the same code succeed
+	result := r.db.WithContext(ctx).Model(&models.UserTOTP{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *twoFactorRepository) Delete(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error
+	})
+}
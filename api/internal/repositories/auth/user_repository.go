@@ -2,16 +2,17 @@ package auth
 
 import (
 	"api/internal/models"
+	"context"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
-	Create(user *models.User) error
-	GetByEmail(email string) (*models.User, error)
-	GetByID(id uint) (*models.User, error)
-	Update(user *models.User) error
-	Delete(id uint) error
-	EmailExists(email string) (bool, error)
+	Create(ctx context.Context, user *models.User) error
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uint) error
+	EmailExists(ctx context.Context, email string) (bool, error)
 }
 
 type userRepository struct {
@@ -24,39 +25,41 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	}
 }
 
-func (r *userRepository) Create(user *models.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
 }
 
-func (r *userRepository) GetByEmail(email string) (*models.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Preload("Roles").Preload("Warehouses").Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetByID(id uint) (*models.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("id = ?", id).First(&user).Error
+	err := r.db.WithContext(ctx).Preload("Roles").Preload("Warehouses").Where("id = ?", id).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) Update(user *models.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	// token_version only moves through the token store, a stale copy must not roll it back;
+	// roles and warehouses only change through their own repositories
+	return r.db.WithContext(ctx).Omit("token_version", "Roles", "Warehouses").Save(user).Error
 }
 
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&models.User{}, id).Error
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.User{}, id).Error
 }
 
-func (r *userRepository) EmailExists(email string) (bool, error) {
+func (r *userRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("email = ?", email).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
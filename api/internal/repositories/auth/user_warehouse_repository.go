@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"api/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type UserWarehouseRepository interface {
+	GetUserWarehouseIDs(ctx context.Context, userID uint) ([]uint, error)
+	// ReplaceUserWarehouses makes warehouseIDs the only warehouses of the user
+	ReplaceUserWarehouses(ctx context.Context, userID uint, warehouseIDs []uint) error
+	// ExistingWarehouseIDs returns which of the given warehouses exist and are not deleted
+	ExistingWarehouseIDs(ctx context.Context, warehouseIDs []uint) ([]uint, error)
+}
+
+type userWarehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewUserWarehouseRepository(db *gorm.DB) UserWarehouseRepository {
+	return &userWarehouseRepository{
+		db: db,
+	}
+}
+
+func (r *userWarehouseRepository) GetUserWarehouseIDs(ctx context.Context, userID uint) ([]uint, error) {
+	ids := []uint{}
+	err := r.db.WithContext(ctx).Model(&models.UserWarehouse{}).
+		Where("user_id = ?", userID).
+		Order("warehouse_id").
+		Pluck("warehouse_id", &ids).Error
+	return ids, err
+}
+
+func (r *userWarehouseRepository) ReplaceUserWarehouses(ctx context.Context, userID uint, warehouseIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserWarehouse{}).Error; err != nil {
+			return err
+		}
+		if len(warehouseIDs) == 0 {
+			return nil
+		}
+
+		assignments := make([]models.UserWarehouse, 0, len(warehouseIDs))
+		for _, warehouseID := range warehouseIDs {
+			assignments = append(assignments, models.UserWarehouse{UserID: userID, WarehouseID: warehouseID})
+		}
+		return tx.Create(&assignments).Error
+	})
+}
+
+func (r *userWarehouseRepository) ExistingWarehouseIDs(ctx context.Context, warehouseIDs []uint) ([]uint, error) {
+	ids := []uint{}
+	if len(warehouseIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.Warehouse{}).Where("id IN ?", warehouseIDs).Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,123 @@
+package master
+
+import (
+	"api/internal/models"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductRepository interface {
+	// List returns the products visible in the warehouse scope, see stockedIn
+	List(ctx context.Context, scope models.WarehouseScope, query *models.ListQuery) ([]models.Product, int64, error)
+	// GetByID returns a non-deleted product with its stock in the warehouse scope
+	GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Product, error)
+	GetByIDWithTrashed(ctx context.Context, id uint) (*models.Product, error)
+	Create(ctx context.Context, product *models.Product) error
+	Update(ctx context.Context, product *models.Product) error
+	// Delete soft deletes the product and clears its image in the same update, so a restored
+	// product never points at the image file removed on delete
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) error
+	// StockWarehouseIDs returns the warehouses holding a stock balance of the product
+	StockWarehouseIDs(ctx context.Context, id uint) ([]uint, error)
+}
+
+type productRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{
+		db: db,
+	}
+}
+
+func (r *productRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.ListQuery) ([]models.Product, int64, error) {
+	var products []models.Product
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.Product{}).Scopes(stockedIn(scope))
+	switch query.Trashed {
+	case "with":
+		db = db.Unscoped()
+	case "only":
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if query.Search != "" {
+		db = db.Where("name LIKE ?", "%"+query.Search+"%")
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Scopes(scope.ProductStock()).Order("id DESC").Offset(query.Offset()).Limit(query.Limit).Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return products, total, nil
+}
+
+func (r *productRepository) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.WithContext(ctx).Scopes(scope.ProductStock()).Where("id = ?", id).First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productRepository) GetByIDWithTrashed(ctx context.Context, id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
+	return r.db.WithContext(ctx).Create(product).Error
+}
+
+func (r *productRepository) Update(ctx context.Context, product *models.Product) error {
+	// Stock is derived from warehouse balances and only written by transaction postings
+	return r.db.WithContext(ctx).Omit("stock").Save(product).Error
+}
+
+func (r *productRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Model(&models.Product{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"image": nil, "deleted_at": time.Now()}).Error
+}
+
+func (r *productRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Product{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+func (r *productRepository) StockWarehouseIDs(ctx context.Context, id uint) ([]uint, error) {
+	ids := []uint{}
+	err := r.db.WithContext(ctx).Model(&models.StockBalance{}).
+		Where("product_id = ?", id).
+		Distinct().
+		Order("warehouse_id").
+		Pluck("warehouse_id", &ids).Error
+	return ids, err
+}
+
+// stockedIn limits products to those with a stock balance in an allowed warehouse.
+// Products without any balance yet belong to no warehouse and stay visible, so they can be stocked.
+func stockedIn(scope models.WarehouseScope) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if scope.All {
+			return db
+		}
+		unstocked := "NOT EXISTS (SELECT 1 FROM stock_balances WHERE stock_balances.product_id = products.id)"
+		if len(scope.WarehouseIDs) == 0 {
+			return db.Where(unstocked)
+		}
+		return db.Where("(EXISTS (SELECT 1 FROM stock_balances WHERE stock_balances.product_id = products.id AND stock_balances.warehouse_id IN ?) OR "+unstocked+")", scope.WarehouseIDs)
+	}
+}
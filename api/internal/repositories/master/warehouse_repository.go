@@ -0,0 +1,88 @@
+package master
+
+import (
+	"api/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type WarehouseRepository interface {
+	List(ctx context.Context, query *models.ListQuery) ([]models.Warehouse, int64, error)
+	GetByID(ctx context.Context, id uint) (*models.Warehouse, error)
+	GetByIDWithTrashed(ctx context.Context, id uint) (*models.Warehouse, error)
+	Create(ctx context.Context, warehouse *models.Warehouse) error
+	Update(ctx context.Context, warehouse *models.Warehouse) error
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) error
+}
+
+type warehouseRepository struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepository(db *gorm.DB) WarehouseRepository {
+	return &warehouseRepository{
+		db: db,
+	}
+}
+
+func (r *warehouseRepository) List(ctx context.Context, query *models.ListQuery) ([]models.Warehouse, int64, error) {
+	var warehouses []models.Warehouse
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.Warehouse{})
+	switch query.Trashed {
+	case "with":
+		db = db.Unscoped()
+	case "only":
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if query.Search != "" {
+		db = db.Where("name LIKE ?", "%"+query.Search+"%")
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Order("id DESC").Offset(query.Offset()).Limit(query.Limit).Find(&warehouses).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return warehouses, total, nil
+}
+
+func (r *warehouseRepository) GetByID(ctx context.Context, id uint) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&warehouse).Error
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *warehouseRepository) GetByIDWithTrashed(ctx context.Context, id uint) (*models.Warehouse, error) {
+	var warehouse models.Warehouse
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ?", id).First(&warehouse).Error
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+func (r *warehouseRepository) Create(ctx context.Context, warehouse *models.Warehouse) error {
+	return r.db.WithContext(ctx).Create(warehouse).Error
+}
+
+func (r *warehouseRepository) Update(ctx context.Context, warehouse *models.Warehouse) error {
+	return r.db.WithContext(ctx).Save(warehouse).Error
+}
+
+func (r *warehouseRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Warehouse{}, id).Error
+}
+
+func (r *warehouseRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Warehouse{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
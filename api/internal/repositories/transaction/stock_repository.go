@@ -0,0 +1,105 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockRepository interface {
+	List(ctx context.Context, scope models.WarehouseScope, query *models.StockQuery, paging *models.ListQuery) ([]models.StockBalance, int64, error)
+	All(ctx context.Context) ([]models.StockBalance, error)
+	// LedgerTotals sums non-deleted transactions per (product, warehouse)
+	LedgerTotals(ctx context.Context) ([]models.StockBalance, error)
+
+	// LockProducts takes a row lock on every product (SELECT ... FOR UPDATE), in id order
+	LockProducts(ctx context.Context) error
+
+	// Atomic runs fn inside a database transaction; the repository passed to fn is bound to it
+	Atomic(ctx context.Context, fn func(repo StockRepository) error) error
+	ReplaceAll(ctx context.Context, balances []models.StockBalance) error
+	SyncAllProductStock(ctx context.Context) error
+}
+
+type stockRepository struct {
+	db *gorm.DB
+}
+
+func NewStockRepository(db *gorm.DB) StockRepository {
+	return &stockRepository{
+		db: db,
+	}
+}
+
+func (r *stockRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.StockQuery, paging *models.ListQuery) ([]models.StockBalance, int64, error) {
+	var balances []models.StockBalance
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.StockBalance{}).Scopes(scope.Filter("warehouse_id"))
+	if query.WarehouseID != 0 {
+		db = db.Where("warehouse_id = ?", query.WarehouseID)
+	}
+	if query.ProductID != 0 {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("Product", scope.ProductStock()).Preload("Warehouse").
+		Order("product_id ASC, warehouse_id ASC").
+		Offset(paging.Offset()).Limit(paging.Limit).
+		Find(&balances).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return balances, total, nil
+}
+
+func (r *stockRepository) All(ctx context.Context) ([]models.StockBalance, error) {
+	var balances []models.StockBalance
+	err := r.db.WithContext(ctx).Order("product_id ASC, warehouse_id ASC").Find(&balances).Error
+	return balances, err
+}
+
+func (r *stockRepository) LedgerTotals(ctx context.Context) ([]models.StockBalance, error) {
+	var totals []models.StockBalance
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("product_id, warehouse_id, SUM(CASE WHEN type = ? THEN quantity ELSE -quantity END) AS quantity", models.TransactionTypeIn).
+		Where("product_id IS NOT NULL AND warehouse_id IS NOT NULL").
+		Group("product_id, warehouse_id").
+		Order("product_id ASC, warehouse_id ASC").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *stockRepository) LockProducts(ctx context.Context) error {
+	var ids []uint
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Product{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Order("id ASC").
+		Pluck("id", &ids).Error
+}
+
+func (r *stockRepository) Atomic(ctx context.Context, fn func(repo StockRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&stockRepository{db: tx})
+	})
+}
+
+func (r *stockRepository) ReplaceAll(ctx context.Context, balances []models.StockBalance) error {
+	if err := r.db.WithContext(ctx).Where("1 = 1").Delete(&models.StockBalance{}).Error; err != nil {
+		return err
+	}
+	if len(balances) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(balances, 500).Error
+}
+
+func (r *stockRepository) SyncAllProductStock(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec("UPDATE products SET stock = COALESCE((SELECT SUM(stock_balances.quantity) FROM stock_balances WHERE stock_balances.product_id = products.id), 0)").Error
+}
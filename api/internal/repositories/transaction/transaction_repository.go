@@ -0,0 +1,163 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransactionRepository interface {
+	List(ctx context.Context, scope models.WarehouseScope, query *models.TransactionListQuery) ([]models.Transaction, int64, error)
+	// GetByID returns a transaction with its product's stock in the warehouse scope
+	GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transaction, error)
+	Create(ctx context.Context, transaction *models.Transaction) error
+	Delete(ctx context.Context, id uint) error
+	HasReversal(ctx context.Context, id uint) (bool, error)
+	WarehouseExists(ctx context.Context, id uint) (bool, error)
+
+	// Atomic runs fn inside a database transaction; the repository passed to fn is bound to it
+	Atomic(ctx context.Context, fn func(repo TransactionRepository) error) error
+	// LockByID loads a transaction with a row lock (SELECT ... FOR UPDATE)
+	LockByID(ctx context.Context, id uint) (*models.Transaction, error)
+	// LockProduct loads a product with a row lock (SELECT ... FOR UPDATE)
+	LockProduct(ctx context.Context, id uint) (*models.Product, error)
+	// LockBalance loads the (product, warehouse) stock balance with a row lock, starting at zero when missing
+	LockBalance(ctx context.Context, productID, warehouseID uint) (*models.StockBalance, error)
+	SaveBalance(ctx context.Context, balance *models.StockBalance) error
+	// SyncProductStock sets Product.Stock to the total of its warehouse balances
+	SyncProductStock(ctx context.Context, productID uint) error
+}
+
+type transactionRepository struct {
+	db *gorm.DB
+}
+
+func NewTransactionRepository(db *gorm.DB) TransactionRepository {
+	return &transactionRepository{
+		db: db,
+	}
+}
+
+func (r *transactionRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.TransactionListQuery) ([]models.Transaction, int64, error) {
+	var transactions []models.Transaction
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.Transaction{}).Scopes(scope.Filter("transactions.warehouse_id"))
+	switch query.Trashed {
+	case "with":
+		db = db.Unscoped()
+	case "only":
+		db = db.Unscoped().Where("transactions.deleted_at IS NOT NULL")
+	}
+	if query.WarehouseID != 0 {
+		db = db.Where("transactions.warehouse_id = ?", query.WarehouseID)
+	}
+	if query.ProductID != 0 {
+		db = db.Where("transactions.product_id = ?", query.ProductID)
+	}
+	if query.Type != "" {
+		db = db.Where("transactions.type = ?", query.Type)
+	}
+	if query.Search != "" {
+		db = db.Joins("LEFT JOIN products ON products.id = transactions.product_id").
+			Where("products.name LIKE ?", "%"+query.Search+"%")
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("User").Preload("Warehouse").Preload("Product", scope.ProductStock()).
+		Order("transactions.id DESC").
+		Offset(query.Offset()).Limit(query.Limit).
+		Find(&transactions).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+func (r *transactionRepository) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(ctx).Preload("User").Preload("Warehouse").Preload("Product", scope.ProductStock()).
+		Where("id = ?", id).First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	return r.db.WithContext(ctx).Create(transaction).Error
+}
+
+func (r *transactionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Transaction{}, id).Error
+}
+
+func (r *transactionRepository) HasReversal(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).Where("reversal_of = ?", id).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *transactionRepository) WarehouseExists(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Warehouse{}).Where("id = ?", id).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *transactionRepository) Atomic(ctx context.Context, fn func(repo TransactionRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&transactionRepository{db: tx})
+	})
+}
+
+func (r *transactionRepository) LockByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) LockProduct(ctx context.Context, id uint) (*models.Product, error) {
+	var product models.Product
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *transactionRepository) LockBalance(ctx context.Context, productID, warehouseID uint) (*models.StockBalance, error) {
+	var balance models.StockBalance
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("product_id = ? AND warehouse_id = ?", productID, warehouseID).
+		First(&balance).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.StockBalance{ProductID: productID, WarehouseID: warehouseID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+func (r *transactionRepository) SaveBalance(ctx context.Context, balance *models.StockBalance) error {
+	return r.db.WithContext(ctx).Save(balance).Error
+}
+
+func (r *transactionRepository) SyncProductStock(ctx context.Context, productID uint) error {
+	return r.db.WithContext(ctx).Exec("UPDATE products SET stock = COALESCE((SELECT SUM(quantity) FROM stock_balances WHERE product_id = ?), 0) WHERE id = ?", productID, productID).Error
+}
@@ -0,0 +1,101 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferRepository interface {
+	// List returns the transfers leaving or entering the scope's warehouses
+	List(ctx context.Context, scope models.WarehouseScope, query *models.TransferListQuery, paging *models.ListQuery) ([]models.Transfer, int64, error)
+	// GetByID returns a transfer with its product's stock in the warehouse scope
+	GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transfer, error)
+	Create(ctx context.Context, transfer *models.Transfer) error
+	Update(ctx context.Context, transfer *models.Transfer) error
+
+	// Atomic runs fn inside a database transaction; the repository passed to fn is bound to it
+	Atomic(ctx context.Context, fn func(repo TransferRepository) error) error
+	// LockByID loads a transfer with a row lock (SELECT ... FOR UPDATE)
+	LockByID(ctx context.Context, id uint) (*models.Transfer, error)
+	// Movements returns a transaction repository bound to the same database session
+	Movements() TransactionRepository
+}
+
+type transferRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{
+		db: db,
+	}
+}
+
+func (r *transferRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.TransferListQuery, paging *models.ListQuery) ([]models.Transfer, int64, error) {
+	var transfers []models.Transfer
+	var total int64
+
+	db := r.db.WithContext(ctx).Model(&models.Transfer{}).Scopes(scope.Filter("from_warehouse_id", "to_warehouse_id"))
+	if query.WarehouseID != 0 {
+		db = db.Where("from_warehouse_id = ? OR to_warehouse_id = ?", query.WarehouseID, query.WarehouseID)
+	}
+	if query.ProductID != 0 {
+		db = db.Where("product_id = ?", query.ProductID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := db.Preload("Product", scope.ProductStock()).Preload("FromWarehouse").Preload("ToWarehouse").
+		Order("id DESC").
+		Offset(paging.Offset()).Limit(paging.Limit).
+		Find(&transfers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return transfers, total, nil
+}
+
+func (r *transferRepository) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.WithContext(ctx).Preload("Product", scope.ProductStock()).Preload("FromWarehouse").Preload("ToWarehouse").Preload("Transactions").
+		Where("id = ?", id).First(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) Create(ctx context.Context, transfer *models.Transfer) error {
+	return r.db.WithContext(ctx).Create(transfer).Error
+}
+
+func (r *transferRepository) Update(ctx context.Context, transfer *models.Transfer) error {
+	return r.db.WithContext(ctx).Save(transfer).Error
+}
+
+func (r *transferRepository) Atomic(ctx context.Context, fn func(repo TransferRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&transferRepository{db: tx})
+	})
+}
+
+func (r *transferRepository) LockByID(ctx context.Context, id uint) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&transfer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) Movements() TransactionRepository {
+	return &transactionRepository{db: r.db}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import (
+	authHandlers "api/internal/handlers/auth"
+	"api/internal/middlewares"
+	"api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupAdminRoutes(app *fiber.App, roleHandler *authHandlers.RoleHandler, userWarehouseHandler *authHandlers.UserWarehouseHandler, loginAttemptHandler *authHandlers.LoginAttemptHandler, jwtMiddleware *middlewares.JWTMiddleware) {
+	// Create admin group (user management permission required)
+	admin := app.Group("/api/v1/admin", jwtMiddleware.JWTAuth(), middlewares.RequirePermission(models.PermissionUsersManage))
+
+	// Role routes
+	admin.Get("/roles", roleHandler.ListRoles)
+	admin.Get("/users/:id/roles", roleHandler.GetUserRoles)
+	admin.Put("/users/:id/roles", roleHandler.AssignRoles)
+
+	// Warehouse assignment routes
+	admin.Get("/users/:id/warehouses", userWarehouseHandler.GetUserWarehouses)
+	admin.Put("/users/:id/warehouses", userWarehouseHandler.AssignWarehouses)
+
+	// Sign-in lockout routes
+	admin.Post("/users/:id/unlock", loginAttemptHandler.Unlock)
+}
@@ -7,7 +7,10 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func SetupAuthRoutes(app *fiber.App, authHandler *authHandlers.AuthHandler, jwtMiddleware *middlewares.JWTMiddleware) {
+func SetupAuthRoutes(app *fiber.App, authHandler *authHandlers.AuthHandler, passwordResetHandler *authHandlers.PasswordResetHandler, emailVerificationHandler *authHandlers.EmailVerificationHandler, twoFactorHandler *authHandlers.TwoFactorHandler, jwksHandler *authHandlers.JWKSHandler, apiKeyHandler *authHandlers.APIKeyHandler, sessionHandler *authHandlers.SessionHandler, oidcHandler *authHandlers.OIDCHandler, jwtMiddleware *middlewares.JWTMiddleware) {
+	// Public keys for services that verify our tokens
+	app.Get("/.well-known/jwks.json", jwksHandler.JWKS)
+
 	// Create auth group
 	auth := app.Group("/api/v1/auth")
 
@@ -15,8 +18,26 @@ func SetupAuthRoutes(app *fiber.App, authHandler *authHandlers.AuthHandler, jwtM
 	auth.Post("/signin", authHandler.SignIn)
 	auth.Post("/signup", authHandler.SignUp)
 	auth.Post("/refresh-token", authHandler.RefreshToken)
+	auth.Post("/forgot-password", passwordResetHandler.ForgotPassword)
+	auth.Post("/reset-password", passwordResetHandler.ResetPassword)
+	auth.Post("/verify-email", emailVerificationHandler.VerifyEmail)
+	auth.Post("/resend-verification", emailVerificationHandler.ResendVerification)
+	auth.Post("/2fa/verify", twoFactorHandler.Verify)
+	auth.Get("/oidc/authorize", oidcHandler.Authorize)
+	auth.Post("/oidc/callback", oidcHandler.Callback)
 
 	// Protected routes (authentication required)
 	auth.Get("/me", jwtMiddleware.JWTAuth(), authHandler.Me)
 	auth.Post("/logout", jwtMiddleware.JWTAuth(), authHandler.Logout)
+	auth.Post("/logout-all", jwtMiddleware.JWTAuth(), authHandler.LogoutAll)
+	auth.Post("/2fa/enroll", jwtMiddleware.JWTAuth(), twoFactorHandler.Enroll)
+	auth.Post("/2fa/confirm", jwtMiddleware.JWTAuth(), twoFactorHandler.Confirm)
+	auth.Post("/2fa/disable", jwtMiddleware.JWTAuth(), twoFactorHandler.Disable)
+	auth.Get("/sessions", jwtMiddleware.JWTAuth(), sessionHandler.List)
+	auth.Delete("/sessions/:id", jwtMiddleware.JWTAuth(), sessionHandler.Revoke)
+
+	// API keys are managed with an access token only, so a leaked key cannot mint more keys
+	auth.Get("/api-keys", jwtMiddleware.JWTAuth(), apiKeyHandler.List)
+	auth.Post("/api-keys", jwtMiddleware.JWTAuth(), apiKeyHandler.Create)
+	auth.Delete("/api-keys/:id", jwtMiddleware.JWTAuth(), apiKeyHandler.Revoke)
 }
\ No newline at end of file
@@ -0,0 +1,36 @@
+package master
+
+import (
+	masterHandlers "api/internal/handlers/master"
+	"api/internal/middlewares"
+	"api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupMasterRoutes(app *fiber.App, warehouseHandler *masterHandlers.WarehouseHandler, productHandler *masterHandlers.ProductHandler, jwtMiddleware *middlewares.JWTMiddleware, apiKeyMiddleware *middlewares.APIKeyMiddleware) {
+	// Create master group (access token or API key required)
+	master := app.Group("/api/v1/master", middlewares.JWTOrAPIKeyAuth(jwtMiddleware, apiKeyMiddleware))
+
+	// Warehouse routes
+	warehouses := master.Group("/warehouses")
+	canReadWarehouses := middlewares.RequirePermission(models.PermissionWarehousesRead)
+	canWriteWarehouses := middlewares.RequirePermission(models.PermissionWarehousesWrite)
+	warehouses.Get("/", canReadWarehouses, warehouseHandler.List)
+	warehouses.Get("/:id", canReadWarehouses, warehouseHandler.Get)
+	warehouses.Post("/", canWriteWarehouses, warehouseHandler.Create)
+	warehouses.Put("/:id", canWriteWarehouses, warehouseHandler.Update)
+	warehouses.Delete("/:id", canWriteWarehouses, warehouseHandler.Delete)
+	warehouses.Post("/:id/restore", canWriteWarehouses, warehouseHandler.Restore)
+
+	// Product routes
+	products := master.Group("/products")
+	canReadProducts := middlewares.RequirePermission(models.PermissionProductsRead)
+	canWriteProducts := middlewares.RequirePermission(models.PermissionProductsWrite)
+	products.Get("/", canReadProducts, productHandler.List)
+	products.Get("/:id", canReadProducts, productHandler.Get)
+	products.Post("/", canWriteProducts, productHandler.Create)
+	products.Put("/:id", canWriteProducts, productHandler.Update)
+	products.Delete("/:id", canWriteProducts, productHandler.Delete)
+	products.Post("/:id/restore", canWriteProducts, productHandler.Restore)
+}
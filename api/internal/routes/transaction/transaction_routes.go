@@ -0,0 +1,39 @@
+package transaction
+
+import (
+	transactionHandlers "api/internal/handlers/transaction"
+	"api/internal/middlewares"
+	"api/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetupTransactionRoutes(app *fiber.App, transactionHandler *transactionHandlers.TransactionHandler, stockHandler *transactionHandlers.StockHandler, transferHandler *transactionHandlers.TransferHandler, jwtMiddleware *middlewares.JWTMiddleware, apiKeyMiddleware *middlewares.APIKeyMiddleware) {
+	// Access token or API key required
+	authenticated := middlewares.JWTOrAPIKeyAuth(jwtMiddleware, apiKeyMiddleware)
+
+	// Create transaction group
+	transactions := app.Group("/api/v1/transactions", authenticated)
+
+	canReadTransactions := middlewares.RequirePermission(models.PermissionTransactionsRead)
+	canWriteTransactions := middlewares.RequirePermission(models.PermissionTransactionsWrite)
+	transactions.Get("/", canReadTransactions, transactionHandler.List)
+	transactions.Get("/:id", canReadTransactions, transactionHandler.Get)
+	transactions.Post("/", canWriteTransactions, transactionHandler.Create)
+	transactions.Post("/:id/reverse", canWriteTransactions, transactionHandler.Reverse)
+	transactions.Delete("/:id", canWriteTransactions, transactionHandler.Delete)
+
+	// Per-warehouse stock balances
+	stock := app.Group("/api/v1/stock", authenticated)
+	stock.Get("/", middlewares.RequirePermission(models.PermissionStockRead), stockHandler.List)
+
+	// Inter-warehouse transfers
+	transfers := app.Group("/api/v1/transfers", authenticated)
+
+	canReadTransfers := middlewares.RequirePermission(models.PermissionTransfersRead)
+	canWriteTransfers := middlewares.RequirePermission(models.PermissionTransfersWrite)
+	transfers.Get("/", canReadTransfers, transferHandler.List)
+	transfers.Get("/:id", canReadTransfers, transferHandler.Get)
+	transfers.Post("/", canWriteTransfers, transferHandler.Create)
+	transfers.Post("/:id/receive", canWriteTransfers, transferHandler.Receive)
+}
@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefix marks our keys so secret scanners and people can recognise them
+	apiKeyPrefix = "pk_"
+	// apiKeyDisplayLength is how much of a key is kept in clear text to tell keys apart
+	apiKeyDisplayLength = 11
+	defaultAPIKeyTTL    = 90 * 24 * time.Hour
+	// apiKeyLastUsedInterval limits last_used_at writes to one per key per interval
+	apiKeyLastUsedInterval = time.Minute
+)
+
+type APIKeyService interface {
+	// Create issues a key limited to scopes the user's roles grant; the plain key is returned only here
+	Create(ctx context.Context, userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKeyResponse, error)
+	List(ctx context.Context, userID uint) ([]models.APIKeyResponse, error)
+	// Revoke deletes a key of the user
+	Revoke(ctx context.Context, userID, id uint) error
+	// Authenticate resolves a plain key to the key and its owner and records the use
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, *models.User, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepo  auth.APIKeyRepository
+	userRepo    auth.UserRepository
+	roleService RoleService
+}
+
+func NewAPIKeyService(apiKeyRepo auth.APIKeyRepository, userRepo auth.UserRepository, roleService RoleService) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo:  apiKeyRepo,
+		userRepo:    userRepo,
+		roleService: roleService,
+	}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, userID uint, req *models.CreateAPIKeyRequest) (*models.CreatedAPIKeyResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// A key may not grant more than its owner can do
+	granted, err := s.roleService.Permissions(ctx, user.RoleNames())
+	if err != nil {
+		return nil, err
+	}
+	scopes := uniqueNames(req.Scopes)
+	var missing []string
+	for _, scope := range scopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrScopeNotGranted, strings.Join(missing, ", "))
+	}
+
+	rawKey, err := newAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	ttl := defaultAPIKeyTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    rawKey[:apiKeyDisplayLength],
+		KeyHash:   hashOneTimeToken(rawKey),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &models.CreatedAPIKeyResponse{APIKeyResponse: key.ToResponse(), Key: rawKey}, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, userID uint) ([]models.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	responses := make([]models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		responses = append(responses, key.ToResponse())
+	}
+	return responses, nil
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userID, id uint) error {
+	deleted, err := s.apiKeyRepo.Delete(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if !deleted {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, *models.User, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashOneTimeToken(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	now := time.Now()
+	if now.After(key.ExpiresAt) {
+		return nil, nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidAPIKey
+		}
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now, now.Add(-apiKeyLastUsedInterval)); err != nil {
+		return nil, nil, fmt.Errorf("failed to record API key use: %w", err)
+	}
+
+	return key, user, nil
+}
+
+// ScopedPermissions limits the permissions of the key owner's roles to the key's scopes
+func ScopedPermissions(permissions map[string]bool, key *models.APIKey) map[string]bool {
+	scoped := make(map[string]bool)
+	for _, scope := range key.ScopeList() {
+		if permissions[scope] {
+			scoped[scope] = true
+		}
+	}
+	return scoped
+}
+
+// newAPIKey returns "pk_" followed by 256 random bits, base64url encoded
+func newAPIKey() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -3,41 +3,56 @@ package auth
 import (
 	"api/internal/models"
 	"api/internal/repositories/auth"
+	"context"
 	"errors"
 	"fmt"
+	"log"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	Register(req *models.RegisterRequest) (*models.AuthResponse, error)
-	Login(req *models.AuthRequest) (*models.AuthResponse, error)
-	GetUserByID(userID uint) (*models.UserResponse, error)
-	RefreshToken(req *models.RefreshTokenRequest) (*models.AuthResponse, error)
-	Logout(userID uint) error
+	// Register creates a user and, unless verification blocks sign-in, starts a session for client
+	Register(ctx context.Context, req *models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	// Login checks the credentials and starts a session for client
+	Login(ctx context.Context, req *models.AuthRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	GetUserByID(ctx context.Context, userID uint) (*models.UserResponse, error)
+	RefreshToken(ctx context.Context, req *models.RefreshTokenRequest, client models.ClientInfo) (*models.AuthResponse, error)
+	// Logout revokes the access token of the current session and, when given, its refresh token
+	Logout(ctx context.Context, userID uint, accessToken string, refreshToken string) error
+	// LogoutAll revokes every access and refresh token issued to the user
+	LogoutAll(ctx context.Context, userID uint) error
 }
 
 type authService struct {
-	userRepo   auth.UserRepository
-	jwtService JWTService
+	userRepo            auth.UserRepository
+	roleRepo            auth.RoleRepository
+	jwtService          JWTService
+	verificationService EmailVerificationService
+	verificationPolicy  VerificationPolicy
+	twoFactorService    TwoFactorService
 }
 
-func NewAuthService(userRepo auth.UserRepository, jwtService JWTService) AuthService {
+func NewAuthService(userRepo auth.UserRepository, roleRepo auth.RoleRepository, jwtService JWTService, verificationService EmailVerificationService, verificationPolicy VerificationPolicy, twoFactorService TwoFactorService) AuthService {
 	return &authService{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:            userRepo,
+		roleRepo:            roleRepo,
+		jwtService:          jwtService,
+		verificationService: verificationService,
+		verificationPolicy:  verificationPolicy,
+		twoFactorService:    twoFactorService,
 	}
 }
 
-func (s *authService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
+func (s *authService) Register(ctx context.Context, req *models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	// Check if email already exists
-	exists, err := s.userRepo.EmailExists(req.Email)
+	exists, err := s.userRepo.EmailExists(ctx, req.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check email existence: %w", err)
 	}
 	if exists {
-		return nil, errors.New("email already registered")
+		return nil, ErrEmailAlreadyRegistered
 	}
 
 	// Hash password
@@ -46,19 +61,38 @@ func (s *authService) Register(req *models.RegisterRequest) (*models.AuthRespons
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	// Self-registered users start with the least privileged role
+	defaultRole, err := s.roleRepo.GetByName(ctx, models.DefaultRole)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default role: %w", err)
+	}
+
 	// Create user
 	user := &models.User{
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: string(hashedPassword),
+		Roles:    []models.Role{*defaultRole},
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// The account exists either way; the user can ask for another link with resend-verification
+	if err := s.verificationService.SendVerification(ctx, user); err != nil {
+		log.Printf("Failed to send verification to user %d: %v", user.ID, err)
+	}
+
+	if s.verificationPolicy == VerificationPolicyBlock {
+		return &models.AuthResponse{
+			Message: "Please verify your email before signing in",
+			User:    user.ToResponse(),
+		}, nil
+	}
+
 	// Generate tokens
-	accessToken, refreshToken, expiresIn, err := s.jwtService.GenerateTokens(user)
+	accessToken, refreshToken, expiresIn, err := s.jwtService.GenerateTokens(ctx, user, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
@@ -73,23 +107,44 @@ func (s *authService) Register(req *models.RegisterRequest) (*models.AuthRespons
 	}, nil
 }
 
-func (s *authService) Login(req *models.AuthRequest) (*models.AuthResponse, error) {
+func (s *authService) Login(ctx context.Context, req *models.AuthRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(req.Email)
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid email or password")
+			return nil, ErrInvalidCredentials
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.EmailVerifiedAt == nil && s.verificationPolicy == VerificationPolicyBlock {
+		return nil, ErrEmailNotVerified
+	}
+
+	// With two-factor sign-in the password only earns an mfa_pending token for /auth/2fa/verify
+	twoFactorEnabled, err := s.twoFactorService.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactorEnabled {
+		mfaToken, err := s.jwtService.GenerateMFAToken(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+		return &models.AuthResponse{
+			Message:  "mfa_required",
+			User:     user.ToResponse(),
+			MFAToken: mfaToken,
+		}, nil
 	}
 
 	// Generate tokens
-	accessToken, refreshToken, expiresIn, err := s.jwtService.GenerateTokens(user)
+	accessToken, refreshToken, expiresIn, err := s.jwtService.GenerateTokens(ctx, user, client)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
@@ -104,11 +159,11 @@ func (s *authService) Login(req *models.AuthRequest) (*models.AuthResponse, erro
 	}, nil
 }
 
-func (s *authService) GetUserByID(userID uint) (*models.UserResponse, error) {
-	user, err := s.userRepo.GetByID(userID)
+func (s *authService) GetUserByID(ctx context.Context, userID uint) (*models.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -117,17 +172,11 @@ func (s *authService) GetUserByID(userID uint) (*models.UserResponse, error) {
 	return &userResponse, nil
 }
 
-func (s *authService) RefreshToken(req *models.RefreshTokenRequest) (*models.AuthResponse, error) {
-	// Generate new access token
-	accessToken, expiresIn, err := s.jwtService.RefreshAccessToken(req.RefreshToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed to refresh token: %w", err)
-	}
-
+func (s *authService) RefreshToken(ctx context.Context, req *models.RefreshTokenRequest, client models.ClientInfo) (*models.AuthResponse, error) {
 	// Validate refresh token to get user info
-	token, err := s.jwtService.ValidateToken(req.RefreshToken)
+	token, err := s.jwtService.ValidateRefreshToken(ctx, req.RefreshToken)
 	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token: %w", err)
+		return nil, refreshTokenError(err)
 	}
 
 	userID, err := s.jwtService.ExtractUserID(token)
@@ -135,8 +184,14 @@ func (s *authService) RefreshToken(req *models.RefreshTokenRequest) (*models.Aut
 		return nil, fmt.Errorf("failed to extract user ID: %w", err)
 	}
 
+	// Exchange the refresh token; a token that was already used revokes its family
+	accessToken, refreshToken, expiresIn, err := s.jwtService.RotateRefreshToken(ctx, req.RefreshToken, client)
+	if err != nil {
+		return nil, refreshTokenError(err)
+	}
+
 	// Get user details
-	userResponse, err := s.GetUserByID(userID)
+	userResponse, err := s.GetUserByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -145,26 +200,50 @@ func (s *authService) RefreshToken(req *models.RefreshTokenRequest) (*models.Aut
 		Message:      "success",
 		User:         *userResponse,
 		AccessToken:  accessToken,
-		RefreshToken: req.RefreshToken, // Keep the same refresh token
+		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    expiresIn,
 	}, nil
 }
 
-func (s *authService) Logout(userID uint) error {
-	// In a production environment, you might want to:
-	// 1. Blacklist the tokens
-	// 2. Store logout timestamp
-	// 3. Clear any session data
-	
-	// For now, we'll just validate that the user exists
-	_, err := s.userRepo.GetByID(userID)
+func (s *authService) Logout(ctx context.Context, userID uint, accessToken string, refreshToken string) error {
+	token, err := s.jwtService.ValidateToken(ctx, accessToken)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
-		}
-		return fmt.Errorf("failed to validate user: %w", err)
+		return ErrInvalidToken
+	}
+	if err := s.jwtService.RevokeToken(ctx, token); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
 	}
 
+	// An unusable refresh token needs no revocation, so logout still succeeds
+	if refreshToken == "" {
+		return nil
+	}
+	refresh, err := s.jwtService.ValidateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return nil
+	}
+	if ownerID, err := s.jwtService.ExtractUserID(refresh); err != nil || ownerID != userID {
+		return nil
+	}
+	if err := s.jwtService.RevokeToken(ctx, refresh); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+func (s *authService) LogoutAll(ctx context.Context, userID uint) error {
+	if err := s.jwtService.RevokeAllTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// refreshTokenError reports token problems as ErrInvalidRefreshToken and keeps storage failures as they are
+func refreshTokenError(err error) error {
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenRevoked) || errors.Is(err, ErrRefreshTokenReused) {
+		return fmt.Errorf("%w: %w", ErrInvalidRefreshToken, err)
+	}
+	return fmt.Errorf("failed to refresh token: %w", err)
+}
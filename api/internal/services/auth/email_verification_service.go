@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"api/pkg"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// VerificationPolicy decides what users with an unverified email may do
+type VerificationPolicy string
+
+const (
+	// VerificationPolicyAllow gives unverified users the same access as verified ones
+	VerificationPolicyAllow VerificationPolicy = "allow"
+	// VerificationPolicyReadOnly lets unverified users sign in but keeps only their ":read" permissions
+	VerificationPolicyReadOnly VerificationPolicy = "read_only"
+	// VerificationPolicyBlock refuses to sign in unverified users
+	VerificationPolicyBlock VerificationPolicy = "block"
+)
+
+type EmailVerificationService interface {
+	// SendVerification emails a verification link to the user
+	SendVerification(ctx context.Context, user *models.User) error
+	// VerifyEmail marks the email of the token's user as verified and revokes the user's tokens,
+	// so the next sign-in carries the verified state
+	VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error
+	// ResendVerification sends a new link when the email belongs to an unverified user.
+	// Other emails are not reported, so the endpoint cannot be used to discover accounts.
+	ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error
+}
+
+type emailVerificationService struct {
+	userRepo         auth.UserRepository
+	verificationRepo auth.EmailVerificationRepository
+	jwtService       JWTService
+	mailer           pkg.Mailer
+	verifyURL        string
+	tokenTTL         time.Duration
+}
+
+func NewEmailVerificationService(userRepo auth.UserRepository, verificationRepo auth.EmailVerificationRepository, jwtService JWTService, mailer pkg.Mailer, cfg config.EmailVerificationConfig) EmailVerificationService {
+	return &emailVerificationService{
+		userRepo:         userRepo,
+		verificationRepo: verificationRepo,
+		jwtService:       jwtService,
+		mailer:           mailer,
+		verifyURL:        cfg.URL,
+		tokenTTL:         cfg.TTL,
+	}
+}
+
+func (s *emailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	token, err := newOneTimeToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	err = s.verificationRepo.Create(ctx, &models.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashOneTimeToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save verification token: %w", err)
+	}
+
+	if err := s.mailer.Send(s.verificationMail(user, token)); err != nil {
+		return fmt.Errorf("failed to send verification mail: %w", err)
+	}
+	return nil
+}
+
+func (s *emailVerificationService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	record, err := s.verificationRepo.UseToken(ctx, hashOneTimeToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidVerificationToken
+		}
+		return fmt.Errorf("failed to use verification token: %w", err)
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+
+	if err := s.verificationRepo.MarkVerified(ctx, record.UserID, time.Now()); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	// Issued tokens still say unverified; end them so the next sign-in picks up the new state
+	if err := s.jwtService.RevokeAllTokens(ctx, record.UserID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *emailVerificationService) ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	// A delivery failure must look like success to the caller, otherwise it reveals that the account exists
+	if err := s.SendVerification(ctx, user); err != nil {
+		log.Printf("Failed to resend verification to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *emailVerificationService) verificationMail(user *models.User, token string) pkg.MailMessage {
+	link := s.verifyURL + "?token=" + url.QueryEscape(token)
+	return pkg.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address with the link below. It expires in %d minutes.\n\n%s\n\nIf you did not create an account, you can ignore this email.\n",
+			user.Name, int(s.tokenTTL.Minutes()), link),
+	}
+}
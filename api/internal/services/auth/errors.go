@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrEmailAlreadyRegistered   = errors.New("email already registered")
+	ErrInvalidCredentials       = errors.New("invalid email or password")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrInvalidToken             = errors.New("invalid token")
+	ErrInvalidRefreshToken      = errors.New("invalid refresh token")
+	ErrTokenRevoked             = errors.New("token has been revoked")
+	ErrRefreshTokenReused       = errors.New("refresh token has already been used")
+	ErrRoleNotFound             = errors.New("role not found")
+	ErrLastAdmin                = errors.New("cannot remove the admin role from the last admin")
+	ErrWarehouseNotFound        = errors.New("warehouse not found")
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+	ErrInvalidVerificationToken = errors.New("invalid or expired email verification token")
+	ErrEmailNotVerified         = errors.New("email address is not verified")
+	ErrTwoFactorAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled     = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode     = errors.New("invalid two-factor code")
+	ErrInvalidMFAToken          = errors.New("invalid or expired mfa token")
+	ErrInvalidAPIKey            = errors.New("invalid or expired API key")
+	ErrAPIKeyNotFound           = errors.New("API key not found")
+	ErrScopeNotGranted          = errors.New("scope is not granted by the user's roles")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrOIDCNotConfigured        = errors.New("OIDC sign-in is not configured")
+	ErrInvalidOIDCState         = errors.New("invalid or expired OIDC state")
+	ErrOIDCAuthenticationFailed = errors.New("OIDC authentication failed")
+	ErrOIDCEmailNotVerified     = errors.New("identity provider did not return a verified email")
+)
+
+// LockedError reports that sign-in is locked after too many failed attempts
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return "too many failed sign-in attempts"
+}
@@ -1,112 +1,128 @@
 package auth
 
 import (
+	"api/config"
 	"api/internal/models"
+	"api/internal/repositories/auth"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
-	"os"
+	"fmt"
+	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
 )
 
 type JWTService interface {
-	GenerateTokens(user *models.User) (accessToken, refreshToken string, expiresIn int64, err error)
-	ValidateToken(tokenString string) (*jwt.Token, error)
+	// GenerateTokens signs the user in: it starts a new session for client and issues its first token pair
+	GenerateTokens(ctx context.Context, user *models.User, client models.ClientInfo) (accessToken, refreshToken string, expiresIn int64, err error)
+	// ValidateToken parses an access token and rejects revoked or superseded ones
+	ValidateToken(ctx context.Context, tokenString string) (*jwt.Token, error)
+	// ValidateRefreshToken parses a refresh token with the same revocation checks
+	ValidateRefreshToken(ctx context.Context, tokenString string) (*jwt.Token, error)
 	ExtractUserID(token *jwt.Token) (uint, error)
-	RefreshAccessToken(refreshToken string) (accessToken string, expiresIn int64, err error)
+	// ExtractRoles returns the role names embedded when the token was issued
+	ExtractRoles(token *jwt.Token) ([]string, error)
+	// ExtractWarehouseIDs returns the warehouse assignments embedded when the token was issued
+	ExtractWarehouseIDs(token *jwt.Token) ([]uint, error)
+	// ExtractEmailVerified reports whether the user's email was verified when the token was issued
+	ExtractEmailVerified(token *jwt.Token) (bool, error)
+	// ExtractSessionID returns the session (refresh token family) the token was issued in, or "" for
+	// tokens that belong to no session
+	ExtractSessionID(token *jwt.Token) (string, error)
+	// RotateRefreshToken exchanges a refresh token for a new access and refresh token pair.
+	// The presented token is used up; presenting it again revokes its whole family.
+	RotateRefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (accessToken, newRefreshToken string, expiresIn int64, err error)
+	// RevokeToken blocks a single validated token until it expires (logout this session).
+	// Access and refresh tokens also revoke their family, ending the session and its refresh tokens.
+	RevokeToken(ctx context.Context, token *jwt.Token) error
+	// RevokeAllTokens invalidates every token issued to the user so far (logout all sessions)
+	RevokeAllTokens(ctx context.Context, userID uint) error
+	// GenerateMFAToken issues a short-lived "mfa_pending" token after the password step of a
+	// two-factor sign-in. It carries no roles and is not accepted as an access token.
+	GenerateMFAToken(ctx context.Context, user *models.User) (string, error)
+	// ValidateMFAToken parses an mfa_pending token with the same revocation checks
+	ValidateMFAToken(ctx context.Context, tokenString string) (*jwt.Token, error)
 }
 
+// maxUserAgentLength is the size of sessions.user_agent
+const maxUserAgentLength = 255
+
+// mfaTokenTTL bounds the time between the password step and the code step of a two-factor sign-in
+const mfaTokenTTL = 5 * time.Minute
+
 type jwtService struct {
-	secretKey        string
-	refreshSecretKey string
-	accessTokenTTL   time.Duration
-	refreshTokenTTL  time.Duration
+	keys            *SigningKeys
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	tokenStore      auth.TokenStore
 }
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Type   string `json:"type"` // "access" or "refresh"
+	UserID        uint     `json:"user_id"`
+	Email         string   `json:"email"`
+	Roles         []string `json:"roles"`
+	WarehouseIDs  []uint   `json:"whs"`
+	EmailVerified bool     `json:"ev"`
+	Type          string   `json:"type"`          // "access", "refresh" or "mfa_pending"
+	TokenVersion  uint     `json:"ver"`           // must match the user's current token version
+	FamilyID      string   `json:"fam,omitempty"` // session and refresh token rotation family
 	jwt.RegisteredClaims
 }
 
-func NewJWTService() JWTService {
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "your-secret-key-change-in-production"
+// NewJWTService creates the token service; token lifetimes come from cfg
+func NewJWTService(tokenStore auth.TokenStore, keys *SigningKeys, cfg config.JWTConfig) JWTService {
+	return &jwtService{
+		keys:            keys,
+		accessTokenTTL:  cfg.AccessTTL,
+		refreshTokenTTL: cfg.RefreshTTL,
+		tokenStore:      tokenStore,
 	}
+}
 
-	refreshSecretKey := os.Getenv("JWT_REFRESH_SECRET")
-	if refreshSecretKey == "" {
-		refreshSecretKey = "your-refresh-secret-key-change-in-production"
+func (s *jwtService) GenerateTokens(ctx context.Context, user *models.User, client models.ClientInfo) (accessToken, refreshToken string, expiresIn int64, err error) {
+	version, err := s.tokenStore.TokenVersion(ctx, user.ID)
+	if err != nil {
+		return "", "", 0, err
 	}
 
-	accessTTLStr := os.Getenv("JWT_ACCESS_TTL")
-	accessTTL := 15 * time.Minute // default 15 minutes
-	if accessTTLStr != "" {
-		if minutes, err := strconv.Atoi(accessTTLStr); err == nil {
-			accessTTL = time.Duration(minutes) * time.Minute
-		}
-	}
+	now := time.Now()
 
-	refreshTTLStr := os.Getenv("JWT_REFRESH_TTL")
-	refreshTTL := 7 * 24 * time.Hour // default 7 days
-	if refreshTTLStr != "" {
-		if hours, err := strconv.Atoi(refreshTTLStr); err == nil {
-			refreshTTL = time.Duration(hours) * time.Hour
-		}
+	// Start a new session, which is also the rotation family of its refresh tokens
+	identity := Claims{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Roles:         user.RoleNames(),
+		WarehouseIDs:  user.WarehouseIDs(),
+		EmailVerified: user.EmailVerifiedAt != nil,
+		TokenVersion:  version,
+		FamilyID:      newTokenID(),
 	}
-
-	return &jwtService{
-		secretKey:        secretKey,
-		refreshSecretKey: refreshSecretKey,
-		accessTokenTTL:   accessTTL,
-		refreshTokenTTL:  refreshTTL,
+	err = s.tokenStore.CreateSession(ctx, &models.Session{
+		FamilyID:   identity.FamilyID,
+		UserID:     user.ID,
+		UserAgent:  truncate(client.UserAgent, maxUserAgentLength),
+		IPAddress:  client.IPAddress,
+		ExpiresAt:  now.Add(s.refreshTokenTTL),
+		LastUsedAt: now,
+	})
+	if err != nil {
+		return "", "", 0, err
 	}
-}
-
-func (s *jwtService) GenerateTokens(user *models.User) (accessToken, refreshToken string, expiresIn int64, err error) {
-	now := time.Now()
-	accessExpiry := now.Add(s.accessTokenTTL)
-	refreshExpiry := now.Add(s.refreshTokenTTL)
 
 	// Generate access token
-	accessClaims := Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Type:   "access",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(accessExpiry),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "pseudo-app",
-			Subject:   strconv.Itoa(int(user.ID)),
-		},
-	}
-
-	accessTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessTokenObj.SignedString([]byte(s.secretKey))
+	accessToken, err = s.keys.sign(newClaims(identity, "access", now, s.accessTokenTTL))
 	if err != nil {
 		return "", "", 0, err
 	}
 
 	// Generate refresh token
-	refreshClaims := Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Type:   "refresh",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(refreshExpiry),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-			Issuer:    "pseudo-app",
-			Subject:   strconv.Itoa(int(user.ID)),
-		},
-	}
-
-	refreshTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshToken, err = refreshTokenObj.SignedString([]byte(s.refreshSecretKey))
+	refreshToken, err = s.issueRefreshToken(ctx, identity, nil, now)
 	if err != nil {
 		return "", "", 0, err
 	}
@@ -114,13 +130,12 @@ func (s *jwtService) GenerateTokens(user *models.User) (accessToken, refreshToke
 	return accessToken, refreshToken, int64(s.accessTokenTTL.Seconds()), nil
 }
 
-func (s *jwtService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	return jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(s.secretKey), nil
-	})
+func (s *jwtService) ValidateToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	return s.parse(ctx, tokenString, "access")
+}
+
+func (s *jwtService) ValidateRefreshToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	return s.parse(ctx, tokenString, "refresh")
 }
 
 func (s *jwtService) ExtractUserID(token *jwt.Token) (uint, error) {
@@ -131,45 +146,222 @@ func (s *jwtService) ExtractUserID(token *jwt.Token) (uint, error) {
 	return claims.UserID, nil
 }
 
-func (s *jwtService) RefreshAccessToken(refreshToken string) (accessToken string, expiresIn int64, err error) {
-	token, err := jwt.ParseWithClaims(refreshToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
+func (s *jwtService) ExtractRoles(token *jwt.Token) ([]string, error) {
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims.Roles, nil
+}
+
+func (s *jwtService) ExtractWarehouseIDs(token *jwt.Token) ([]uint, error) {
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token claims")
+	}
+	return claims.WarehouseIDs, nil
+}
+
+func (s *jwtService) ExtractEmailVerified(token *jwt.Token) (bool, error) {
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return false, errors.New("invalid token claims")
+	}
+	return claims.EmailVerified, nil
+}
+
+func (s *jwtService) ExtractSessionID(token *jwt.Token) (string, error) {
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return "", errors.New("invalid token claims")
+	}
+	return claims.FamilyID, nil
+}
+
+func (s *jwtService) RotateRefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (accessToken, newRefreshToken string, expiresIn int64, err error) {
+	token, err := s.ValidateRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return "", "", 0, err
+	}
+	claims := token.Claims.(*Claims)
+
+	record, err := s.tokenStore.UseRefreshToken(ctx, claims.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", 0, ErrInvalidToken
+	}
+	if err != nil {
+		return "", "", 0, err
+	}
+	if record.RevokedAt != nil {
+		return "", "", 0, ErrTokenRevoked
+	}
+	if record.UsedAt != nil {
+		// Either the client or an attacker holds a stolen copy; we cannot tell which, so end the session for both
+		log.Printf("Refresh token reuse detected for user %d (family %s, token %s); revoking the family", record.UserID, record.FamilyID, record.JTI)
+		if err := s.tokenStore.RevokeTokenFamily(ctx, record.FamilyID); err != nil {
+			return "", "", 0, err
 		}
-		return []byte(s.refreshSecretKey), nil
-	})
+		return "", "", 0, ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+
+	// Generate new access token
+	accessClaims := newClaims(*claims, "access", now, s.accessTokenTTL)
+	accessToken, err = s.keys.sign(accessClaims)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	// Generate the next refresh token of the family
+	newRefreshToken, err = s.issueRefreshToken(ctx, *claims, &record.JTI, now)
+	if err != nil {
+		return "", "", 0, err
+	}
 
+	err = s.tokenStore.TouchSession(ctx, record.FamilyID, models.ClientInfo{
+		IPAddress: client.IPAddress,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+	}, now, now.Add(s.refreshTokenTTL))
 	if err != nil {
-		return "", 0, err
+		return "", "", 0, err
 	}
 
+	return accessToken, newRefreshToken, int64(s.accessTokenTTL.Seconds()), nil
+}
+
+func (s *jwtService) RevokeToken(ctx context.Context, token *jwt.Token) error {
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid || claims.Type != "refresh" {
-		return "", 0, errors.New("invalid refresh token")
+	if !ok || claims.ExpiresAt == nil {
+		return ErrInvalidToken
+	}
+	if err := s.tokenStore.RevokeToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+		return err
 	}
+	if claims.FamilyID != "" {
+		return s.tokenStore.RevokeTokenFamily(ctx, claims.FamilyID)
+	}
+	return nil
+}
 
-	// Generate new access token
-	now := time.Now()
-	accessExpiry := now.Add(s.accessTokenTTL)
+func (s *jwtService) RevokeAllTokens(ctx context.Context, userID uint) error {
+	_, err := s.tokenStore.IncrementTokenVersion(ctx, userID)
+	return err
+}
+
+func (s *jwtService) GenerateMFAToken(ctx context.Context, user *models.User) (string, error) {
+	version, err := s.tokenStore.TokenVersion(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+	identity := Claims{UserID: user.ID, Email: user.Email, TokenVersion: version}
+	return s.keys.sign(newClaims(identity, "mfa_pending", time.Now(), mfaTokenTTL))
+}
+
+func (s *jwtService) ValidateMFAToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	return s.parse(ctx, tokenString, "mfa_pending")
+}
+
+// parse verifies signature, expiry and token type, then consults the token store
+func (s *jwtService) parse(ctx context.Context, tokenString, tokenType string) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.keys.keyFunc(tokenType))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
 
-	accessClaims := Claims{
-		UserID: claims.UserID,
-		Email:  claims.Email,
-		Type:   "access",
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid || claims.Type != tokenType || claims.ID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := s.tokenStore.IsTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+
+	version, err := s.tokenStore.TokenVersion(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenVersion != version {
+		return nil, ErrTokenRevoked
+	}
+
+	// Revoking a session revokes every token issued in it
+	if claims.FamilyID != "" {
+		revoked, err := s.tokenStore.IsSessionRevoked(ctx, claims.FamilyID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return token, nil
+}
+
+// issueRefreshToken signs a refresh token in the family of identity and records it in the token store
+func (s *jwtService) issueRefreshToken(ctx context.Context, identity Claims, parentJTI *string, now time.Time) (string, error) {
+	claims := newClaims(identity, "refresh", now, s.refreshTokenTTL)
+
+	signed, err := s.keys.sign(claims)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.tokenStore.SaveRefreshToken(ctx, &models.RefreshToken{
+		JTI:       claims.ID,
+		FamilyID:  claims.FamilyID,
+		ParentJTI: parentJTI,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return signed, nil
+}
+
+// newClaims builds the claims of a token with a fresh jti, copying the user fields of identity
+func newClaims(identity Claims, tokenType string, now time.Time, ttl time.Duration) Claims {
+	return Claims{
+		UserID:        identity.UserID,
+		Email:         identity.Email,
+		Roles:         identity.Roles,
+		WarehouseIDs:  identity.WarehouseIDs,
+		EmailVerified: identity.EmailVerified,
+		Type:          tokenType,
+		TokenVersion:  identity.TokenVersion,
+		FamilyID:      identity.FamilyID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(accessExpiry),
+			ID:        newTokenID(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "pseudo-app",
-			Subject:   strconv.Itoa(int(claims.UserID)),
+			Subject:   strconv.Itoa(int(identity.UserID)),
 		},
 	}
+}
 
-	accessTokenObj := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessToken, err = accessTokenObj.SignedString([]byte(s.secretKey))
-	if err != nil {
-		return "", 0, err
+// truncate shortens s to at most n bytes, e.g. a user agent to its column size
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
 	}
+	return strings.ToValidUTF8(s[:n], "")
+}
 
-	return accessToken, int64(s.accessTokenTTL.Seconds()), nil
-}
\ No newline at end of file
+// newTokenID returns a random 128-bit hex jti
+func newTokenID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"api/config"
+	"api/internal/repositories/auth"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LoginAttemptService throttles sign-in per email and per client IP. Once a key reaches its
+// failure limit it is locked, and every further failure doubles the lock, up to a maximum.
+type LoginAttemptService interface {
+	// Check returns how long sign-in stays locked for the email or the IP; zero means it is allowed
+	Check(ctx context.Context, email, ip string) (time.Duration, error)
+	// RecordFailure counts a failed sign-in and returns the lock it caused, if any
+	RecordFailure(ctx context.Context, email, ip string) (time.Duration, error)
+	// RecordSuccess clears the failures of the email. The IP keeps its count, so one valid
+	// account cannot be used to reset the counter while guessing the passwords of others.
+	RecordSuccess(ctx context.Context, email string) error
+	// Unlock clears the failures and lock of the user's email
+	Unlock(ctx context.Context, userID uint) error
+}
+
+type loginAttemptService struct {
+	store          auth.LoginAttemptStore
+	userRepo       auth.UserRepository
+	maxAttempts    uint
+	maxIPAttempts  uint
+	lockoutBase    time.Duration
+	lockoutMax     time.Duration
+	trackingWindow time.Duration
+}
+
+func NewLoginAttemptService(store auth.LoginAttemptStore, userRepo auth.UserRepository, cfg config.LoginAttemptsConfig) LoginAttemptService {
+	return &loginAttemptService{
+		store:          store,
+		userRepo:       userRepo,
+		maxAttempts:    uint(cfg.MaxAttempts),
+		maxIPAttempts:  uint(cfg.MaxIPAttempts),
+		lockoutBase:    cfg.LockoutBase,
+		lockoutMax:     cfg.LockoutMax,
+		trackingWindow: cfg.Window,
+	}
+}
+
+func (s *loginAttemptService) Check(ctx context.Context, email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var retryAfter time.Duration
+	for _, key := range []string{emailKey(email), ipKey(ip)} {
+		attempt, err := s.store.Get(ctx, key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get login attempts: %w", err)
+		}
+		if attempt != nil && attempt.LockedUntil != nil {
+			retryAfter = max(retryAfter, attempt.LockedUntil.Sub(now))
+		}
+	}
+	return retryAfter, nil
+}
+
+func (s *loginAttemptService) RecordFailure(ctx context.Context, email, ip string) (time.Duration, error) {
+	emailLock, err := s.recordFailure(ctx, emailKey(email), s.maxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	ipLock, err := s.recordFailure(ctx, ipKey(ip), s.maxIPAttempts)
+	if err != nil {
+		return 0, err
+	}
+	return max(emailLock, ipLock), nil
+}
+
+func (s *loginAttemptService) RecordSuccess(ctx context.Context, email string) error {
+	if err := s.store.Reset(ctx, emailKey(email)); err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
+
+func (s *loginAttemptService) Unlock(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.RecordSuccess(ctx, user.Email)
+}
+
+// recordFailure counts a failure for key and locks it once limit is reached
+func (s *loginAttemptService) recordFailure(ctx context.Context, key string, limit uint) (time.Duration, error) {
+	now := time.Now()
+	attempt, err := s.store.RecordFailure(ctx, key, now, s.trackingWindow)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	if attempt.Failures < limit {
+		return 0, nil
+	}
+
+	lock := s.lockoutDuration(attempt.Failures - limit)
+	if err := s.store.Lock(ctx, key, now.Add(lock)); err != nil {
+		return 0, fmt.Errorf("failed to lock sign-in: %w", err)
+	}
+	log.Printf("Sign-in locked for %s for %s after %d failed attempts", key, lock, attempt.Failures)
+	return lock, nil
+}
+
+// lockoutDuration doubles the base lock for every failure past the limit, up to the maximum
+func (s *loginAttemptService) lockoutDuration(failuresPastLimit uint) time.Duration {
+	lock := s.lockoutBase
+	for i := uint(0); i < failuresPastLimit && lock < s.lockoutMax; i++ {
+		lock *= 2
+	}
+	return min(lock, s.lockoutMax)
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
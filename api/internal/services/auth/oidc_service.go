@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"api/pkg"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// oidcStateTTL is how long a user has to sign in at the provider
+const oidcStateTTL = 10 * time.Minute
+
+// maxUserNameLength matches the users.name column
+const maxUserNameLength = 100
+
+type OIDCService interface {
+	// Enabled reports whether an identity provider is configured
+	Enabled() bool
+	// AuthorizationURL starts a sign-in and returns the provider URL to redirect the browser to
+	AuthorizationURL(ctx context.Context) (*models.OIDCAuthorizationResponse, error)
+	// Callback finishes a sign-in with the code and state from the provider redirect.
+	// The provider account is matched to a user by link, then by verified email, and a user
+	// is created on first sign-in.
+	Callback(ctx context.Context, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error)
+}
+
+type oidcService struct {
+	provider         *pkg.OIDCProvider
+	oidcRepo         auth.OIDCRepository
+	userRepo         auth.UserRepository
+	roleRepo         auth.RoleRepository
+	jwtService       JWTService
+	twoFactorService TwoFactorService
+}
+
+// NewOIDCService creates the OIDC sign-in service; a nil provider disables it
+func NewOIDCService(provider *pkg.OIDCProvider, oidcRepo auth.OIDCRepository, userRepo auth.UserRepository, roleRepo auth.RoleRepository, jwtService JWTService, twoFactorService TwoFactorService) OIDCService {
+	return &oidcService{
+		provider:         provider,
+		oidcRepo:         oidcRepo,
+		userRepo:         userRepo,
+		roleRepo:         roleRepo,
+		jwtService:       jwtService,
+		twoFactorService: twoFactorService,
+	}
+}
+
+func (s *oidcService) Enabled() bool {
+	return s.provider != nil
+}
+
+func (s *oidcService) AuthorizationURL(ctx context.Context) (*models.OIDCAuthorizationResponse, error) {
+	if s.provider == nil {
+		return nil, ErrOIDCNotConfigured
+	}
+
+	state, err := newOneTimeToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := pkg.GenerateOIDCNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	codeVerifier, err := pkg.GeneratePKCEVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	authorizationURL, err := s.provider.AuthCodeURL(state, nonce, codeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build authorization URL: %w", err)
+	}
+
+	err = s.oidcRepo.SaveState(ctx, &models.OIDCState{
+		StateHash:    hashOneTimeToken(state),
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	return &models.OIDCAuthorizationResponse{AuthorizationURL: authorizationURL}, nil
+}
+
+func (s *oidcService) Callback(ctx context.Context, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	if s.provider == nil {
+		return nil, ErrOIDCNotConfigured
+	}
+
+	// The state is used once, so a replayed callback fails here
+	state, err := s.oidcRepo.TakeState(ctx, hashOneTimeToken(req.State))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, fmt.Errorf("failed to get state: %w", err)
+	}
+	if time.Now().After(state.ExpiresAt) {
+		return nil, ErrInvalidOIDCState
+	}
+
+	rawIDToken, err := s.provider.Exchange(req.Code, state.CodeVerifier)
+	if err != nil {
+		if errors.Is(err, pkg.ErrOIDCRejected) || errors.Is(err, pkg.ErrOIDCInvalidIDToken) {
+			return nil, fmt.Errorf("%w: %w", ErrOIDCAuthenticationFailed, err)
+		}
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	claims, err := s.provider.VerifyIDToken(rawIDToken, state.Nonce)
+	if err != nil {
+		if errors.Is(err, pkg.ErrOIDCInvalidIDToken) {
+			return nil, fmt.Errorf("%w: %w", ErrOIDCAuthenticationFailed, err)
+		}
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+
+	user, err := s.findOrCreateUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// The provider replaces the password step only; two-factor sign-in still applies
+	twoFactorEnabled, err := s.twoFactorService.Enabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactorEnabled {
+		mfaToken, err := s.jwtService.GenerateMFAToken(ctx, user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate mfa token: %w", err)
+		}
+		return &models.AuthResponse{
+			Message:  "mfa_required",
+			User:     user.ToResponse(),
+			MFAToken: mfaToken,
+		}, nil
+	}
+
+	// Generate tokens
+	accessToken, refreshToken, expiresIn, err := s.jwtService.GenerateTokens(ctx, user, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	return &models.AuthResponse{
+		Message:      "success",
+		User:         user.ToResponse(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+// findOrCreateUser returns the user linked to the provider account, linking or creating one on
+// first sign-in. Emails are only trusted when the provider verified them, otherwise anyone able to
+// register the address at the provider could take over the local account.
+func (s *oidcService) findOrCreateUser(ctx context.Context, claims *pkg.OIDCClaims) (*models.User, error) {
+	identity, err := s.oidcRepo.GetIdentity(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	now := time.Now()
+	user, err := s.userRepo.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		// The provider has verified the address, which is as good as our verification link
+		if user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return nil, fmt.Errorf("failed to verify email: %w", err)
+			}
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = s.createUser(ctx, claims, now)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	err = s.oidcRepo.CreateIdentity(ctx, &models.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+	return user, nil
+}
+
+// createUser creates the user of a first OIDC sign-in with the default role
+func (s *oidcService) createUser(ctx context.Context, claims *pkg.OIDCClaims, verifiedAt time.Time) (*models.User, error) {
+	// The user signs in through the provider; the random password only fills the column until
+	// they set one with forgot-password
+	password, err := newOneTimeToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	defaultRole, err := s.roleRepo.GetByName(ctx, models.DefaultRole)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default role: %w", err)
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	user := &models.User{
+		Name:            truncate(name, maxUserNameLength),
+		Email:           claims.Email,
+		Password:        string(hashedPassword),
+		EmailVerifiedAt: &verifiedAt,
+		Roles:           []models.Role{*defaultRole},
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
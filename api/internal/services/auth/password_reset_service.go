@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"api/pkg"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type PasswordResetService interface {
+	// ForgotPassword emails a reset link when the email belongs to a user.
+	// Unknown emails are not reported, so the endpoint cannot be used to discover accounts.
+	ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error
+	// ResetPassword sets a new password with a reset token and revokes every session of the user
+	ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error
+}
+
+type passwordResetService struct {
+	userRepo   auth.UserRepository
+	resetRepo  auth.PasswordResetRepository
+	jwtService JWTService
+	mailer     pkg.Mailer
+	resetURL   string
+	tokenTTL   time.Duration
+}
+
+func NewPasswordResetService(userRepo auth.UserRepository, resetRepo auth.PasswordResetRepository, jwtService JWTService, mailer pkg.Mailer, cfg config.PasswordResetConfig) PasswordResetService {
+	return &passwordResetService{
+		userRepo:   userRepo,
+		resetRepo:  resetRepo,
+		jwtService: jwtService,
+		mailer:     mailer,
+		resetURL:   cfg.URL,
+		tokenTTL:   cfg.TTL,
+	}
+}
+
+func (s *passwordResetService) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest) error {
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	token, err := newOneTimeToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	err = s.resetRepo.Create(ctx, &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashOneTimeToken(token),
+		ExpiresAt: time.Now().Add(s.tokenTTL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save reset token: %w", err)
+	}
+
+	// A delivery failure must look like success to the caller, otherwise it reveals that the account exists
+	if err := s.mailer.Send(s.resetMail(user, token)); err != nil {
+		log.Printf("Failed to send password reset mail to user %d: %v", user.ID, err)
+	}
+	return nil
+}
+
+func (s *passwordResetService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest) error {
+	record, err := s.resetRepo.UseToken(ctx, hashOneTimeToken(req.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to use reset token: %w", err)
+	}
+	if record.UsedAt != nil || time.Now().After(record.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, record.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Whoever knew the old password must not stay signed in
+	if err := s.jwtService.RevokeAllTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	if err := s.resetRepo.DeleteUserTokens(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete reset tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *passwordResetService) resetMail(user *models.User, token string) pkg.MailMessage {
+	link := s.resetURL + "?token=" + url.QueryEscape(token)
+	return pkg.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can be used once.\n\n%s\n\nIf you did not ask for a password reset, you can ignore this email.\n",
+			user.Name, int(s.tokenTTL.Minutes()), link),
+	}
+}
+
+// newOneTimeToken returns a random 256-bit hex token
+func newOneTimeToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// hashOneTimeToken returns the hex SHA-256 of token, the form stored in the database
+func hashOneTimeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// permissionCacheTTL bounds how long a manual change to role_permissions takes to apply
+const permissionCacheTTL = time.Minute
+
+type RoleService interface {
+	ListRoles(ctx context.Context) ([]models.RoleResponse, error)
+	GetUserRoles(ctx context.Context, userID uint) (*models.UserRolesResponse, error)
+	// AssignRoles replaces the roles of a user. The user's tokens are revoked,
+	// so the next sign-in carries the new roles.
+	AssignRoles(ctx context.Context, userID uint, req *models.AssignRolesRequest) (*models.UserRolesResponse, error)
+	// Permissions resolves role names to the set of permissions they grant
+	Permissions(ctx context.Context, roles []string) (map[string]bool, error)
+}
+
+type roleService struct {
+	roleRepo   auth.RoleRepository
+	userRepo   auth.UserRepository
+	jwtService JWTService
+
+	mu              sync.Mutex
+	rolePermissions map[string][]string
+	loadedAt        time.Time
+}
+
+func NewRoleService(roleRepo auth.RoleRepository, userRepo auth.UserRepository, jwtService JWTService) RoleService {
+	return &roleService{
+		roleRepo:   roleRepo,
+		userRepo:   userRepo,
+		jwtService: jwtService,
+	}
+}
+
+func (s *roleService) ListRoles(ctx context.Context) ([]models.RoleResponse, error) {
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	items := make([]models.RoleResponse, 0, len(roles))
+	for i := range roles {
+		items = append(items, roles[i].ToResponse())
+	}
+	return items, nil
+}
+
+func (s *roleService) GetUserRoles(ctx context.Context, userID uint) (*models.UserRolesResponse, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserRolesResponse{UserID: user.ID, Roles: user.RoleNames()}, nil
+}
+
+func (s *roleService) AssignRoles(ctx context.Context, userID uint, req *models.AssignRolesRequest) (*models.UserRolesResponse, error) {
+	names := uniqueNames(req.Roles)
+	roles, err := s.roleRepo.GetByNames(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roles: %w", err)
+	}
+	if len(roles) != len(names) {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, strings.Join(missingRoles(names, roles), ", "))
+	}
+
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if slices.Contains(user.RoleNames(), models.RoleAdmin) && !slices.Contains(names, models.RoleAdmin) {
+		admins, err := s.roleRepo.CountUsersWithRole(ctx, models.RoleAdmin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count admins: %w", err)
+		}
+		if admins <= 1 {
+			return nil, ErrLastAdmin
+		}
+	}
+
+	if err := s.roleRepo.ReplaceUserRoles(ctx, userID, roles); err != nil {
+		return nil, fmt.Errorf("failed to assign roles: %w", err)
+	}
+	if err := s.jwtService.RevokeAllTokens(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	assigned := make([]string, 0, len(roles))
+	for _, role := range roles {
+		assigned = append(assigned, role.Name)
+	}
+	return &models.UserRolesResponse{UserID: userID, Roles: assigned}, nil
+}
+
+func (s *roleService) Permissions(ctx context.Context, roles []string) (map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rolePermissions == nil || time.Since(s.loadedAt) > permissionCacheTTL {
+		rolePermissions, err := s.roleRepo.RolePermissions(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load role permissions: %w", err)
+		}
+		s.rolePermissions = rolePermissions
+		s.loadedAt = time.Now()
+	}
+
+	permissions := make(map[string]bool)
+	for _, role := range roles {
+		for _, permission := range s.rolePermissions[role] {
+			permissions[permission] = true
+		}
+	}
+	return permissions, nil
+}
+
+func (s *roleService) findUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+func missingRoles(names []string, roles []models.Role) []string {
+	found := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		found[role.Name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
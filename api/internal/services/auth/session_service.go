@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"context"
+	"fmt"
+)
+
+type SessionService interface {
+	// List returns the user's active sessions, marking the one with currentSessionID as current
+	List(ctx context.Context, userID uint, currentSessionID string) ([]models.SessionResponse, error)
+	// Revoke signs a session of the user out: its refresh and access tokens stop working
+	Revoke(ctx context.Context, userID, id uint) error
+}
+
+type sessionService struct {
+	tokenStore auth.TokenStore
+}
+
+func NewSessionService(tokenStore auth.TokenStore) SessionService {
+	return &sessionService{
+		tokenStore: tokenStore,
+	}
+}
+
+func (s *sessionService) List(ctx context.Context, userID uint, currentSessionID string) ([]models.SessionResponse, error) {
+	sessions, err := s.tokenStore.ListSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	responses := make([]models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, session.ToResponse(currentSessionID))
+	}
+	return responses, nil
+}
+
+func (s *sessionService) Revoke(ctx context.Context, userID, id uint) error {
+	revoked, err := s.tokenStore.RevokeSession(ctx, userID, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
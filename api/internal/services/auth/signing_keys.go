@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"api/config"
+	"api/internal/models"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Development fallbacks for the HS256 secrets; LoadSigningKeys refuses them unless APP_DEBUG is true
+const (
+	defaultAccessSecret  = "your-secret-key-change-in-production"
+	defaultRefreshSecret = "your-refresh-secret-key-change-in-production"
+)
+
+// verificationKey is a public key that tokens may be signed with, identified by its kid
+type verificationKey struct {
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// SigningKeys holds the keys tokens are signed and verified with. With JWT_PRIVATE_KEY_FILE it
+// signs with RS256 or EdDSA, depending on the key, and verifies against the matching public key
+// plus any retired keys from JWT_PUBLIC_KEY_FILES. Otherwise it falls back to the HS256 secrets.
+type SigningKeys struct {
+	method     jwt.SigningMethod
+	keyID      string
+	privateKey crypto.PrivateKey
+	verifyKeys map[string]verificationKey
+
+	// HS256 only; refresh tokens use their own secret
+	accessSecret  []byte
+	refreshSecret []byte
+}
+
+// LoadSigningKeys loads the signing keys from PEM files, or the HS256 secrets when
+// JWT_PRIVATE_KEY_FILE is unset. Missing or default secrets are an error unless debug is set.
+func LoadSigningKeys(cfg config.JWTConfig, debug bool) (*SigningKeys, error) {
+	privateKeyFile := cfg.PrivateKeyFile
+	if privateKeyFile == "" {
+		return loadHMACKeys(cfg, debug)
+	}
+
+	pemBytes, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT_PRIVATE_KEY_FILE: %w", err)
+	}
+	privateKey, err := parsePrivateKey(pemBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_PRIVATE_KEY_FILE %s: %w", privateKeyFile, err)
+	}
+
+	keys, err := NewAsymmetricSigningKeys(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range cfg.PublicKeyFiles {
+		pemBytes, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT_PUBLIC_KEY_FILES entry: %w", err)
+		}
+		publicKey, err := parsePublicKey(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_PUBLIC_KEY_FILES entry %s: %w", file, err)
+		}
+		if err := keys.AddVerificationKey(publicKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// NewAsymmetricSigningKeys signs with an *rsa.PrivateKey (RS256) or ed25519.PrivateKey (EdDSA)
+func NewAsymmetricSigningKeys(privateKey crypto.PrivateKey) (*SigningKeys, error) {
+	var publicKey crypto.PublicKey
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		publicKey = &key.PublicKey
+	case ed25519.PrivateKey:
+		publicKey = key.Public()
+	default:
+		return nil, fmt.Errorf("unsupported JWT private key type %T", privateKey)
+	}
+
+	keys := &SigningKeys{privateKey: privateKey, verifyKeys: map[string]verificationKey{}}
+	if err := keys.AddVerificationKey(publicKey); err != nil {
+		return nil, err
+	}
+	keys.keyID, _ = keyThumbprint(publicKey)
+	keys.method = keys.verifyKeys[keys.keyID].method
+	return keys, nil
+}
+
+// NewHMACSigningKeys signs with HS256; nothing is published in the JWKS
+func NewHMACSigningKeys(accessSecret, refreshSecret string) *SigningKeys {
+	return &SigningKeys{
+		method:        jwt.SigningMethodHS256,
+		accessSecret:  []byte(accessSecret),
+		refreshSecret: []byte(refreshSecret),
+	}
+}
+
+// AddVerificationKey accepts tokens signed by the private half of publicKey, e.g. a retired
+// signing key whose tokens have not expired yet
+func (k *SigningKeys) AddVerificationKey(publicKey crypto.PublicKey) error {
+	if k.verifyKeys == nil {
+		return errors.New("verification keys need asymmetric signing")
+	}
+
+	var method jwt.SigningMethod
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return fmt.Errorf("unsupported JWT public key type %T", publicKey)
+	}
+
+	kid, err := keyThumbprint(publicKey)
+	if err != nil {
+		return err
+	}
+	k.verifyKeys[kid] = verificationKey{method: method, public: publicKey}
+	return nil
+}
+
+// Algorithm returns the JWS algorithm new tokens are signed with
+func (k *SigningKeys) Algorithm() string {
+	return k.method.Alg()
+}
+
+// JWKS returns the public verification keys, signing key first; it is empty with HS256
+func (k *SigningKeys) JWKS() models.JWKSet {
+	kids := make([]string, 0, len(k.verifyKeys))
+	for kid := range k.verifyKeys {
+		if kid != k.keyID {
+			kids = append(kids, kid)
+		}
+	}
+	slices.Sort(kids)
+	if k.privateKey != nil {
+		kids = append([]string{k.keyID}, kids...)
+	}
+
+	set := models.JWKSet{Keys: make([]models.JWK, 0, len(kids))}
+	for _, kid := range kids {
+		key := k.verifyKeys[kid]
+		jwk, _ := publicJWK(key.public)
+		jwk.KeyID = kid
+		jwk.Use = "sig"
+		jwk.Algorithm = key.method.Alg()
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// sign signs claims with the current key, setting the kid header for asymmetric keys
+func (k *SigningKeys) sign(claims Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.privateKey == nil {
+		if claims.Type == "refresh" {
+			return token.SignedString(k.refreshSecret)
+		}
+		return token.SignedString(k.accessSecret)
+	}
+
+	token.Header["kid"] = k.keyID
+	return token.SignedString(k.privateKey)
+}
+
+// keyFunc returns the jwt.Keyfunc for tokens of tokenType, picking the verification key by kid
+func (k *SigningKeys) keyFunc(tokenType string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if k.privateKey == nil {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("unexpected signing method")
+			}
+			if tokenType == "refresh" {
+				return k.refreshSecret, nil
+			}
+			return k.accessSecret, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := k.verifyKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.public, nil
+	}
+}
+
+func loadHMACKeys(cfg config.JWTConfig, debug bool) (*SigningKeys, error) {
+	accessSecret, err := hmacSecret("JWT_SECRET", cfg.Secret, defaultAccessSecret, debug)
+	if err != nil {
+		return nil, err
+	}
+	refreshSecret, err := hmacSecret("JWT_REFRESH_SECRET", cfg.RefreshSecret, defaultRefreshSecret, debug)
+	if err != nil {
+		return nil, err
+	}
+	return NewHMACSigningKeys(accessSecret, refreshSecret), nil
+}
+
+// hmacSecret checks an HS256 secret, allowing the default only in debug mode
+func hmacSecret(name, value, fallback string, debug bool) (string, error) {
+	if value != "" && value != fallback {
+		return value, nil
+	}
+	if !debug {
+		return "", fmt.Errorf("%s is unset or uses the default value; set it or JWT_PRIVATE_KEY_FILE, or set APP_DEBUG=true for development", name)
+	}
+	log.Printf("Warning: %s is unset or uses the default value, anyone can forge tokens; never run like this in production", name)
+	return fallback, nil
+}
+
+func parsePrivateKey(pemBytes []byte) (crypto.PrivateKey, error) {
+	if key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, errors.New("expected a PEM encoded RSA or Ed25519 private key")
+	}
+	return key, nil
+}
+
+func parsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	if key, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return key, nil
+	}
+	key, err := jwt.ParseEdPublicKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, errors.New("expected a PEM encoded RSA or Ed25519 public key")
+	}
+	return key, nil
+}
+
+// publicJWK returns the key parameters of a public key in JWK form (RFC 7517, RFC 8037)
+func publicJWK(publicKey crypto.PublicKey) (models.JWK, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return models.JWK{
+			KeyType:  "RSA",
+			Modulus:  base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			Exponent: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return models.JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	default:
+		return models.JWK{}, fmt.Errorf("unsupported JWT public key type %T", publicKey)
+	}
+}
+
+// keyThumbprint returns the RFC 7638 JWK thumbprint of a public key, used as its kid. It depends
+// only on the key, so a key keeps its kid when it moves from signing to verification only.
+func keyThumbprint(publicKey crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Required members only, in lexicographic order
+	var members interface{}
+	if jwk.KeyType == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.Exponent, jwk.KeyType, jwk.Modulus}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+	canonical, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"api/internal/repositories/auth"
+	"context"
+	"log"
+	"time"
+)
+
+// TokenPurgeService periodically deletes expired revocations, refresh tokens and sessions
+type TokenPurgeService struct {
+	tokenStore auth.TokenStore
+	interval   time.Duration
+}
+
+// NewTokenPurgeService creates a purge that runs every interval
+func NewTokenPurgeService(tokenStore auth.TokenStore, interval time.Duration) *TokenPurgeService {
+	return &TokenPurgeService{
+		tokenStore: tokenStore,
+		interval:   interval,
+	}
+}
+
+// Run purges expired rows every interval until ctx is cancelled
+func (s *TokenPurgeService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.tokenStore.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to purge expired tokens: %v", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"api/pkg"
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recoveryCodeCount is the number of recovery codes issued when two-factor sign-in is enabled
+const recoveryCodeCount = 10
+
+// totpSkew accepts codes from one time step before and after the current one, for clock drift
+const totpSkew = 1
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorService interface {
+	// Enabled reports whether the user has confirmed a TOTP enrollment
+	Enabled(ctx context.Context, userID uint) (bool, error)
+	// Enroll starts a new TOTP enrollment and returns the secret and its otpauth:// URI
+	Enroll(ctx context.Context, userID uint) (*models.TwoFactorEnrollResponse, error)
+	// Confirm enables two-factor sign-in with a first code and returns the recovery codes
+	Confirm(ctx context.Context, userID uint, req *models.TwoFactorCodeRequest) (*models.RecoveryCodesResponse, error)
+	// Disable turns two-factor sign-in off; it needs a TOTP or recovery code
+	Disable(ctx context.Context, userID uint, req *models.TwoFactorCodeRequest) error
+	// Verify exchanges an mfa_pending token and a TOTP or recovery code for the real token pair.
+	// Wrong codes count as failed sign-ins of the user's email and the client IP.
+	Verify(ctx context.Context, req *models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error)
+}
+
+type twoFactorService struct {
+	twoFactorRepo       auth.TwoFactorRepository
+	userRepo            auth.UserRepository
+	jwtService          JWTService
+	loginAttemptService LoginAttemptService
+	issuer              string
+}
+
+func NewTwoFactorService(twoFactorRepo auth.TwoFactorRepository, userRepo auth.UserRepository, jwtService JWTService, loginAttemptService LoginAttemptService, cfg config.TOTPConfig) TwoFactorService {
+	return &twoFactorService{
+		twoFactorRepo:       twoFactorRepo,
+		userRepo:            userRepo,
+		jwtService:          jwtService,
+		loginAttemptService: loginAttemptService,
+		issuer:              cfg.Issuer,
+	}
+}
+
+func (s *twoFactorService) Enabled(ctx context.Context, userID uint) (bool, error) {
+	totp, err := s.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get two-factor enrollment: %w", err)
+	}
+	return totp.ConfirmedAt != nil, nil
+}
+
+func (s *twoFactorService) Enroll(ctx context.Context, userID uint) (*models.TwoFactorEnrollResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.Enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+	if err := s.twoFactorRepo.SavePending(ctx, &models.UserTOTP{UserID: userID, Secret: secret}); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor enrollment: %w", err)
+	}
+
+	return &models.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: pkg.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+func (s *twoFactorService) Confirm(ctx context.Context, userID uint, req *models.TwoFactorCodeRequest) (*models.RecoveryCodesResponse, error) {
+	totp, err := s.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get two-factor enrollment: %w", err)
+	}
+	if totp.ConfirmedAt != nil {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	// Recovery codes do not exist yet, only a TOTP code proves the authenticator was set up
+	ok, err := s.checkTOTP(ctx, totp, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{UserID: userID, CodeHash: hashOneTimeToken(normalizeRecoveryCode(code))})
+	}
+
+	if err := s.twoFactorRepo.Confirm(ctx, userID, time.Now(), records); err != nil {
+		return nil, fmt.Errorf("failed to confirm two-factor enrollment: %w", err)
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID uint, req *models.TwoFactorCodeRequest) error {
+	totp, err := s.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorNotEnabled
+		}
+		return fmt.Errorf("failed to get two-factor enrollment: %w", err)
+	}
+	if totp.ConfirmedAt == nil {
+		return ErrTwoFactorNotEnabled
+	}
+
+	ok, err := s.checkCode(ctx, totp, req.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if err := s.twoFactorRepo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return nil
+}
+
+func (s *twoFactorService) Verify(ctx context.Context, req *models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	token, err := s.jwtService.ValidateMFAToken(ctx, req.MFAToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrTokenRevoked) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, fmt.Errorf("failed to validate mfa token: %w", err)
+	}
+	userID, err := s.jwtService.ExtractUserID(token)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// The code step shares the sign-in lockout, otherwise six digits could be guessed freely
+	retryAfter, err := s.loginAttemptService.Check(ctx, user.Email, client.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, &LockedError{RetryAfter: retryAfter}
+	}
+
+	totp, err := s.twoFactorRepo.GetByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get two-factor enrollment: %w", err)
+	}
+	if err != nil || totp.ConfirmedAt == nil {
+		// Two-factor sign-in was disabled after the password step
+		return nil, ErrInvalidMFAToken
+	}
+
+	ok, err := s.checkCode(ctx, totp, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		lock, err := s.loginAttemptService.RecordFailure(ctx, user.Email, client.IPAddress)
+		if err != nil {
+			return nil, err
+		}
+		if lock > 0 {
+			return nil, &LockedError{RetryAfter: lock}
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.loginAttemptService.RecordSuccess(ctx, user.Email); err != nil {
+		return nil, err
+	}
+
+	// The mfa_pending token is single-use
+	if err := s.jwtService.RevokeToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("failed to revoke mfa token: %w", err)
+	}
+
+	accessToken, refreshToken, expiresIn, err := s.jwtService.GenerateTokens(ctx, user, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	return &models.AuthResponse{
+		Message:      "success",
+		User:         user.ToResponse(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    expiresIn,
+	}, nil
+}
+
+func (s *twoFactorService) getUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// checkCode accepts a TOTP code or an unused recovery code
+func (s *twoFactorService) checkCode(ctx context.Context, totp *models.UserTOTP, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == pkg.TOTPDigits {
+		return s.checkTOTP(ctx, totp, code)
+	}
+
+	ok, err := s.twoFactorRepo.UseRecoveryCode(ctx, totp.UserID, hashOneTimeToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return ok, nil
+}
+
+// checkTOTP accepts a TOTP code once; a second use of the same or an older time step is refused
+func (s *twoFactorService) checkTOTP(ctx context.Context, totp *models.UserTOTP, code string) (bool, error) {
+	step, ok := pkg.ValidateTOTP(totp.Secret, strings.TrimSpace(code), time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	fresh, err := s.twoFactorRepo.MarkStepUsed(ctx, totp.UserID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP code: %w", err)
+	}
+	return fresh, nil
+}
+
+// newRecoveryCode returns a random 80-bit code formatted as "xxxx-xxxx-xxxx-xxxx"
+func newRecoveryCode() (string, error) {
+	bytes := make([]byte, 10)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(bytes))
+	return encoded[0:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:16], nil
+}
+
+// normalizeRecoveryCode makes codes match however the user typed them
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
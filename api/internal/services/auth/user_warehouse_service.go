@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/auth"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type UserWarehouseService interface {
+	GetUserWarehouses(ctx context.Context, userID uint) (*models.UserWarehousesResponse, error)
+	// AssignWarehouses replaces the warehouses of a user. The user's tokens are revoked,
+	// so the next sign-in carries the new assignment.
+	AssignWarehouses(ctx context.Context, userID uint, req *models.AssignWarehousesRequest) (*models.UserWarehousesResponse, error)
+}
+
+type userWarehouseService struct {
+	userWarehouseRepo auth.UserWarehouseRepository
+	userRepo          auth.UserRepository
+	jwtService        JWTService
+}
+
+func NewUserWarehouseService(userWarehouseRepo auth.UserWarehouseRepository, userRepo auth.UserRepository, jwtService JWTService) UserWarehouseService {
+	return &userWarehouseService{
+		userWarehouseRepo: userWarehouseRepo,
+		userRepo:          userRepo,
+		jwtService:        jwtService,
+	}
+}
+
+func (s *userWarehouseService) GetUserWarehouses(ctx context.Context, userID uint) (*models.UserWarehousesResponse, error) {
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.userWarehouseRepo.GetUserWarehouseIDs(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user warehouses: %w", err)
+	}
+	return &models.UserWarehousesResponse{UserID: userID, WarehouseIDs: ids}, nil
+}
+
+func (s *userWarehouseService) AssignWarehouses(ctx context.Context, userID uint, req *models.AssignWarehousesRequest) (*models.UserWarehousesResponse, error) {
+	ids := slices.Clone(req.WarehouseIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	existing, err := s.userWarehouseRepo.ExistingWarehouseIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get warehouses: %w", err)
+	}
+	if len(existing) != len(ids) {
+		return nil, fmt.Errorf("%w: %s", ErrWarehouseNotFound, missingWarehouses(ids, existing))
+	}
+
+	if err := s.ensureUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.userWarehouseRepo.ReplaceUserWarehouses(ctx, userID, ids); err != nil {
+		return nil, fmt.Errorf("failed to assign warehouses: %w", err)
+	}
+	if err := s.jwtService.RevokeAllTokens(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	return &models.UserWarehousesResponse{UserID: userID, WarehouseIDs: ids}, nil
+}
+
+func (s *userWarehouseService) ensureUser(ctx context.Context, userID uint) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return nil
+}
+
+// missingWarehouses lists the sorted ids not found in existing, comma separated
+func missingWarehouses(ids, existing []uint) string {
+	var missing []string
+	for _, id := range ids {
+		if !slices.Contains(existing, id) {
+			missing = append(missing, strconv.FormatUint(uint64(id), 10))
+		}
+	}
+	return strings.Join(missing, ", ")
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"api/internal/middlewares"
+	"context"
 	"database/sql"
 	"log"
 	"time"
@@ -21,18 +22,18 @@ func NewMetricsService(db *gorm.DB) *MetricsService {
 	}
 }
 
-// StartMetricsCollection starts collecting database metrics periodically
-func (s *MetricsService) StartMetricsCollection() {
+// RunMetricsCollection collects database metrics periodically until ctx is cancelled
+func (s *MetricsService) RunMetricsCollection(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second) // Collect metrics every 30 seconds
-	go func() {
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				s.collectDBMetrics()
-			}
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.collectDBMetrics()
+		case <-ctx.Done():
+			return
 		}
-	}()
+	}
 }
 
 // collectDBMetrics collects and updates database connection metrics
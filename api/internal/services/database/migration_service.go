@@ -0,0 +1,484 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMigrationLocked      = errors.New("another migration process holds the lock")
+	ErrNoDownMigration      = errors.New("migration has no down file")
+	ErrUnknownMigration     = errors.New("unknown migration version")
+	ErrInvalidMigrationName = errors.New("migration name may only contain letters, digits, spaces, '-' and '_'")
+)
+
+// DirtyMigrationError is returned when a previous run failed halfway through a migration.
+// MySQL DDL is not transactional, so the schema must be fixed by hand before running `force`.
+type DirtyMigrationError struct {
+	Version uint64
+}
+
+func (e *DirtyMigrationError) Error() string {
+	return fmt.Sprintf("database is dirty at version %d: fix the schema manually, then run `force` with the last fully applied version", e.Version)
+}
+
+var (
+	migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration is one numbered pair of up/down SQL files
+type Migration struct {
+	Version  uint64
+	Name     string
+	UpPath   string
+	DownPath string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	Missing   bool // recorded in schema_migrations but no file exists
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of the schema_migrations bookkeeping table
+type schemaMigration struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	Dirty     bool      `gorm:"not null;default:false"`
+	AppliedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+const createSchemaMigrationsSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    dirty BOOLEAN NOT NULL DEFAULT FALSE,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+)`
+
+// MigrationService applies the numbered SQL files in a migrations directory
+type MigrationService struct {
+	db          *gorm.DB
+	dir         string
+	lockTimeout time.Duration
+}
+
+// NewMigrationService creates a new migration service
+func NewMigrationService(db *gorm.DB, dir string, lockTimeout time.Duration) *MigrationService {
+	return &MigrationService{
+		db:          db,
+		dir:         dir,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Up applies pending migrations in version order; steps <= 0 applies all of them
+func (s *MigrationService) Up(steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	err = s.withLock(func(tx *gorm.DB) error {
+		records, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if _, ok := records[migration.Version]; ok {
+				continue
+			}
+
+			if err := tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name, Dirty: true}).Error; err != nil {
+				return err
+			}
+			if err := execFile(tx, migration.UpPath); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			if err := tx.Model(&schemaMigration{}).Where("version = ?", migration.Version).Update("dirty", false).Error; err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back applied migrations newest first; steps <= 0 rolls back all of them
+func (s *MigrationService) Down(steps int) ([]Migration, error) {
+	migrations, err := LoadMigrations(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[uint64]Migration, len(migrations))
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	var reverted []Migration
+	err = s.withLock(func(tx *gorm.DB) error {
+		records, err := appliedMigrations(tx)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]uint64, 0, len(records))
+		for version := range records {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, version := range versions {
+			if steps > 0 && len(reverted) == steps {
+				break
+			}
+
+			migration, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+			}
+			if migration.DownPath == "" {
+				return fmt.Errorf("%w: %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+			}
+
+			if err := tx.Model(&schemaMigration{}).Where("version = ?", version).Update("dirty", true).Error; err != nil {
+				return err
+			}
+			if err := execFile(tx, migration.DownPath); err != nil {
+				return fmt.Errorf("rollback of %d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+			if err := tx.Delete(&schemaMigration{}, "version = ?", version).Error; err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration together with its applied state
+func (s *MigrationService) Status() ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[uint64]schemaMigration{}
+	if s.db.Migrator().HasTable(&schemaMigration{}) {
+		var rows []schemaMigration
+		if err := s.db.Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			records[row.Version] = row
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, ok := records[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.Dirty = record.Dirty
+			status.AppliedAt = &appliedAt
+			delete(records, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version: record.Version, Name: record.Name, Applied: true, Dirty: record.Dirty, Missing: true, AppliedAt: &appliedAt,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Force records version as the current clean state without running any SQL:
+// later versions are forgotten and earlier ones are marked applied.
+// Use it to adopt an existing database or to recover from a dirty migration.
+func (s *MigrationService) Force(version uint64) error {
+	migrations, err := LoadMigrations(s.dir)
+	if err != nil {
+		return err
+	}
+
+	known := version == 0
+	for _, migration := range migrations {
+		if migration.Version == version {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("%w: %d", ErrUnknownMigration, version)
+	}
+
+	return s.withLock(func(tx *gorm.DB) error {
+		return tx.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&schemaMigration{}, "version > ?", version).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&schemaMigration{}).Where("version <= ?", version).Update("dirty", false).Error; err != nil {
+				return err
+			}
+
+			records, err := recordedMigrations(tx)
+			if err != nil {
+				return err
+			}
+			for _, migration := range migrations {
+				if migration.Version > version {
+					break
+				}
+				if _, ok := records[migration.Version]; ok {
+					continue
+				}
+				if err := tx.Create(&schemaMigration{Version: migration.Version, Name: migration.Name}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// withLock pins one connection, takes a MySQL advisory lock scoped to the current database
+// and makes sure the bookkeeping table exists before running fn
+func (s *MigrationService) withLock(fn func(tx *gorm.DB) error) error {
+	return s.db.Connection(func(tx *gorm.DB) error {
+		// GET_LOCK returns 1 when acquired, 0 on timeout and NULL on error
+		var acquired sql.NullInt64
+		timeout := int(s.lockTimeout.Seconds())
+		if err := tx.Raw("SELECT GET_LOCK(CONCAT(DATABASE(), '.schema_migrations'), ?)", timeout).Row().Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return ErrMigrationLocked
+		}
+		defer tx.Exec("SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.schema_migrations'))")
+
+		if err := tx.Exec(createSchemaMigrationsSQL).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		return fn(tx)
+	})
+}
+
+// appliedMigrations loads recorded versions and refuses to continue when one is dirty
+func appliedMigrations(tx *gorm.DB) (map[uint64]schemaMigration, error) {
+	records, err := recordedMigrations(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if record.Dirty {
+			return nil, &DirtyMigrationError{Version: record.Version}
+		}
+	}
+	return records, nil
+}
+
+func recordedMigrations(tx *gorm.DB) (map[uint64]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	records := make(map[uint64]schemaMigration, len(rows))
+	for _, row := range rows {
+		records[row.Version] = row
+	}
+	return records, nil
+}
+
+// execFile runs each statement of a SQL file in order
+func execFile(tx *gorm.DB, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range SplitStatements(string(content)) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadMigrations reads NNNNNN_name.up.sql / NNNNNN_name.down.sql files from dir, sorted by version
+func LoadMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		if match[3] == "up" {
+			migration.UpPath = path
+		} else {
+			migration.DownPath = path
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpPath == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// CreateMigration writes an empty up/down pair numbered after the newest existing migration
+func CreateMigration(dir, name string) (*Migration, error) {
+	slug := strings.ToLower(strings.TrimSpace(name))
+	slug = strings.NewReplacer(" ", "_", "-", "_").Replace(slug)
+	if !migrationNamePattern.MatchString(slug) {
+		return nil, ErrInvalidMigrationName
+	}
+
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var version uint64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, slug))
+	migration := &Migration{
+		Version:  version,
+		Name:     slug,
+		UpPath:   base + ".up.sql",
+		DownPath: base + ".down.sql",
+	}
+
+	if err := os.WriteFile(migration.UpPath, []byte(fmt.Sprintf("-- %s\n", name)), 0644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(migration.DownPath, []byte(fmt.Sprintf("-- Revert %s\n", name)), 0644); err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+// SplitStatements splits a SQL script on ';' while ignoring semicolons inside
+// quotes and comments. Comment-only fragments are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	hasCode := false
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" && hasCode {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			end := i + 1
+			for end < len(script) && script[end] != ch {
+				if script[end] == '\\' && ch != '`' {
+					end++
+				}
+				end++
+			}
+			if end >= len(script) {
+				end = len(script) - 1
+			}
+			current.WriteString(script[i : end+1])
+			hasCode = true
+			i = end
+		case ch == '-' && strings.HasPrefix(script[i:], "--"), ch == '#':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			i += end - 1
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+		case ch == ';':
+			flush()
+		default:
+			current.WriteByte(ch)
+			if ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r' {
+				hasCode = true
+			}
+		}
+	}
+	flush()
+
+	return statements
+}
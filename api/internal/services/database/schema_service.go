@@ -0,0 +1,123 @@
+package database
+
+import (
+	"api/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// SchemaDifference describes one mismatch between a model and its database table
+type SchemaDifference struct {
+	Table  string
+	Column string
+	Issue  string
+}
+
+func (d SchemaDifference) String() string {
+	if d.Column == "" {
+		return fmt.Sprintf("%s: %s", d.Table, d.Issue)
+	}
+	return fmt.Sprintf("%s.%s: %s", d.Table, d.Column, d.Issue)
+}
+
+// SchemaDriftError is returned when the database does not match the models
+type SchemaDriftError struct {
+	Differences []SchemaDifference
+}
+
+func (e *SchemaDriftError) Error() string {
+	lines := make([]string, 0, len(e.Differences))
+	for _, d := range e.Differences {
+		lines = append(lines, "  - "+d.String())
+	}
+	return fmt.Sprintf("database schema does not match models (run the pending migrations in database/migrations):\n%s", strings.Join(lines, "\n"))
+}
+
+// SchemaService compares the GORM models with the live database schema
+type SchemaService struct {
+	db *gorm.DB
+}
+
+// NewSchemaService creates a new schema service
+func NewSchemaService(db *gorm.DB) *SchemaService {
+	return &SchemaService{
+		db: db,
+	}
+}
+
+// Check lists the differences between every registered model and its table
+func (s *SchemaService) Check() ([]SchemaDifference, error) {
+	var differences []SchemaDifference
+	migrator := s.db.Migrator()
+
+	for _, model := range models.AllModels() {
+		stmt := &gorm.Statement{DB: s.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			differences = append(differences, SchemaDifference{Table: table, Issue: "table is missing"})
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+
+		tableColumns := make([]string, 0, len(columnTypes))
+		for _, column := range columnTypes {
+			tableColumns = append(tableColumns, column.Name())
+		}
+
+		differences = append(differences, CompareColumns(table, stmt.Schema.DBNames, tableColumns)...)
+	}
+
+	return differences, nil
+}
+
+// Verify returns a SchemaDriftError listing every difference, or nil when the schema matches
+func (s *SchemaService) Verify() error {
+	differences, err := s.Check()
+	if err != nil {
+		return err
+	}
+	if len(differences) > 0 {
+		return &SchemaDriftError{Differences: differences}
+	}
+	return nil
+}
+
+// CompareColumns reports model columns missing from the table and table columns unknown to the model
+func CompareColumns(table string, modelColumns, tableColumns []string) []SchemaDifference {
+	inModel := make(map[string]bool, len(modelColumns))
+	for _, column := range modelColumns {
+		inModel[strings.ToLower(column)] = true
+	}
+	inTable := make(map[string]bool, len(tableColumns))
+	for _, column := range tableColumns {
+		inTable[strings.ToLower(column)] = true
+	}
+
+	var differences []SchemaDifference
+	for _, column := range modelColumns {
+		if !inTable[strings.ToLower(column)] {
+			differences = append(differences, SchemaDifference{Table: table, Column: column, Issue: "missing in database"})
+		}
+	}
+	for _, column := range tableColumns {
+		if !inModel[strings.ToLower(column)] {
+			differences = append(differences, SchemaDifference{Table: table, Column: column, Issue: "not defined in model"})
+		}
+	}
+
+	sort.SliceStable(differences, func(i, j int) bool {
+		return differences[i].Column < differences[j].Column
+	})
+	return differences
+}
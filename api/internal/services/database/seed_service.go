@@ -0,0 +1,320 @@
+package database
+
+import (
+	"api/internal/models"
+	"api/pkg"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Seed sets, each one includes the previous
+const (
+	SeedSetMinimal  = "minimal"
+	SeedSetDemo     = "demo"
+	SeedSetLoadTest = "load-test"
+)
+
+const loadTestProductPrefix = "Load Test Product "
+
+var ErrUnknownSeedSet = errors.New("unknown seed set (expected minimal, demo or load-test)")
+
+// SeedOptions configures a seed run
+type SeedOptions struct {
+	Password     string // plain password for seeded users
+	LoadTestSize int    // load-test products in thousands; each gets two transactions
+}
+
+// SeedResult counts the rows created by a seed run; existing rows are not counted
+type SeedResult struct {
+	Users        int
+	Warehouses   int
+	Products     int
+	Transactions int
+}
+
+type seedUser struct {
+	Name  string
+	Email string
+	Role  string // assigned when the user has no role yet
+}
+
+type seedProduct struct {
+	Name  string
+	Price float64
+	Stock float64 // opening stock posted to the first warehouse
+}
+
+var (
+	minimalUsers      = []seedUser{{Name: "Admin User", Email: "admin@pseudo.com", Role: models.RoleAdmin}}
+	minimalWarehouses = []string{"Main Warehouse"}
+
+	demoUsers      = []seedUser{{Name: "Test User", Email: "test@pseudo.com", Role: models.RoleClerk}}
+	demoWarehouses = []string{"Secondary Warehouse"}
+	demoProducts   = []seedProduct{
+		{Name: "Product A", Price: 100, Stock: 50},
+		{Name: "Product B", Price: 200, Stock: 30},
+		{Name: "Product C", Price: 150, Stock: 75},
+	}
+)
+
+// SeedService inserts fixture data. Rows are matched by their natural key
+// (user email, warehouse and product name), so re-running a set only fills in what is missing.
+type SeedService struct {
+	db *gorm.DB
+}
+
+// NewSeedService creates a new seed service
+func NewSeedService(db *gorm.DB) *SeedService {
+	return &SeedService{
+		db: db,
+	}
+}
+
+// Run seeds the named set inside one database transaction.
+// Transactions are inserted directly; rebuild stock balances afterwards (see cmd/reconcile).
+func (s *SeedService) Run(set string, options SeedOptions) (*SeedResult, error) {
+	if set != SeedSetMinimal && set != SeedSetDemo && set != SeedSetLoadTest {
+		return nil, ErrUnknownSeedSet
+	}
+
+	hash, err := pkg.HashPassword(options.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash seed password: %w", err)
+	}
+
+	result := &SeedResult{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		users := minimalUsers
+		warehouses := minimalWarehouses
+		var products []seedProduct
+		if set != SeedSetMinimal {
+			users = append(append([]seedUser{}, users...), demoUsers...)
+			warehouses = append(append([]string{}, warehouses...), demoWarehouses...)
+			products = demoProducts
+		}
+
+		if err := seedUsers(tx, users, hash, result); err != nil {
+			return err
+		}
+		warehouseIDs, err := seedWarehouses(tx, warehouses, result)
+		if err != nil {
+			return err
+		}
+		if err := seedUserWarehouses(tx, users, warehouseIDs); err != nil {
+			return err
+		}
+
+		adminID, err := userID(tx, minimalUsers[0].Email)
+		if err != nil {
+			return err
+		}
+		if err := seedProducts(tx, products, adminID, warehouseIDs[0], result); err != nil {
+			return err
+		}
+
+		if set == SeedSetLoadTest {
+			return seedLoadTest(tx, options.LoadTestSize*1000, adminID, warehouseIDs, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// seedUsers creates missing users, gives role-less users their seed role and replaces
+// stored passwords that are not bcrypt hashes (the placeholder hash shipped in pseudo.sql
+// could never be used to sign in)
+func seedUsers(tx *gorm.DB, users []seedUser, hash string, result *SeedResult) error {
+	for _, seed := range users {
+		var user models.User
+		err := tx.Unscoped().Where("email = ?", seed.Email).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Fixture accounts have no real inbox, so they start out verified
+			verifiedAt := time.Now()
+			user = models.User{Name: seed.Name, Email: seed.Email, Password: hash, EmailVerifiedAt: &verifiedAt}
+			if err := tx.Create(&user).Error; err != nil {
+				return fmt.Errorf("failed to seed user %s: %w", seed.Email, err)
+			}
+			result.Users++
+		} else if err != nil {
+			return err
+		} else if _, err := bcrypt.Cost([]byte(user.Password)); err != nil {
+			if err := tx.Unscoped().Model(&user).Update("password", hash).Error; err != nil {
+				return fmt.Errorf("failed to reset password of %s: %w", seed.Email, err)
+			}
+		}
+
+		if err := seedUserRole(tx, &user, seed.Role); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedUserRole assigns role unless the user already has one, so roles changed by an admin survive re-seeding
+func seedUserRole(tx *gorm.DB, user *models.User, roleName string) error {
+	if tx.Model(user).Association("Roles").Count() > 0 {
+		return nil
+	}
+
+	var role models.Role
+	if err := tx.Where("name = ?", roleName).First(&role).Error; err != nil {
+		return fmt.Errorf("failed to load role %s (run migrations first): %w", roleName, err)
+	}
+	if err := tx.Model(user).Association("Roles").Append(&role); err != nil {
+		return fmt.Errorf("failed to assign role %s to %s: %w", roleName, user.Email, err)
+	}
+	return nil
+}
+
+// seedUserWarehouses assigns the seeded warehouses to non-admin users without any assignment,
+// so assignments changed by an admin survive re-seeding
+func seedUserWarehouses(tx *gorm.DB, users []seedUser, warehouseIDs []uint) error {
+	for _, seed := range users {
+		if seed.Role == models.RoleAdmin {
+			continue
+		}
+
+		id, err := userID(tx, seed.Email)
+		if err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.UserWarehouse{}).Where("user_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		for _, warehouseID := range warehouseIDs {
+			if err := tx.Create(&models.UserWarehouse{UserID: id, WarehouseID: warehouseID}).Error; err != nil {
+				return fmt.Errorf("failed to assign warehouse %d to %s: %w", warehouseID, seed.Email, err)
+			}
+		}
+	}
+	return nil
+}
+
+// seedWarehouses creates missing warehouses and returns the IDs in the given order
+func seedWarehouses(tx *gorm.DB, names []string, result *SeedResult) ([]uint, error) {
+	ids := make([]uint, 0, len(names))
+	for _, name := range names {
+		name := name
+		var warehouse models.Warehouse
+		err := tx.Unscoped().Where("name = ?", name).First(&warehouse).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			warehouse = models.Warehouse{Name: &name}
+			if err := tx.Create(&warehouse).Error; err != nil {
+				return nil, fmt.Errorf("failed to seed warehouse %s: %w", name, err)
+			}
+			result.Warehouses++
+		} else if err != nil {
+			return nil, err
+		}
+		ids = append(ids, warehouse.ID)
+	}
+	return ids, nil
+}
+
+// seedProducts creates missing products and posts their opening stock as an "in" transaction
+func seedProducts(tx *gorm.DB, products []seedProduct, userID uint, warehouseID uint, result *SeedResult) error {
+	for _, seed := range products {
+		name, price := seed.Name, seed.Price
+		var product models.Product
+		err := tx.Unscoped().Where("name = ?", name).First(&product).Error
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		stock := 0.0
+		product = models.Product{Name: &name, Price: &price, Stock: &stock}
+		if err := tx.Create(&product).Error; err != nil {
+			return fmt.Errorf("failed to seed product %s: %w", name, err)
+		}
+		result.Products++
+
+		if err := tx.Create(newSeedTransaction(userID, warehouseID, product.ID, models.TransactionTypeIn, seed.Stock)).Error; err != nil {
+			return err
+		}
+		result.Transactions++
+	}
+	return nil
+}
+
+// seedLoadTest tops load-test products up to count and gives each new product
+// a receipt and a smaller issue spread over the seeded warehouses
+func seedLoadTest(tx *gorm.DB, count int, userID uint, warehouseIDs []uint, result *SeedResult) error {
+	var existing int64
+	if err := tx.Unscoped().Model(&models.Product{}).Where("name LIKE ?", loadTestProductPrefix+"%").Count(&existing).Error; err != nil {
+		return err
+	}
+
+	// Seeding from the resume point keeps quantities reproducible between environments
+	random := rand.New(rand.NewSource(int64(existing) + 1))
+	const batchSize = 500
+
+	for start := int(existing); start < count; start += batchSize {
+		end := start + batchSize
+		if end > count {
+			end = count
+		}
+
+		products := make([]models.Product, 0, end-start)
+		for i := start; i < end; i++ {
+			name := fmt.Sprintf("%s%06d", loadTestProductPrefix, i+1)
+			price := float64(random.Intn(100000)) / 100
+			stock := 0.0
+			products = append(products, models.Product{Name: &name, Price: &price, Stock: &stock})
+		}
+		if err := tx.CreateInBatches(&products, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to seed load-test products: %w", err)
+		}
+		result.Products += len(products)
+
+		transactions := make([]*models.Transaction, 0, len(products)*2)
+		for _, product := range products {
+			warehouseID := warehouseIDs[random.Intn(len(warehouseIDs))]
+			received := float64(random.Intn(500) + 1)
+			issued := float64(random.Intn(int(received)) + 1)
+			transactions = append(transactions,
+				newSeedTransaction(userID, warehouseID, product.ID, models.TransactionTypeIn, received),
+				newSeedTransaction(userID, warehouseID, product.ID, models.TransactionTypeOut, issued),
+			)
+		}
+		if err := tx.CreateInBatches(transactions, batchSize).Error; err != nil {
+			return fmt.Errorf("failed to seed load-test transactions: %w", err)
+		}
+		result.Transactions += len(transactions)
+	}
+	return nil
+}
+
+func newSeedTransaction(userID, warehouseID, productID uint, trxType string, quantity float64) *models.Transaction {
+	return &models.Transaction{
+		UserID:      &userID,
+		WarehouseID: &warehouseID,
+		ProductID:   &productID,
+		Type:        &trxType,
+		Quantity:    &quantity,
+	}
+}
+
+func userID(tx *gorm.DB, email string) (uint, error) {
+	var user models.User
+	if err := tx.Unscoped().Select("id").Where("email = ?", email).First(&user).Error; err != nil {
+		return 0, fmt.Errorf("failed to load seed user %s: %w", email, err)
+	}
+	return user.ID, nil
+}
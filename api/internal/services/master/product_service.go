@@ -0,0 +1,210 @@
+package master
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/master"
+	"api/pkg"
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrProductNotDeleted = errors.New("product is not deleted")
+	// ErrWarehouseAccessDenied is returned when the product is only stocked in warehouses outside the caller's scope
+	ErrWarehouseAccessDenied = errors.New("access to this warehouse is not allowed")
+)
+
+type ProductService interface {
+	// List returns the products stocked in the scope's warehouses, plus products not stocked anywhere yet
+	List(ctx context.Context, scope models.WarehouseScope, query *models.ListQuery) (*models.ProductListResponse, error)
+	GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.ProductResponse, error)
+	Create(ctx context.Context, req *models.ProductRequest, image *multipart.FileHeader) (*models.ProductResponse, error)
+	Update(ctx context.Context, scope models.WarehouseScope, id uint, req *models.ProductRequest, image *multipart.FileHeader) (*models.ProductResponse, error)
+	Delete(ctx context.Context, scope models.WarehouseScope, id uint) error
+	Restore(ctx context.Context, scope models.WarehouseScope, id uint) (*models.ProductResponse, error)
+}
+
+type productService struct {
+	productRepo  master.ProductRepository
+	imageDir     string
+	maxImageSize int64
+}
+
+// NewProductService creates a product service storing uploaded images of up to maxImageSize bytes in imageDir
+func NewProductService(productRepo master.ProductRepository, imageDir string, maxImageSize int64) ProductService {
+	return &productService{
+		productRepo:  productRepo,
+		imageDir:     imageDir,
+		maxImageSize: maxImageSize,
+	}
+}
+
+func (s *productService) List(ctx context.Context, scope models.WarehouseScope, query *models.ListQuery) (*models.ProductListResponse, error) {
+	query.Normalize()
+
+	products, total, err := s.productRepo.List(ctx, scope, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+
+	items := make([]models.ProductResponse, 0, len(products))
+	for i := range products {
+		items = append(items, products[i].ToResponse())
+	}
+
+	return &models.ProductListResponse{
+		Items: items,
+		Meta:  models.NewPaginationMeta(query, total),
+	}, nil
+}
+
+func (s *productService) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.ProductResponse, error) {
+	product, err := s.find(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
+func (s *productService) Create(ctx context.Context, req *models.ProductRequest, image *multipart.FileHeader) (*models.ProductResponse, error) {
+	stock := 0.0
+	product := &models.Product{
+		Name:  &req.Name,
+		Price: req.Price,
+		Stock: &stock,
+	}
+
+	if image != nil {
+		name, err := pkg.SaveImage(image, s.imageDir, s.maxImageSize)
+		if err != nil {
+			return nil, err
+		}
+		product.Image = &name
+	}
+
+	if err := s.productRepo.Create(ctx, product); err != nil {
+		s.removeImage(product.Image)
+		return nil, fmt.Errorf("failed to create product: %w", err)
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
+func (s *productService) Update(ctx context.Context, scope models.WarehouseScope, id uint, req *models.ProductRequest, image *multipart.FileHeader) (*models.ProductResponse, error) {
+	product, err := s.find(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldImage := product.Image
+	product.Name = &req.Name
+	product.Price = req.Price
+
+	if image != nil {
+		name, err := pkg.SaveImage(image, s.imageDir, s.maxImageSize)
+		if err != nil {
+			return nil, err
+		}
+		product.Image = &name
+	} else if req.RemoveImage {
+		product.Image = nil
+	}
+
+	if err := s.productRepo.Update(ctx, product); err != nil {
+		// Keep the previous image, drop the one we just stored
+		if product.Image != oldImage {
+			s.removeImage(product.Image)
+		}
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	if product.Image != oldImage {
+		s.removeImage(oldImage)
+	}
+
+	response := product.ToResponse()
+	return &response, nil
+}
+
+func (s *productService) Delete(ctx context.Context, scope models.WarehouseScope, id uint) error {
+	product, err := s.find(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+
+	// The row loses its image together with the soft delete, so a restored product has no image
+	if err := s.productRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	s.removeImage(product.Image)
+	return nil
+}
+
+func (s *productService) Restore(ctx context.Context, scope models.WarehouseScope, id uint) (*models.ProductResponse, error) {
+	product, err := s.productRepo.GetByIDWithTrashed(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if !product.DeletedAt.Valid {
+		return nil, ErrProductNotDeleted
+	}
+	if err := s.checkAccess(ctx, scope, id); err != nil {
+		return nil, err
+	}
+
+	if err := s.productRepo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to restore product: %w", err)
+	}
+
+	return s.GetByID(ctx, scope, id)
+}
+
+// find returns a non-deleted product in the scope, ErrProductNotFound or ErrWarehouseAccessDenied
+func (s *productService) find(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Product, error) {
+	product, err := s.productRepo.GetByID(ctx, scope, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if err := s.checkAccess(ctx, scope, id); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// checkAccess denies products stocked only in warehouses outside the scope
+func (s *productService) checkAccess(ctx context.Context, scope models.WarehouseScope, id uint) error {
+	if scope.All {
+		return nil
+	}
+	warehouseIDs, err := s.productRepo.StockWarehouseIDs(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get product warehouses: %w", err)
+	}
+	if len(warehouseIDs) > 0 && !scope.AllowsAny(warehouseIDs...) {
+		return ErrWarehouseAccessDenied
+	}
+	return nil
+}
+
+// removeImage deletes an image file, logging instead of failing the request
+func (s *productService) removeImage(name *string) {
+	if name == nil {
+		return
+	}
+	pkg.LogError(pkg.DeleteImage(s.imageDir, *name), "delete product image")
+}
@@ -0,0 +1,134 @@
+package master
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/master"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWarehouseNotFound   = errors.New("warehouse not found")
+	ErrWarehouseNotDeleted = errors.New("warehouse is not deleted")
+)
+
+type WarehouseService interface {
+	List(ctx context.Context, query *models.ListQuery) (*models.WarehouseListResponse, error)
+	GetByID(ctx context.Context, id uint) (*models.WarehouseResponse, error)
+	Create(ctx context.Context, req *models.WarehouseRequest) (*models.WarehouseResponse, error)
+	Update(ctx context.Context, id uint, req *models.WarehouseRequest) (*models.WarehouseResponse, error)
+	Delete(ctx context.Context, id uint) error
+	Restore(ctx context.Context, id uint) (*models.WarehouseResponse, error)
+}
+
+type warehouseService struct {
+	warehouseRepo master.WarehouseRepository
+}
+
+func NewWarehouseService(warehouseRepo master.WarehouseRepository) WarehouseService {
+	return &warehouseService{
+		warehouseRepo: warehouseRepo,
+	}
+}
+
+func (s *warehouseService) List(ctx context.Context, query *models.ListQuery) (*models.WarehouseListResponse, error) {
+	query.Normalize()
+
+	warehouses, total, err := s.warehouseRepo.List(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list warehouses: %w", err)
+	}
+
+	items := make([]models.WarehouseResponse, 0, len(warehouses))
+	for i := range warehouses {
+		items = append(items, warehouses[i].ToResponse())
+	}
+
+	return &models.WarehouseListResponse{
+		Items: items,
+		Meta:  models.NewPaginationMeta(query, total),
+	}, nil
+}
+
+func (s *warehouseService) GetByID(ctx context.Context, id uint) (*models.WarehouseResponse, error) {
+	warehouse, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := warehouse.ToResponse()
+	return &response, nil
+}
+
+func (s *warehouseService) Create(ctx context.Context, req *models.WarehouseRequest) (*models.WarehouseResponse, error) {
+	warehouse := &models.Warehouse{
+		Name: &req.Name,
+	}
+
+	if err := s.warehouseRepo.Create(ctx, warehouse); err != nil {
+		return nil, fmt.Errorf("failed to create warehouse: %w", err)
+	}
+
+	response := warehouse.ToResponse()
+	return &response, nil
+}
+
+func (s *warehouseService) Update(ctx context.Context, id uint, req *models.WarehouseRequest) (*models.WarehouseResponse, error) {
+	warehouse, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouse.Name = &req.Name
+	if err := s.warehouseRepo.Update(ctx, warehouse); err != nil {
+		return nil, fmt.Errorf("failed to update warehouse: %w", err)
+	}
+
+	response := warehouse.ToResponse()
+	return &response, nil
+}
+
+func (s *warehouseService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.find(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.warehouseRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete warehouse: %w", err)
+	}
+	return nil
+}
+
+func (s *warehouseService) Restore(ctx context.Context, id uint) (*models.WarehouseResponse, error) {
+	warehouse, err := s.warehouseRepo.GetByIDWithTrashed(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWarehouseNotFound
+		}
+		return nil, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+	if !warehouse.DeletedAt.Valid {
+		return nil, ErrWarehouseNotDeleted
+	}
+
+	if err := s.warehouseRepo.Restore(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to restore warehouse: %w", err)
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// find returns a non-deleted warehouse or ErrWarehouseNotFound
+func (s *warehouseService) find(ctx context.Context, id uint) (*models.Warehouse, error) {
+	warehouse, err := s.warehouseRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWarehouseNotFound
+		}
+		return nil, fmt.Errorf("failed to get warehouse: %w", err)
+	}
+	return warehouse, nil
+}
@@ -0,0 +1,39 @@
+package transaction
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTransactionNotFound        = errors.New("transaction not found")
+	ErrTransactionAlreadyReversed = errors.New("transaction has already been reversed")
+	ErrTransactionIsReversal      = errors.New("a reversal transaction cannot be reversed")
+	ErrWarehouseNotFound          = errors.New("warehouse not found")
+	ErrProductNotFound            = errors.New("product not found")
+	ErrTransactionPartOfTransfer  = errors.New("transaction belongs to a transfer and cannot be changed on its own")
+	ErrTransferNotFound           = errors.New("transfer not found")
+	ErrTransferNotInTransit       = errors.New("transfer is not in transit")
+	ErrReceivedQuantityTooLarge   = errors.New("received quantity exceeds transferred quantity")
+	ErrInvalidQuantity            = errors.New("quantity must be at least 0.01")
+	ErrInsufficientStock          = errors.New("insufficient stock")
+	ErrWarehouseAccessDenied      = errors.New("access to this warehouse is not allowed")
+)
+
+// InsufficientStockError is returned when a movement would take a product's stock in a warehouse below zero.
+// It matches ErrInsufficientStock with errors.Is.
+type InsufficientStockError struct {
+	ProductID   uint
+	WarehouseID uint
+	Available   float64
+	Requested   float64
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %d in warehouse %d: available %.2f, requested %.2f",
+		e.ProductID, e.WarehouseID, e.Available, e.Requested)
+}
+
+func (e *InsufficientStockError) Is(target error) bool {
+	return target == ErrInsufficientStock
+}
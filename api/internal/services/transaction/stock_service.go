@@ -0,0 +1,143 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/transaction"
+	"context"
+	"fmt"
+)
+
+type StockService interface {
+	// List returns the balances of the scope's warehouses
+	List(ctx context.Context, scope models.WarehouseScope, query *models.StockQuery) (*models.StockListResponse, error)
+	// Reconcile rebuilds stock balances from the transaction ledger; with dryRun it only reports differences
+	Reconcile(ctx context.Context, dryRun bool) (*models.StockReconcileResult, error)
+}
+
+type stockService struct {
+	stockRepo transaction.StockRepository
+}
+
+func NewStockService(stockRepo transaction.StockRepository) StockService {
+	return &stockService{
+		stockRepo: stockRepo,
+	}
+}
+
+func (s *stockService) List(ctx context.Context, scope models.WarehouseScope, query *models.StockQuery) (*models.StockListResponse, error) {
+	if query.WarehouseID != 0 && !scope.Allows(query.WarehouseID) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	paging := query.Paging()
+
+	balances, total, err := s.stockRepo.List(ctx, scope, query, paging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock balances: %w", err)
+	}
+
+	items := make([]models.StockBalanceResponse, 0, len(balances))
+	for i := range balances {
+		items = append(items, balances[i].ToResponse())
+	}
+
+	return &models.StockListResponse{
+		Items: items,
+		Meta:  models.NewPaginationMeta(paging, total),
+	}, nil
+}
+
+func (s *stockService) Reconcile(ctx context.Context, dryRun bool) (*models.StockReconcileResult, error) {
+	result := &models.StockReconcileResult{}
+
+	err := s.stockRepo.Atomic(ctx, func(repo transaction.StockRepository) error {
+		// Every posting locks its product first (see applyMovement), so holding all product locks
+		// keeps new movements out until the rebuilt balances are committed
+		if err := repo.LockProducts(ctx); err != nil {
+			return fmt.Errorf("failed to lock products: %w", err)
+		}
+		ledger, err := repo.LedgerTotals(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to sum transaction ledger: %w", err)
+		}
+		current, err := repo.All(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load stock balances: %w", err)
+		}
+
+		result.Balances = len(ledger)
+		result.Differences = diffBalances(current, ledger)
+		for _, total := range ledger {
+			if roundQuantity(total.Quantity) < 0 {
+				result.Negative = append(result.Negative, models.StockDifference{
+					ProductID:   total.ProductID,
+					WarehouseID: total.WarehouseID,
+					Ledger:      roundQuantity(total.Quantity),
+				})
+			}
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		balances := make([]models.StockBalance, 0, len(ledger))
+		for _, total := range ledger {
+			balances = append(balances, models.StockBalance{
+				ProductID:   total.ProductID,
+				WarehouseID: total.WarehouseID,
+				Quantity:    roundQuantity(total.Quantity),
+			})
+		}
+		if err := repo.ReplaceAll(ctx, balances); err != nil {
+			return fmt.Errorf("failed to replace stock balances: %w", err)
+		}
+		if err := repo.SyncAllProductStock(ctx); err != nil {
+			return fmt.Errorf("failed to sync product stock: %w", err)
+		}
+
+		result.Applied = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// diffBalances lists every (product, warehouse) pair whose recorded balance differs from the ledger
+func diffBalances(current, ledger []models.StockBalance) []models.StockDifference {
+	type key struct{ productID, warehouseID uint }
+
+	recorded := make(map[key]float64, len(current))
+	for _, balance := range current {
+		recorded[key{balance.ProductID, balance.WarehouseID}] = balance.Quantity
+	}
+
+	differences := []models.StockDifference{}
+	for _, total := range ledger {
+		k := key{total.ProductID, total.WarehouseID}
+		quantity := roundQuantity(total.Quantity)
+		if roundQuantity(recorded[k]) != quantity {
+			differences = append(differences, models.StockDifference{
+				ProductID:   k.productID,
+				WarehouseID: k.warehouseID,
+				Recorded:    recorded[k],
+				Ledger:      quantity,
+			})
+		}
+		delete(recorded, k)
+	}
+
+	// Balances without any ledger movement should be zero
+	for k, quantity := range recorded {
+		if roundQuantity(quantity) != 0 {
+			differences = append(differences, models.StockDifference{
+				ProductID:   k.productID,
+				WarehouseID: k.warehouseID,
+				Recorded:    quantity,
+			})
+		}
+	}
+	return differences
+}
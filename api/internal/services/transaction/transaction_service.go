@@ -0,0 +1,298 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/transaction"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type TransactionService interface {
+	// List returns the transactions of the scope's warehouses
+	List(ctx context.Context, scope models.WarehouseScope, query *models.TransactionListQuery) (*models.TransactionListResponse, error)
+	GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransactionResponse, error)
+	Create(ctx context.Context, scope models.WarehouseScope, userID uint, req *models.TransactionRequest) (*models.TransactionResponse, error)
+	Reverse(ctx context.Context, scope models.WarehouseScope, userID uint, id uint) (*models.TransactionResponse, error)
+	Delete(ctx context.Context, scope models.WarehouseScope, id uint) error
+}
+
+type transactionService struct {
+	transactionRepo transaction.TransactionRepository
+}
+
+func NewTransactionService(transactionRepo transaction.TransactionRepository) TransactionService {
+	return &transactionService{
+		transactionRepo: transactionRepo,
+	}
+}
+
+func (s *transactionService) List(ctx context.Context, scope models.WarehouseScope, query *models.TransactionListQuery) (*models.TransactionListResponse, error) {
+	if query.WarehouseID != 0 && !scope.Allows(query.WarehouseID) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	query.Normalize()
+
+	transactions, total, err := s.transactionRepo.List(ctx, scope, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	items := make([]models.TransactionResponse, 0, len(transactions))
+	for i := range transactions {
+		items = append(items, transactions[i].ToResponse())
+	}
+
+	return &models.TransactionListResponse{
+		Items: items,
+		Meta:  models.NewPaginationMeta(&query.ListQuery, total),
+	}, nil
+}
+
+func (s *transactionService) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransactionResponse, error) {
+	trx, err := s.find(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.Allows(derefID(trx.WarehouseID)) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	response := trx.ToResponse()
+	return &response, nil
+}
+
+// Create records a stock movement and adjusts the product stock in the same database transaction
+func (s *transactionService) Create(ctx context.Context, scope models.WarehouseScope, userID uint, req *models.TransactionRequest) (*models.TransactionResponse, error) {
+	quantity := roundQuantity(req.Quantity)
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if !scope.Allows(req.WarehouseID) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	exists, err := s.transactionRepo.WarehouseExists(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check warehouse: %w", err)
+	}
+	if !exists {
+		return nil, ErrWarehouseNotFound
+	}
+
+	trxType := req.Type
+	trx := &models.Transaction{
+		UserID:      &userID,
+		WarehouseID: &req.WarehouseID,
+		ProductID:   &req.ProductID,
+		Type:        &trxType,
+		Quantity:    &quantity,
+	}
+
+	err = s.transactionRepo.Atomic(ctx, func(repo transaction.TransactionRepository) error {
+		if err := applyMovement(ctx, repo, req.ProductID, req.WarehouseID, trxType, quantity); err != nil {
+			return err
+		}
+		return repo.Create(ctx, trx)
+	})
+	if err != nil {
+		return nil, wrapMovementError(err, "failed to create transaction")
+	}
+
+	return s.response(ctx, scope, trx.ID)
+}
+
+// Reverse posts a compensating movement of the opposite type, restoring the stock the original changed
+func (s *transactionService) Reverse(ctx context.Context, scope models.WarehouseScope, userID uint, id uint) (*models.TransactionResponse, error) {
+	var reversal *models.Transaction
+
+	err := s.transactionRepo.Atomic(ctx, func(repo transaction.TransactionRepository) error {
+		original, err := repo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !scope.Allows(derefID(original.WarehouseID)) {
+			return ErrWarehouseAccessDenied
+		}
+		if original.ReversalOf != nil {
+			return ErrTransactionIsReversal
+		}
+		if original.TransferID != nil {
+			return ErrTransactionPartOfTransfer
+		}
+
+		reversed, err := repo.HasReversal(ctx, id)
+		if err != nil {
+			return err
+		}
+		if reversed {
+			return ErrTransactionAlreadyReversed
+		}
+
+		reversalType := oppositeType(*original.Type)
+		if err := applyMovement(ctx, repo, derefID(original.ProductID), derefID(original.WarehouseID), reversalType, *original.Quantity); err != nil {
+			return err
+		}
+
+		reversal = &models.Transaction{
+			UserID:      &userID,
+			WarehouseID: original.WarehouseID,
+			ProductID:   original.ProductID,
+			Type:        &reversalType,
+			Quantity:    original.Quantity,
+			ReversalOf:  &original.ID,
+		}
+		return repo.Create(ctx, reversal)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, wrapMovementError(err, "failed to reverse transaction")
+	}
+
+	return s.response(ctx, scope, reversal.ID)
+}
+
+// Delete soft-deletes a transaction and undoes its effect on the product stock
+func (s *transactionService) Delete(ctx context.Context, scope models.WarehouseScope, id uint) error {
+	err := s.transactionRepo.Atomic(ctx, func(repo transaction.TransactionRepository) error {
+		trx, err := repo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !scope.Allows(derefID(trx.WarehouseID)) {
+			return ErrWarehouseAccessDenied
+		}
+		if trx.TransferID != nil {
+			return ErrTransactionPartOfTransfer
+		}
+
+		// Deleting a reversed movement would restore its stock a second time
+		reversed, err := repo.HasReversal(ctx, id)
+		if err != nil {
+			return err
+		}
+		if reversed {
+			return ErrTransactionAlreadyReversed
+		}
+
+		if err := applyMovement(ctx, repo, derefID(trx.ProductID), derefID(trx.WarehouseID), oppositeType(*trx.Type), *trx.Quantity); err != nil {
+			return err
+		}
+		return repo.Delete(ctx, id)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransactionNotFound
+		}
+		return wrapMovementError(err, "failed to delete transaction")
+	}
+	return nil
+}
+
+// find returns a non-deleted transaction or ErrTransactionNotFound
+func (s *transactionService) find(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transaction, error) {
+	trx, err := s.transactionRepo.GetByID(ctx, scope, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return trx, nil
+}
+
+// response reloads a transaction just written by the caller, with its relations
+func (s *transactionService) response(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransactionResponse, error) {
+	trx, err := s.find(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := trx.ToResponse()
+	return &response, nil
+}
+
+// applyMovement adds (in) or subtracts (out) quantity from the product's balance in a warehouse,
+// then refreshes Product.Stock as the total across warehouses.
+// The product row lock serializes concurrent movements of the same product.
+func applyMovement(ctx context.Context, repo transaction.TransactionRepository, productID, warehouseID uint, trxType string, quantity float64) error {
+	if _, err := repo.LockProduct(ctx, productID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrProductNotFound
+		}
+		return err
+	}
+	if warehouseID == 0 {
+		return ErrWarehouseNotFound
+	}
+
+	balance, err := repo.LockBalance(ctx, productID, warehouseID)
+	if err != nil {
+		return err
+	}
+
+	available := balance.Quantity
+	stock := available + quantity
+	if trxType == models.TransactionTypeOut {
+		stock = available - quantity
+	}
+	stock = roundQuantity(stock)
+
+	if stock < 0 {
+		return &InsufficientStockError{
+			ProductID:   productID,
+			WarehouseID: warehouseID,
+			Available:   available,
+			Requested:   quantity,
+		}
+	}
+
+	balance.Quantity = stock
+	if err := repo.SaveBalance(ctx, balance); err != nil {
+		return err
+	}
+	return repo.SyncProductStock(ctx, productID)
+}
+
+// wrapMovementError keeps domain errors as-is and wraps database errors with context
+func wrapMovementError(err error, message string) error {
+	if errors.Is(err, ErrInsufficientStock) ||
+		errors.Is(err, ErrProductNotFound) ||
+		errors.Is(err, ErrWarehouseNotFound) ||
+		errors.Is(err, ErrWarehouseAccessDenied) ||
+		errors.Is(err, ErrTransactionAlreadyReversed) ||
+		errors.Is(err, ErrTransactionIsReversal) ||
+		errors.Is(err, ErrTransactionPartOfTransfer) ||
+		errors.Is(err, ErrTransferNotInTransit) ||
+		errors.Is(err, ErrReceivedQuantityTooLarge) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+// oppositeType returns the movement type that undoes trxType
+func oppositeType(trxType string) string {
+	if trxType == models.TransactionTypeIn {
+		return models.TransactionTypeOut
+	}
+	return models.TransactionTypeIn
+}
+
+// derefID returns the value of an optional foreign key, or 0 when it is not set
+func derefID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
+
+// roundQuantity rounds to the two decimals stored by decimal(20,2) columns
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*100) / 100
+}
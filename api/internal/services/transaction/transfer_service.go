@@ -0,0 +1,212 @@
+package transaction
+
+import (
+	"api/internal/models"
+	"api/internal/repositories/transaction"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TransferService interface {
+	// List returns the transfers leaving or entering the scope's warehouses
+	List(ctx context.Context, scope models.WarehouseScope, query *models.TransferListQuery) (*models.TransferListResponse, error)
+	GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransferResponse, error)
+	// Create posts the source "out" leg and, unless the transfer is in transit, the destination "in" leg.
+	// The source warehouse must be in the scope.
+	Create(ctx context.Context, scope models.WarehouseScope, userID uint, req *models.TransferRequest) (*models.TransferResponse, error)
+	// Receive confirms arrival of an in-transit transfer with the full or a partial quantity.
+	// The destination warehouse must be in the scope.
+	Receive(ctx context.Context, scope models.WarehouseScope, userID uint, id uint, req *models.TransferReceiveRequest) (*models.TransferResponse, error)
+}
+
+type transferService struct {
+	transferRepo transaction.TransferRepository
+}
+
+func NewTransferService(transferRepo transaction.TransferRepository) TransferService {
+	return &transferService{
+		transferRepo: transferRepo,
+	}
+}
+
+func (s *transferService) List(ctx context.Context, scope models.WarehouseScope, query *models.TransferListQuery) (*models.TransferListResponse, error) {
+	if query.WarehouseID != 0 && !scope.Allows(query.WarehouseID) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	paging := query.Paging()
+
+	transfers, total, err := s.transferRepo.List(ctx, scope, query, paging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+
+	items := make([]models.TransferResponse, 0, len(transfers))
+	for i := range transfers {
+		items = append(items, transfers[i].ToResponse())
+	}
+
+	return &models.TransferListResponse{
+		Items: items,
+		Meta:  models.NewPaginationMeta(paging, total),
+	}, nil
+}
+
+func (s *transferService) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransferResponse, error) {
+	transfer, err := s.find(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if !scope.AllowsAny(transfer.FromWarehouseID, transfer.ToWarehouseID) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	response := transfer.ToResponse()
+	return &response, nil
+}
+
+func (s *transferService) Create(ctx context.Context, scope models.WarehouseScope, userID uint, req *models.TransferRequest) (*models.TransferResponse, error) {
+	quantity := roundQuantity(req.Quantity)
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if !scope.Allows(req.FromWarehouseID) {
+		return nil, ErrWarehouseAccessDenied
+	}
+
+	var transfer *models.Transfer
+
+	err := s.transferRepo.Atomic(ctx, func(repo transaction.TransferRepository) error {
+		movements := repo.Movements()
+
+		for _, warehouseID := range []uint{req.FromWarehouseID, req.ToWarehouseID} {
+			exists, err := movements.WarehouseExists(ctx, warehouseID)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return ErrWarehouseNotFound
+			}
+		}
+
+		transfer = &models.Transfer{
+			UserID:          &userID,
+			ProductID:       req.ProductID,
+			FromWarehouseID: req.FromWarehouseID,
+			ToWarehouseID:   req.ToWarehouseID,
+			Quantity:        quantity,
+			Status:          models.TransferStatusInTransit,
+		}
+		if err := repo.Create(ctx, transfer); err != nil {
+			return err
+		}
+
+		if err := postTransferLeg(ctx, movements, transfer, userID, models.TransactionTypeOut, quantity); err != nil {
+			return err
+		}
+
+		if req.InTransit {
+			return nil
+		}
+		return receiveTransfer(ctx, repo, transfer, userID, quantity)
+	})
+	if err != nil {
+		return nil, wrapMovementError(err, "failed to create transfer")
+	}
+
+	return s.response(ctx, scope, transfer.ID)
+}
+
+func (s *transferService) Receive(ctx context.Context, scope models.WarehouseScope, userID uint, id uint, req *models.TransferReceiveRequest) (*models.TransferResponse, error) {
+	err := s.transferRepo.Atomic(ctx, func(repo transaction.TransferRepository) error {
+		transfer, err := repo.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !scope.Allows(transfer.ToWarehouseID) {
+			return ErrWarehouseAccessDenied
+		}
+		if transfer.Status != models.TransferStatusInTransit {
+			return ErrTransferNotInTransit
+		}
+
+		received := roundQuantity(*req.Quantity)
+		if received > transfer.Quantity {
+			return ErrReceivedQuantityTooLarge
+		}
+		return receiveTransfer(ctx, repo, transfer, userID, received)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, wrapMovementError(err, "failed to receive transfer")
+	}
+
+	return s.response(ctx, scope, id)
+}
+
+// find returns a transfer or ErrTransferNotFound
+func (s *transferService) find(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transfer, error) {
+	transfer, err := s.transferRepo.GetByID(ctx, scope, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// response reloads a transfer just written by the caller, with its relations
+func (s *transferService) response(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransferResponse, error) {
+	transfer, err := s.find(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := transfer.ToResponse()
+	return &response, nil
+}
+
+// receiveTransfer posts the destination "in" leg for the received quantity and completes the transfer.
+// Any shortfall against the sent quantity stays recorded as Quantity - ReceivedQuantity.
+func receiveTransfer(ctx context.Context, repo transaction.TransferRepository, transfer *models.Transfer, userID uint, received float64) error {
+	if received > 0 {
+		if err := postTransferLeg(ctx, repo.Movements(), transfer, userID, models.TransactionTypeIn, received); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	transfer.ReceivedQuantity = &received
+	transfer.ReceivedBy = &userID
+	transfer.ReceivedAt = &now
+	transfer.Status = models.TransferStatusCompleted
+	return repo.Update(ctx, transfer)
+}
+
+// postTransferLeg applies one side of a transfer to the warehouse balance and records its transaction row
+func postTransferLeg(ctx context.Context, movements transaction.TransactionRepository, transfer *models.Transfer, userID uint, trxType string, quantity float64) error {
+	warehouseID := transfer.FromWarehouseID
+	if trxType == models.TransactionTypeIn {
+		warehouseID = transfer.ToWarehouseID
+	}
+
+	if err := applyMovement(ctx, movements, transfer.ProductID, warehouseID, trxType, quantity); err != nil {
+		return err
+	}
+
+	return movements.Create(ctx, &models.Transaction{
+		UserID:      &userID,
+		WarehouseID: &warehouseID,
+		ProductID:   &transfer.ProductID,
+		Type:        &trxType,
+		Quantity:    &quantity,
+		TransferID:  &transfer.ID,
+	})
+}
@@ -0,0 +1,220 @@
+package auth_test
+
+import (
+	"api/internal/middlewares"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	args := m.Called(key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) ListByUser(ctx context.Context, userID uint) ([]models.APIKey, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Delete(ctx context.Context, userID, id uint) (bool, error) {
+	args := m.Called(userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id uint, usedAt, since time.Time) error {
+	args := m.Called(id, usedAt, since)
+	return args.Error(0)
+}
+
+// newClerkRoleService returns a role service where clerks may read products and write transactions
+func newClerkRoleService() auth.RoleService {
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("RolePermissions").Return(map[string][]string{
+		models.RoleClerk: {models.PermissionProductsRead, models.PermissionTransactionsWrite},
+	}, nil)
+	return auth.NewRoleService(roleRepo, new(MockUserRepository), new(MockJWTService))
+}
+
+func newClerk() *models.User {
+	verifiedAt := time.Now()
+	return &models.User{ID: 7, Email: "clerk@example.com", EmailVerifiedAt: &verifiedAt, Roles: []models.Role{{Name: models.RoleClerk}}}
+}
+
+func TestAPIKeyService_Create_Success(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	apiKeyService := auth.NewAPIKeyService(mockAPIKeyRepo, mockUserRepo, newClerkRoleService())
+
+	mockUserRepo.On("GetByID", uint(7)).Return(newClerk(), nil)
+	var stored *models.APIKey
+	mockAPIKeyRepo.On("Create", mock.AnythingOfType("*models.APIKey")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.APIKey)
+	}).Return(nil)
+
+	// Act
+	response, err := apiKeyService.Create(context.Background(), 7, &models.CreateAPIKeyRequest{
+		Name:          "ERP sync",
+		Scopes:        []string{models.PermissionProductsRead, models.PermissionProductsRead},
+		ExpiresInDays: 30,
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(response.Key, "pk_"))
+	assert.Equal(t, response.Key[:len(response.Prefix)], response.Prefix)
+	assert.Equal(t, []string{models.PermissionProductsRead}, response.Scopes)
+	assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), response.ExpiresAt, time.Minute)
+
+	// Only the hash of the key is stored
+	assert.Equal(t, sha256Hex(response.Key), stored.KeyHash)
+}
+
+func TestAPIKeyService_Create_ScopeNotGranted(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	apiKeyService := auth.NewAPIKeyService(mockAPIKeyRepo, mockUserRepo, newClerkRoleService())
+
+	mockUserRepo.On("GetByID", uint(7)).Return(newClerk(), nil)
+
+	// Act
+	response, err := apiKeyService.Create(context.Background(), 7, &models.CreateAPIKeyRequest{
+		Name:   "ERP sync",
+		Scopes: []string{models.PermissionProductsRead, models.PermissionUsersManage},
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrScopeNotGranted)
+	assert.Contains(t, err.Error(), models.PermissionUsersManage)
+	assert.Nil(t, response)
+	mockAPIKeyRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAPIKeyService_Authenticate_RejectsUnknownAndExpiredKeys(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	apiKeyService := auth.NewAPIKeyService(mockAPIKeyRepo, new(MockUserRepository), newClerkRoleService())
+
+	mockAPIKeyRepo.On("GetByHash", sha256Hex("pk_unknown")).Return(nil, gorm.ErrRecordNotFound)
+	mockAPIKeyRepo.On("GetByHash", sha256Hex("pk_expired")).Return(&models.APIKey{ID: 1, UserID: 7, ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+	for _, rawKey := range []string{"not-a-key", "pk_unknown", "pk_expired"} {
+		// Act
+		key, user, err := apiKeyService.Authenticate(context.Background(), rawKey)
+
+		// Assert
+		assert.ErrorIs(t, err, auth.ErrInvalidAPIKey, rawKey)
+		assert.Nil(t, key)
+		assert.Nil(t, user)
+	}
+	mockAPIKeyRepo.AssertNotCalled(t, "TouchLastUsed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// setupAPIKeyApp serves a route behind JWTOrAPIKeyAuth that needs permission and echoes the user ID
+func setupAPIKeyApp(t *testing.T, apiKeyService auth.APIKeyService, permission string) *fiber.App {
+	t.Helper()
+	jwtService := newTestJWTService(t)
+	roleService := newClerkRoleService()
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, roleService, auth.VerificationPolicyReadOnly)
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(apiKeyService, roleService, auth.VerificationPolicyReadOnly)
+
+	app := fiber.New()
+	app.Get("/resource", middlewares.JWTOrAPIKeyAuth(jwtMiddleware, apiKeyMiddleware), middlewares.RequirePermission(permission), func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("userID").(string))
+	})
+	return app
+}
+
+func TestAPIKeyAuth_LimitsPermissionsToScopes(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	mockUserRepo := new(MockUserRepository)
+	apiKeyService := auth.NewAPIKeyService(mockAPIKeyRepo, mockUserRepo, newClerkRoleService())
+
+	rawKey := "pk_readonly"
+	mockAPIKeyRepo.On("GetByHash", sha256Hex(rawKey)).Return(&models.APIKey{
+		ID:        3,
+		UserID:    7,
+		Scopes:    models.PermissionProductsRead,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockAPIKeyRepo.On("TouchLastUsed", uint(3), mock.Anything, mock.Anything).Return(nil)
+	mockUserRepo.On("GetByID", uint(7)).Return(newClerk(), nil)
+
+	for permission, status := range map[string]int{
+		models.PermissionProductsRead:      http.StatusOK,
+		models.PermissionTransactionsWrite: http.StatusForbidden, // the clerk may, the key may not
+	} {
+		app := setupAPIKeyApp(t, apiKeyService, permission)
+		req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+		req.Header.Set("X-API-Key", rawKey)
+
+		// Act
+		resp, err := app.Test(req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, status, resp.StatusCode, permission)
+	}
+	mockAPIKeyRepo.AssertCalled(t, "TouchLastUsed", uint(3), mock.Anything, mock.Anything)
+}
+
+func TestAPIKeyAuth_InvalidKey(t *testing.T) {
+	// Arrange
+	mockAPIKeyRepo := new(MockAPIKeyRepository)
+	apiKeyService := auth.NewAPIKeyService(mockAPIKeyRepo, new(MockUserRepository), newClerkRoleService())
+	mockAPIKeyRepo.On("GetByHash", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	app := setupAPIKeyApp(t, apiKeyService, models.PermissionProductsRead)
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("X-API-Key", "pk_revoked")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestJWTOrAPIKeyAuth_FallsBackToBearerToken(t *testing.T) {
+	// Arrange
+	app := setupAPIKeyApp(t, auth.NewAPIKeyService(new(MockAPIKeyRepository), new(MockUserRepository), newClerkRoleService()), models.PermissionTransactionsWrite)
+	accessToken, _, _, err := newTestJWTService(t).GenerateTokens(context.Background(), newClerk(), models.ClientInfo{})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
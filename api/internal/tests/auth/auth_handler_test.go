@@ -1,11 +1,16 @@
 package auth_test
 
 import (
+	"api/config"
 	"api/internal/handlers/auth"
+	"api/internal/middlewares"
 	"api/internal/models"
+	authServices "api/internal/services/auth"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,6 +18,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockAuthService is a mock implementation of AuthService
@@ -20,23 +26,23 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) Register(req *models.RegisterRequest) (*models.AuthResponse, error) {
-	args := m.Called(req)
+func (m *MockAuthService) Register(ctx context.Context, req *models.RegisterRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	args := m.Called(req, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.AuthResponse), args.Error(1)
 }
 
-func (m *MockAuthService) Login(req *models.AuthRequest) (*models.AuthResponse, error) {
-	args := m.Called(req)
+func (m *MockAuthService) Login(ctx context.Context, req *models.AuthRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	args := m.Called(req, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.AuthResponse), args.Error(1)
 }
 
-func (m *MockAuthService) GetUserByID(id uint) (*models.UserResponse, error) {
+func (m *MockAuthService) GetUserByID(ctx context.Context, id uint) (*models.UserResponse, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,15 +50,20 @@ func (m *MockAuthService) GetUserByID(id uint) (*models.UserResponse, error) {
 	return args.Get(0).(*models.UserResponse), args.Error(1)
 }
 
-func (m *MockAuthService) RefreshToken(req *models.RefreshTokenRequest) (*models.AuthResponse, error) {
-	args := m.Called(req)
+func (m *MockAuthService) RefreshToken(ctx context.Context, req *models.RefreshTokenRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	args := m.Called(req, client)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.AuthResponse), args.Error(1)
 }
 
-func (m *MockAuthService) Logout(userID uint) error {
+func (m *MockAuthService) Logout(ctx context.Context, userID uint, accessToken string, refreshToken string) error {
+	args := m.Called(userID, accessToken, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(ctx context.Context, userID uint) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
@@ -66,7 +77,7 @@ func TestAuthHandler_SignUp_Success(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	registerReq := models.RegisterRequest{
 		Name:     "John Doe",
@@ -84,7 +95,7 @@ func TestAuthHandler_SignUp_Success(t *testing.T) {
 		RefreshToken: "refresh_token",
 	}
 
-	mockService.On("Register", mock.AnythingOfType("*models.RegisterRequest")).Return(expectedResponse, nil)
+	mockService.On("Register", mock.AnythingOfType("*models.RegisterRequest"), mock.Anything).Return(expectedResponse, nil)
 
 	app.Post("/signup", handler.SignUp)
 
@@ -112,7 +123,7 @@ func TestAuthHandler_SignUp_ValidationError(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	// Invalid request - missing required fields
 	registerReq := models.RegisterRequest{
@@ -137,14 +148,14 @@ func TestAuthHandler_SignUp_ValidationError(t *testing.T) {
 	json.NewDecoder(resp.Body).Decode(&response)
 
 	assert.Equal(t, "failed", response["message"])
-	assert.NotNil(t, response["errors"])
+	assert.NotNil(t, response["details"])
 }
 
 func TestAuthHandler_SignUp_ServiceError(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	registerReq := models.RegisterRequest{
 		Name:     "John Doe",
@@ -152,7 +163,7 @@ func TestAuthHandler_SignUp_ServiceError(t *testing.T) {
 		Password: "password123",
 	}
 
-	mockService.On("Register", mock.AnythingOfType("*models.RegisterRequest")).Return(nil, errors.New("email already exists"))
+	mockService.On("Register", mock.AnythingOfType("*models.RegisterRequest"), mock.Anything).Return(nil, errors.New("email already exists"))
 
 	app.Post("/signup", handler.SignUp)
 
@@ -180,7 +191,7 @@ func TestAuthHandler_SignIn_Success(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	loginReq := models.AuthRequest{
 		Email:    "john@example.com",
@@ -197,7 +208,7 @@ func TestAuthHandler_SignIn_Success(t *testing.T) {
 		RefreshToken: "refresh_token",
 	}
 
-	mockService.On("Login", mock.AnythingOfType("*models.AuthRequest")).Return(expectedResponse, nil)
+	mockService.On("Login", mock.AnythingOfType("*models.AuthRequest"), mock.Anything).Return(expectedResponse, nil)
 
 	app.Post("/signin", handler.SignIn)
 
@@ -225,14 +236,14 @@ func TestAuthHandler_SignIn_InvalidCredentials(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	loginReq := models.AuthRequest{
 		Email:    "john@example.com",
 		Password: "wrongpassword",
 	}
 
-	mockService.On("Login", mock.AnythingOfType("*models.AuthRequest")).Return(nil, errors.New("invalid credentials"))
+	mockService.On("Login", mock.AnythingOfType("*models.AuthRequest"), mock.Anything).Return(nil, errors.New("invalid credentials"))
 
 	app.Post("/signin", handler.SignIn)
 
@@ -256,11 +267,105 @@ func TestAuthHandler_SignIn_InvalidCredentials(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAuthHandler_SignIn_LockedAfterRepeatedFailures(t *testing.T) {
+	// Arrange
+	app := setupTestApp()
+	mockService := new(MockAuthService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
+
+	mockService.On("Login", mock.AnythingOfType("*models.AuthRequest"), mock.Anything).Return(nil, authServices.ErrInvalidCredentials)
+
+	app.Post("/signin", handler.SignIn)
+
+	signIn := func() *http.Response {
+		reqBody, _ := json.Marshal(models.AuthRequest{Email: "john@example.com", Password: "wrongpassword"})
+		req := httptest.NewRequest("POST", "/signin", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp
+	}
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusUnprocessableEntity, signIn().StatusCode)
+	}
+
+	// Act
+	resp := signIn()
+	lockedResp := signIn()
+
+	// Assert
+	// The failure that triggers the lock already answers with 429
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "failed", response["message"])
+
+	assert.Equal(t, http.StatusTooManyRequests, lockedResp.StatusCode)
+	assert.Equal(t, "30", lockedResp.Header.Get("Retry-After"))
+
+	// The locked attempt never reaches the service
+	mockService.AssertNumberOfCalls(t, "Login", 3)
+}
+
+func TestAuthHandler_SignIn_IPLockoutUsesForwardedClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantOtherIP    int
+	}{
+		{
+			// app.Test connects from 0.0.0.0, which plays the reverse proxy here
+			name:           "trusted proxy locks only the forwarded client",
+			trustedProxies: []string{"0.0.0.0"},
+			wantOtherIP:    http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "untrusted proxy header is ignored",
+			trustedProxies: []string{"10.0.0.0/8"},
+			wantOtherIP:    http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app := fiber.New(middlewares.TrustProxy(fiber.Config{}, config.ProxyConfig{Header: "X-Real-IP", TrustedProxies: tt.trustedProxies}))
+			mockService := new(MockAuthService)
+			handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
+			mockService.On("Login", mock.AnythingOfType("*models.AuthRequest"), mock.Anything).Return(nil, authServices.ErrInvalidCredentials)
+			app.Post("/signin", handler.SignIn)
+
+			signIn := func(email, clientIP string) *http.Response {
+				reqBody, _ := json.Marshal(models.AuthRequest{Email: email, Password: "wrongpassword"})
+				req := httptest.NewRequest("POST", "/signin", bytes.NewBuffer(reqBody))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Real-IP", clientIP)
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				return resp
+			}
+			// LOGIN_IP_MAX_ATTEMPTS is 5 in the test service; a new email each time keeps the email locks out of it
+			for i := 0; i < 5; i++ {
+				signIn(fmt.Sprintf("user%d@example.com", i), "203.0.113.7")
+			}
+
+			// Act
+			attacker := signIn("next@example.com", "203.0.113.7")
+			other := signIn("jane@example.com", "198.51.100.2")
+
+			// Assert
+			assert.Equal(t, http.StatusTooManyRequests, attacker.StatusCode)
+			assert.Equal(t, tt.wantOtherIP, other.StatusCode)
+		})
+	}
+}
+
 func TestAuthHandler_Me_Success(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	expectedUser := &models.UserResponse{
 		ID:    1,
@@ -298,7 +403,7 @@ func TestAuthHandler_Me_Unauthorized(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	app.Get("/me", handler.Me)
 
@@ -322,7 +427,7 @@ func TestAuthHandler_RefreshToken_Success(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
 	refreshReq := models.RefreshTokenRequest{
 		RefreshToken: "valid_refresh_token",
@@ -333,7 +438,7 @@ func TestAuthHandler_RefreshToken_Success(t *testing.T) {
 		RefreshToken: "new_refresh_token",
 	}
 
-	mockService.On("RefreshToken", mock.AnythingOfType("*models.RefreshTokenRequest")).Return(expectedResponse, nil)
+	mockService.On("RefreshToken", mock.AnythingOfType("*models.RefreshTokenRequest"), mock.Anything).Return(expectedResponse, nil)
 
 	app.Post("/refresh-token", handler.RefreshToken)
 
@@ -361,17 +466,20 @@ func TestAuthHandler_Logout_Success(t *testing.T) {
 	// Arrange
 	app := setupTestApp()
 	mockService := new(MockAuthService)
-	handler := auth.NewAuthHandler(mockService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
 
-	mockService.On("Logout", uint(1)).Return(nil)
+	mockService.On("Logout", uint(1), "access_token", "refresh_token").Return(nil)
 
 	app.Post("/logout", func(c *fiber.Ctx) error {
-		// Simulate JWT middleware setting userID
+		// Simulate JWT middleware setting userID and the access token
 		c.Locals("userID", "1")
+		c.Locals("accessToken", "access_token")
 		return handler.Logout(c)
 	})
 
-	req := httptest.NewRequest("POST", "/logout", nil)
+	body, _ := json.Marshal(models.LogoutRequest{RefreshToken: "refresh_token"})
+	req := httptest.NewRequest("POST", "/logout", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
 
 	// Act
 	resp, err := app.Test(req)
@@ -386,4 +494,50 @@ func TestAuthHandler_Logout_Success(t *testing.T) {
 	assert.Equal(t, "success", response["message"])
 
 	mockService.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestAuthHandler_LogoutAll_Success(t *testing.T) {
+	// Arrange
+	app := setupTestApp()
+	mockService := new(MockAuthService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
+
+	mockService.On("LogoutAll", uint(1)).Return(nil)
+
+	app.Post("/logout-all", func(c *fiber.Ctx) error {
+		// Simulate JWT middleware setting userID
+		c.Locals("userID", "1")
+		return handler.LogoutAll(c)
+	})
+
+	req := httptest.NewRequest("POST", "/logout-all", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthHandler_LogoutAll_Unauthorized(t *testing.T) {
+	// Arrange
+	app := setupTestApp()
+	mockService := new(MockAuthService)
+	handler := auth.NewAuthHandler(mockService, newTestLoginAttemptService(t))
+
+	app.Post("/logout-all", handler.LogoutAll)
+
+	req := httptest.NewRequest("POST", "/logout-all", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	mockService.AssertNotCalled(t, "LogoutAll", mock.Anything)
+}
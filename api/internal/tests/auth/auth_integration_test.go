@@ -8,15 +8,17 @@ import (
 	authRepositories "api/internal/repositories/auth"
 	authRoutes "api/internal/routes/auth"
 	authServices "api/internal/services/auth"
+	"api/pkg"
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 )
@@ -30,38 +32,90 @@ type AuthIntegrationTestSuite struct {
 }
 
 func (suite *AuthIntegrationTestSuite) SetupSuite() {
+	// InitDatabase exits the process when it cannot connect, so bail out early without a database
+	if os.Getenv("SKIP_INTEGRATION_TESTS") == "true" || os.Getenv("DB_HOST") == "" || os.Getenv("DB_NAME") == "" {
+		suite.T().Skip("integration tests need DB_HOST and DB_NAME")
+	}
+
 	// Set test environment
 	os.Setenv("JWT_SECRET", "test_secret_key")
 	os.Setenv("JWT_REFRESH_SECRET", "test_refresh_secret_key")
 
 	// Initialize test database
-	config.InitDB()
+	cfg, err := config.Load()
+	suite.Require().NoError(err)
+	config.InitDatabase(cfg)
 	suite.db = config.GetDB()
 
 	// Auto migrate
-	suite.db.AutoMigrate(&models.User{})
+	suite.db.AutoMigrate(&models.User{}, &models.RevokedToken{}, &models.RefreshToken{}, &models.Role{}, &models.Permission{}, &models.UserWarehouse{}, &models.PasswordResetToken{}, &models.EmailVerificationToken{}, &models.LoginAttempt{}, &models.UserTOTP{}, &models.RecoveryCode{}, &models.APIKey{}, &models.Session{}, &models.OIDCState{}, &models.UserIdentity{})
+	suite.db.FirstOrCreate(&models.Role{}, models.Role{Name: models.DefaultRole})
 
 	// Setup Fiber app with auth routes
 	suite.app = fiber.New()
 
 	// Initialize auth dependencies
 	userRepo := authRepositories.NewUserRepository(suite.db)
-	jwtService := authServices.NewJWTService()
-	authService := authServices.NewAuthService(userRepo, jwtService)
-	authHandler := authHandlers.NewAuthHandler(authService)
-	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService)
+	roleRepo := authRepositories.NewRoleRepository(suite.db)
+	signingKeys, err := authServices.LoadSigningKeys(cfg.JWT, cfg.App.Debug)
+	suite.Require().NoError(err)
+	tokenStore := authRepositories.NewTokenStore(suite.db)
+	jwtService := authServices.NewJWTService(tokenStore, signingKeys, cfg.JWT)
+	mailer := pkg.NewLogMailer("")
+	emailVerificationService := authServices.NewEmailVerificationService(userRepo, authRepositories.NewEmailVerificationRepository(suite.db), jwtService, mailer, cfg.EmailVerification)
+	emailVerificationHandler := authHandlers.NewEmailVerificationHandler(emailVerificationService)
+	loginAttemptService := authServices.NewLoginAttemptService(authRepositories.NewLoginAttemptStore(suite.db), userRepo, cfg.LoginAttempts)
+	twoFactorService := authServices.NewTwoFactorService(authRepositories.NewTwoFactorRepository(suite.db), userRepo, jwtService, loginAttemptService, cfg.TOTP)
+	twoFactorHandler := authHandlers.NewTwoFactorHandler(twoFactorService)
+	authService := authServices.NewAuthService(userRepo, roleRepo, jwtService, emailVerificationService, authServices.VerificationPolicy(cfg.EmailVerification.Policy), twoFactorService)
+	authHandler := authHandlers.NewAuthHandler(authService, loginAttemptService)
+	roleService := authServices.NewRoleService(roleRepo, userRepo, jwtService)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, roleService, authServices.VerificationPolicy(cfg.EmailVerification.Policy))
+	passwordResetService := authServices.NewPasswordResetService(userRepo, authRepositories.NewPasswordResetRepository(suite.db), jwtService, mailer, cfg.PasswordReset)
+	passwordResetHandler := authHandlers.NewPasswordResetHandler(passwordResetService)
+	apiKeyHandler := authHandlers.NewAPIKeyHandler(authServices.NewAPIKeyService(authRepositories.NewAPIKeyRepository(suite.db), userRepo, roleService))
+	oidcHandler := authHandlers.NewOIDCHandler(authServices.NewOIDCService(nil, authRepositories.NewOIDCRepository(suite.db), userRepo, roleRepo, jwtService, twoFactorService))
 
 	// Setup auth routes
-	authRoutes.SetupAuthRoutes(suite.app, authHandler, jwtMiddleware)
+	authRoutes.SetupAuthRoutes(suite.app, authHandler, passwordResetHandler, emailVerificationHandler, twoFactorHandler, authHandlers.NewJWKSHandler(signingKeys), apiKeyHandler, authHandlers.NewSessionHandler(authServices.NewSessionService(tokenStore)), oidcHandler, jwtMiddleware)
 }
 
 func (suite *AuthIntegrationTestSuite) SetupTest() {
 	// Clean up database before each test
+	suite.db.Exec("DELETE FROM user_identities")
+	suite.db.Exec("DELETE FROM oidc_states")
+	suite.db.Exec("DELETE FROM sessions")
+	suite.db.Exec("DELETE FROM api_keys")
+	suite.db.Exec("DELETE FROM recovery_codes")
+	suite.db.Exec("DELETE FROM user_totp")
+	suite.db.Exec("DELETE FROM login_attempts")
+	suite.db.Exec("DELETE FROM password_reset_tokens")
+	suite.db.Exec("DELETE FROM email_verification_tokens")
+	suite.db.Exec("DELETE FROM refresh_tokens")
+	suite.db.Exec("DELETE FROM revoked_tokens")
+	suite.db.Exec("DELETE FROM user_roles")
+	suite.db.Exec("DELETE FROM user_warehouses")
 	suite.db.Exec("DELETE FROM users")
 }
 
 func (suite *AuthIntegrationTestSuite) TearDownSuite() {
 	// Clean up after all tests
+	suite.db.Exec("DROP TABLE IF EXISTS user_identities")
+	suite.db.Exec("DROP TABLE IF EXISTS oidc_states")
+	suite.db.Exec("DROP TABLE IF EXISTS sessions")
+	suite.db.Exec("DROP TABLE IF EXISTS api_keys")
+	suite.db.Exec("DROP TABLE IF EXISTS recovery_codes")
+	suite.db.Exec("DROP TABLE IF EXISTS user_totp")
+	suite.db.Exec("DROP TABLE IF EXISTS login_attempts")
+	suite.db.Exec("DROP TABLE IF EXISTS password_reset_tokens")
+	suite.db.Exec("DROP TABLE IF EXISTS email_verification_tokens")
+	suite.db.Exec("DROP TABLE IF EXISTS refresh_tokens")
+	suite.db.Exec("DROP TABLE IF EXISTS revoked_tokens")
+	suite.db.Exec("DROP TABLE IF EXISTS user_roles")
+	suite.db.Exec("DROP TABLE IF EXISTS user_warehouses")
+	suite.db.Exec("DROP TABLE IF EXISTS role_permissions")
+	suite.db.Exec("DROP TABLE IF EXISTS permissions")
+	suite.db.Exec("DROP TABLE IF EXISTS roles")
 	suite.db.Exec("DROP TABLE IF EXISTS users")
 }
 
@@ -118,13 +172,13 @@ func (suite *AuthIntegrationTestSuite) TestSignUpDuplicateEmail() {
 
 	resp2, err := suite.app.Test(req2)
 	suite.NoError(err)
-	suite.Equal(http.StatusInternalServerError, resp2.StatusCode)
+	suite.Equal(http.StatusUnprocessableEntity, resp2.StatusCode)
 
 	var response map[string]interface{}
 	json.NewDecoder(resp2.Body).Decode(&response)
 
 	suite.Equal("failed", response["message"])
-	suite.Contains(response["error"], "email already exists")
+	suite.Contains(response["error"], "email already registered")
 }
 
 func (suite *AuthIntegrationTestSuite) TestSignInFlow() {
@@ -194,13 +248,13 @@ func (suite *AuthIntegrationTestSuite) TestSignInInvalidCredentials() {
 
 	resp, err := suite.app.Test(req)
 	suite.NoError(err)
-	suite.Equal(http.StatusInternalServerError, resp.StatusCode)
+	suite.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
 
 	var response map[string]interface{}
 	json.NewDecoder(resp.Body).Decode(&response)
 
 	suite.Equal("failed", response["message"])
-	suite.Contains(response["error"], "invalid credentials")
+	suite.Contains(response["error"], "invalid email or password")
 }
 
 func (suite *AuthIntegrationTestSuite) TestMeEndpoint() {
@@ -253,7 +307,7 @@ func (suite *AuthIntegrationTestSuite) TestMeEndpointUnauthorized() {
 	json.NewDecoder(resp.Body).Decode(&response)
 
 	suite.Equal("failed", response["message"])
-	suite.Equal("Missing or invalid token", response["error"])
+	suite.Equal("Authorization header is required", response["error"])
 }
 
 func (suite *AuthIntegrationTestSuite) TestRefreshTokenEndpoint() {
@@ -296,7 +350,23 @@ func (suite *AuthIntegrationTestSuite) TestRefreshTokenEndpoint() {
 
 	newData := response["data"].(map[string]interface{})
 	suite.NotNil(newData["access_token"])
-	suite.NotNil(newData["refresh_token"])
+	suite.NotEqual(refreshToken, newData["refresh_token"])
+
+	// Presenting the rotated-out token again is reuse and revokes the whole family
+	req = httptest.NewRequest("POST", "/api/v1/auth/refresh-token", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err = suite.app.Test(req)
+	suite.NoError(err)
+	suite.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+
+	reqBody, _ = json.Marshal(models.RefreshTokenRequest{RefreshToken: newData["refresh_token"].(string)})
+	req = httptest.NewRequest("POST", "/api/v1/auth/refresh-token", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err = suite.app.Test(req)
+	suite.NoError(err)
+	suite.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
 }
 
 func (suite *AuthIntegrationTestSuite) TestLogoutEndpoint() {
@@ -330,6 +400,31 @@ func (suite *AuthIntegrationTestSuite) TestLogoutEndpoint() {
 	json.NewDecoder(resp.Body).Decode(&response)
 
 	suite.Equal("success", response["message"])
+
+	// The revoked access token must no longer be accepted
+	req = httptest.NewRequest("GET", "/api/v1/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err = suite.app.Test(req)
+	suite.NoError(err)
+	suite.Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (suite *AuthIntegrationTestSuite) TestPurgeExpiredTokens() {
+	// Arrange
+	ctx := context.Background()
+	tokenStore := authRepositories.NewTokenStore(suite.db)
+	suite.Require().NoError(tokenStore.RevokeToken(ctx, "expired-jti", 1, time.Now().Add(-time.Minute)))
+	suite.Require().NoError(tokenStore.RevokeToken(ctx, "live-jti", 1, time.Now().Add(time.Hour)))
+
+	// Act
+	err := tokenStore.PurgeExpired(ctx)
+
+	// Assert
+	suite.Require().NoError(err)
+	var jtis []string
+	suite.Require().NoError(suite.db.Model(&models.RevokedToken{}).Pluck("jti", &jtis).Error)
+	suite.Equal([]string{"live-jti"}, jtis)
 }
 
 func TestAuthIntegrationTestSuite(t *testing.T) {
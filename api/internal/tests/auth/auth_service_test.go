@@ -3,13 +3,15 @@ package auth_test
 import (
 	"api/internal/models"
 	"api/internal/services/auth"
+	"context"
 	"errors"
 	"testing"
-	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 // MockUserRepository is a mock implementation of UserRepository
@@ -17,12 +19,12 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user *models.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,7 +32,7 @@ func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByID(id uint) (*models.User, error) {
+func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*models.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -38,54 +40,160 @@ func (m *MockUserRepository) GetByID(id uint) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Update(user *models.User) error {
+func (m *MockUserRepository) Update(ctx context.Context, user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Delete(id uint) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id uint) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) EmailExists(email string) (bool, error) {
+func (m *MockUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
 	args := m.Called(email)
 	return args.Bool(0), args.Error(1)
 }
 
+// MockRoleRepository is a mock implementation of RoleRepository
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) List(ctx context.Context) ([]models.Role, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetByNames(ctx context.Context, names []string) ([]models.Role, error) {
+	args := m.Called(names)
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetUserRoles(ctx context.Context, userID uint) ([]models.Role, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) ReplaceUserRoles(ctx context.Context, userID uint, roles []models.Role) error {
+	args := m.Called(userID, roles)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) CountUsersWithRole(ctx context.Context, name string) (int64, error) {
+	args := m.Called(name)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoleRepository) RolePermissions(ctx context.Context) (map[string][]string, error) {
+	args := m.Called()
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
+// newMockRoleRepository returns a role repository that knows the default role
+func newMockRoleRepository() *MockRoleRepository {
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("GetByName", models.DefaultRole).Return(&models.Role{ID: 4, Name: models.DefaultRole}, nil).Maybe()
+	return roleRepo
+}
+
 // MockJWTService is a mock implementation of JWTService
 type MockJWTService struct {
 	mock.Mock
 }
 
-func (m *MockJWTService) GenerateTokens(userID uint) (string, string, error) {
-	args := m.Called(userID)
-	return args.String(0), args.String(1), args.Error(2)
+func (m *MockJWTService) GenerateTokens(ctx context.Context, user *models.User, client models.ClientInfo) (string, string, int64, error) {
+	args := m.Called(user, client)
+	return args.String(0), args.String(1), args.Get(2).(int64), args.Error(3)
 }
 
-func (m *MockJWTService) ValidateToken(tokenString string) (*auth.Claims, error) {
+func (m *MockJWTService) ValidateToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
 	args := m.Called(tokenString)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*auth.Claims), args.Error(1)
+	return args.Get(0).(*jwt.Token), args.Error(1)
 }
 
-func (m *MockJWTService) ExtractUserID(tokenString string) (uint, error) {
+func (m *MockJWTService) ValidateRefreshToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
 	args := m.Called(tokenString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.Token), args.Error(1)
+}
+
+func (m *MockJWTService) ExtractUserID(token *jwt.Token) (uint, error) {
+	args := m.Called(token)
 	return args.Get(0).(uint), args.Error(1)
 }
 
-func (m *MockJWTService) RefreshAccessToken(refreshToken string) (string, string, error) {
-	args := m.Called(refreshToken)
-	return args.String(0), args.String(1), args.Error(2)
+func (m *MockJWTService) ExtractRoles(token *jwt.Token) ([]string, error) {
+	args := m.Called(token)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockJWTService) ExtractWarehouseIDs(token *jwt.Token) ([]uint, error) {
+	args := m.Called(token)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockJWTService) ExtractEmailVerified(token *jwt.Token) (bool, error) {
+	args := m.Called(token)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockJWTService) ExtractSessionID(token *jwt.Token) (string, error) {
+	args := m.Called(token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTService) RotateRefreshToken(ctx context.Context, refreshToken string, client models.ClientInfo) (string, string, int64, error) {
+	args := m.Called(refreshToken, client)
+	return args.String(0), args.String(1), args.Get(2).(int64), args.Error(3)
+}
+
+func (m *MockJWTService) RevokeToken(ctx context.Context, token *jwt.Token) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockJWTService) RevokeAllTokens(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MockJWTService) GenerateMFAToken(ctx context.Context, user *models.User) (string, error) {
+	args := m.Called(user)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTService) ValidateMFAToken(ctx context.Context, tokenString string) (*jwt.Token, error) {
+	args := m.Called(tokenString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.Token), args.Error(1)
+}
+
+// claimsToken wraps claims in a parsed token as returned by the JWT service
+func claimsToken(userID uint, tokenType string) *jwt.Token {
+	return &jwt.Token{Valid: true, Claims: &auth.Claims{UserID: userID, Type: tokenType}}
 }
 
 func TestAuthService_Register_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	registerReq := &models.RegisterRequest{
 		Name:     "John Doe",
@@ -95,16 +203,17 @@ func TestAuthService_Register_Success(t *testing.T) {
 
 	mockRepo.On("EmailExists", registerReq.Email).Return(false, nil)
 	mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil)
-	mockJWT.On("GenerateTokens", mock.AnythingOfType("uint")).Return("access_token", "refresh_token", nil)
+	mockJWT.On("GenerateTokens", mock.AnythingOfType("*models.User"), mock.Anything).Return("access_token", "refresh_token", int64(900), nil)
 
 	// Act
-	response, err := authService.Register(registerReq)
+	response, err := authService.Register(context.Background(), registerReq, models.ClientInfo{})
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, response)
 	assert.Equal(t, registerReq.Name, response.User.Name)
 	assert.Equal(t, registerReq.Email, response.User.Email)
+	assert.Equal(t, []string{models.DefaultRole}, response.User.Roles)
 	assert.Equal(t, "access_token", response.AccessToken)
 	assert.Equal(t, "refresh_token", response.RefreshToken)
 
@@ -116,7 +225,7 @@ func TestAuthService_Register_EmailExists(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	registerReq := &models.RegisterRequest{
 		Name:     "John Doe",
@@ -127,12 +236,12 @@ func TestAuthService_Register_EmailExists(t *testing.T) {
 	mockRepo.On("EmailExists", registerReq.Email).Return(true, nil)
 
 	// Act
-	response, err := authService.Register(registerReq)
+	response, err := authService.Register(context.Background(), registerReq, models.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, response)
-	assert.Contains(t, err.Error(), "email already exists")
+	assert.ErrorIs(t, err, auth.ErrEmailAlreadyRegistered)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -141,7 +250,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 	user := &models.User{
@@ -157,10 +266,10 @@ func TestAuthService_Login_Success(t *testing.T) {
 	}
 
 	mockRepo.On("GetByEmail", loginReq.Email).Return(user, nil)
-	mockJWT.On("GenerateTokens", user.ID).Return("access_token", "refresh_token", nil)
+	mockJWT.On("GenerateTokens", user, mock.Anything).Return("access_token", "refresh_token", int64(900), nil)
 
 	// Act
-	response, err := authService.Login(loginReq)
+	response, err := authService.Login(context.Background(), loginReq, models.ClientInfo{})
 
 	// Assert
 	assert.NoError(t, err)
@@ -178,7 +287,7 @@ func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 	user := &models.User{
@@ -196,12 +305,12 @@ func TestAuthService_Login_InvalidCredentials(t *testing.T) {
 	mockRepo.On("GetByEmail", loginReq.Email).Return(user, nil)
 
 	// Act
-	response, err := authService.Login(loginReq)
+	response, err := authService.Login(context.Background(), loginReq, models.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, response)
-	assert.Contains(t, err.Error(), "invalid credentials")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -210,22 +319,22 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	loginReq := &models.AuthRequest{
 		Email:    "nonexistent@example.com",
 		Password: "password123",
 	}
 
-	mockRepo.On("GetByEmail", loginReq.Email).Return(nil, errors.New("user not found"))
+	mockRepo.On("GetByEmail", loginReq.Email).Return(nil, gorm.ErrRecordNotFound)
 
 	// Act
-	response, err := authService.Login(loginReq)
+	response, err := authService.Login(context.Background(), loginReq, models.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, response)
-	assert.Contains(t, err.Error(), "invalid credentials")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -234,7 +343,7 @@ func TestAuthService_GetUserByID_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	user := &models.User{
 		ID:    1,
@@ -245,7 +354,7 @@ func TestAuthService_GetUserByID_Success(t *testing.T) {
 	mockRepo.On("GetByID", uint(1)).Return(user, nil)
 
 	// Act
-	result, err := authService.GetUserByID(1)
+	result, err := authService.GetUserByID(context.Background(), 1)
 
 	// Assert
 	assert.NoError(t, err)
@@ -261,12 +370,12 @@ func TestAuthService_GetUserByID_NotFound(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	mockRepo.On("GetByID", uint(999)).Return(nil, errors.New("user not found"))
 
 	// Act
-	result, err := authService.GetUserByID(999)
+	result, err := authService.GetUserByID(context.Background(), 999)
 
 	// Assert
 	assert.Error(t, err)
@@ -279,16 +388,20 @@ func TestAuthService_RefreshToken_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	refreshReq := &models.RefreshTokenRequest{
 		RefreshToken: "valid_refresh_token",
 	}
+	token := claimsToken(1, "refresh")
 
-	mockJWT.On("RefreshAccessToken", refreshReq.RefreshToken).Return("new_access_token", "new_refresh_token", nil)
+	mockJWT.On("ValidateRefreshToken", refreshReq.RefreshToken).Return(token, nil)
+	mockJWT.On("ExtractUserID", token).Return(uint(1), nil)
+	mockJWT.On("RotateRefreshToken", refreshReq.RefreshToken, mock.Anything).Return("new_access_token", "new_refresh_token", int64(900), nil)
+	mockRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Name: "John Doe"}, nil)
 
 	// Act
-	response, err := authService.RefreshToken(refreshReq)
+	response, err := authService.RefreshToken(context.Background(), refreshReq, models.ClientInfo{})
 
 	// Assert
 	assert.NoError(t, err)
@@ -303,33 +416,127 @@ func TestAuthService_RefreshToken_InvalidToken(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
 
 	refreshReq := &models.RefreshTokenRequest{
 		RefreshToken: "invalid_refresh_token",
 	}
 
-	mockJWT.On("RefreshAccessToken", refreshReq.RefreshToken).Return("", "", errors.New("invalid token"))
+	mockJWT.On("ValidateRefreshToken", refreshReq.RefreshToken).Return(nil, auth.ErrInvalidToken)
 
 	// Act
-	response, err := authService.RefreshToken(refreshReq)
+	response, err := authService.RefreshToken(context.Background(), refreshReq, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	assert.Nil(t, response)
+
+	mockJWT.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_RefreshToken_Reused(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
+
+	refreshReq := &models.RefreshTokenRequest{
+		RefreshToken: "rotated_refresh_token",
+	}
+	token := claimsToken(1, "refresh")
+
+	mockJWT.On("ValidateRefreshToken", refreshReq.RefreshToken).Return(token, nil)
+	mockJWT.On("ExtractUserID", token).Return(uint(1), nil)
+	mockJWT.On("RotateRefreshToken", refreshReq.RefreshToken, mock.Anything).Return("", "", int64(0), auth.ErrRefreshTokenReused)
+
+	// Act
+	response, err := authService.RefreshToken(context.Background(), refreshReq, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+	assert.Nil(t, response)
+}
+
+func TestAuthService_RefreshToken_StoreError(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
+
+	refreshReq := &models.RefreshTokenRequest{
+		RefreshToken: "valid_refresh_token",
+	}
+	token := claimsToken(1, "refresh")
+
+	mockJWT.On("ValidateRefreshToken", refreshReq.RefreshToken).Return(token, nil)
+	mockJWT.On("ExtractUserID", token).Return(uint(1), nil)
+	mockJWT.On("RotateRefreshToken", refreshReq.RefreshToken, mock.Anything).Return("", "", int64(0), errors.New("connection refused"))
+
+	// Act
+	response, err := authService.RefreshToken(context.Background(), refreshReq, models.ClientInfo{})
 
 	// Assert
 	assert.Error(t, err)
+	assert.NotErrorIs(t, err, auth.ErrInvalidRefreshToken)
 	assert.Nil(t, response)
+}
+
+func TestAuthService_Logout_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
+
+	access := claimsToken(1, "access")
+	refresh := claimsToken(1, "refresh")
+	mockJWT.On("ValidateToken", "access_token").Return(access, nil)
+	mockJWT.On("RevokeToken", access).Return(nil)
+	mockJWT.On("ValidateRefreshToken", "refresh_token").Return(refresh, nil)
+	mockJWT.On("ExtractUserID", refresh).Return(uint(1), nil)
+	mockJWT.On("RevokeToken", refresh).Return(nil)
+
+	// Act
+	err := authService.Logout(context.Background(), 1, "access_token", "refresh_token")
 
+	// Assert
+	assert.NoError(t, err)
 	mockJWT.AssertExpectations(t)
 }
 
-func TestAuthService_Logout_Success(t *testing.T) {
+func TestAuthService_Logout_IgnoresForeignRefreshToken(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockJWT := new(MockJWTService)
-	authService := auth.NewAuthService(mockRepo, mockJWT)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
+
+	access := claimsToken(1, "access")
+	refresh := claimsToken(2, "refresh")
+	mockJWT.On("ValidateToken", "access_token").Return(access, nil)
+	mockJWT.On("RevokeToken", access).Return(nil)
+	mockJWT.On("ValidateRefreshToken", "other_refresh_token").Return(refresh, nil)
+	mockJWT.On("ExtractUserID", refresh).Return(uint(2), nil)
+
+	// Act
+	err := authService.Logout(context.Background(), 1, "access_token", "other_refresh_token")
+
+	// Assert
+	assert.NoError(t, err)
+	mockJWT.AssertNotCalled(t, "RevokeToken", refresh)
+}
+
+func TestAuthService_LogoutAll_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, newMockTwoFactorService())
+
+	mockJWT.On("RevokeAllTokens", uint(1)).Return(nil)
 
 	// Act
-	err := authService.Logout(1)
+	err := authService.LogoutAll(context.Background(), 1)
 
 	// Assert
 	assert.NoError(t, err)
-}
\ No newline at end of file
+	mockJWT.AssertExpectations(t)
+}
@@ -0,0 +1,205 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MockEmailVerificationRepository is a mock implementation of EmailVerificationRepository
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationRepository) Create(ctx context.Context, token *models.EmailVerificationToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationRepository) UseToken(ctx context.Context, tokenHash string) (*models.EmailVerificationToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationRepository) MarkVerified(ctx context.Context, userID uint, verifiedAt time.Time) error {
+	args := m.Called(userID, verifiedAt)
+	return args.Error(0)
+}
+
+// MockEmailVerificationService is a mock implementation of EmailVerificationService
+type MockEmailVerificationService struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationService) SendVerification(ctx context.Context, user *models.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationService) ResendVerification(ctx context.Context, req *models.ResendVerificationRequest) error {
+	args := m.Called(req)
+	return args.Error(0)
+}
+
+func newMockEmailVerificationService() *MockEmailVerificationService {
+	verificationService := new(MockEmailVerificationService)
+	verificationService.On("SendVerification", mock.AnythingOfType("*models.User")).Return(nil).Maybe()
+	return verificationService
+}
+
+func TestEmailVerificationService_SendVerification(t *testing.T) {
+	// Arrange
+	cfg := config.Default().EmailVerification
+	cfg.URL = "https://app.example.com/verify"
+	mockVerificationRepo := new(MockEmailVerificationRepository)
+	mailer := &recordingMailer{}
+	service := auth.NewEmailVerificationService(new(MockUserRepository), mockVerificationRepo, new(MockJWTService), mailer, cfg)
+
+	var stored *models.EmailVerificationToken
+	mockVerificationRepo.On("Create", mock.AnythingOfType("*models.EmailVerificationToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.EmailVerificationToken)
+	}).Return(nil)
+
+	// Act
+	err := service.SendVerification(context.Background(), &models.User{ID: 1, Name: "John", Email: "john@example.com"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, mailer.messages, 1)
+	assert.Equal(t, "john@example.com", mailer.messages[0].To)
+	assert.Contains(t, mailer.messages[0].Body, "https://app.example.com/verify?token=")
+	require.NotNil(t, stored)
+	assert.Equal(t, sha256Hex(tokenFromMail(t, mailer.messages[0])), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+}
+
+func TestEmailVerificationService_VerifyEmail_Success(t *testing.T) {
+	// Arrange
+	mockVerificationRepo := new(MockEmailVerificationRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewEmailVerificationService(new(MockUserRepository), mockVerificationRepo, mockJWT, &recordingMailer{}, config.Default().EmailVerification)
+
+	mockVerificationRepo.On("UseToken", sha256Hex("verify-token")).Return(&models.EmailVerificationToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	mockVerificationRepo.On("MarkVerified", uint(1), mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("RevokeAllTokens", uint(1)).Return(nil)
+
+	// Act
+	err := service.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: "verify-token"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockVerificationRepo.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestEmailVerificationService_VerifyEmail_InvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		record *models.EmailVerificationToken
+	}{
+		{name: "unknown"},
+		{name: "used", record: &models.EmailVerificationToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}},
+		{name: "expired", record: &models.EmailVerificationToken{UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockVerificationRepo := new(MockEmailVerificationRepository)
+			service := auth.NewEmailVerificationService(new(MockUserRepository), mockVerificationRepo, new(MockJWTService), &recordingMailer{}, config.Default().EmailVerification)
+
+			if tt.record != nil {
+				mockVerificationRepo.On("UseToken", sha256Hex("verify-token")).Return(tt.record, nil)
+			} else {
+				mockVerificationRepo.On("UseToken", sha256Hex("verify-token")).Return(nil, gorm.ErrRecordNotFound)
+			}
+
+			// Act
+			err := service.VerifyEmail(context.Background(), &models.VerifyEmailRequest{Token: "verify-token"})
+
+			// Assert
+			assert.ErrorIs(t, err, auth.ErrInvalidVerificationToken)
+			mockVerificationRepo.AssertNotCalled(t, "MarkVerified", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestEmailVerificationService_ResendVerification_SkipsVerifiedUser(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockVerificationRepo := new(MockEmailVerificationRepository)
+	mailer := &recordingMailer{}
+	service := auth.NewEmailVerificationService(mockUserRepo, mockVerificationRepo, new(MockJWTService), mailer, config.Default().EmailVerification)
+
+	verifiedAt := time.Now()
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+
+	// Act
+	err := service.ResendVerification(context.Background(), &models.ResendVerificationRequest{Email: "john@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, mailer.messages)
+	mockVerificationRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestAuthService_Register_BlockPolicyIssuesNoTokens(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	verificationService := new(MockEmailVerificationService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, verificationService, auth.VerificationPolicyBlock, newMockTwoFactorService())
+
+	mockRepo.On("EmailExists", "john@example.com").Return(false, nil)
+	mockRepo.On("Create", mock.AnythingOfType("*models.User")).Return(nil)
+	verificationService.On("SendVerification", mock.AnythingOfType("*models.User")).Return(errors.New("smtp unavailable"))
+
+	// Act
+	response, err := authService.Register(context.Background(), &models.RegisterRequest{Name: "John Doe", Email: "john@example.com", Password: "password123"}, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", response.User.Email)
+	assert.Nil(t, response.User.EmailVerifiedAt)
+	assert.Empty(t, response.AccessToken)
+	assert.Empty(t, response.RefreshToken)
+	verificationService.AssertExpectations(t)
+	mockJWT.AssertNotCalled(t, "GenerateTokens", mock.Anything, mock.Anything)
+}
+
+func TestAuthService_Login_BlockPolicyRejectsUnverified(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyBlock, newMockTwoFactorService())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	mockRepo.On("GetByEmail", "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword)}, nil)
+
+	// Act
+	response, err := authService.Login(context.Background(), &models.AuthRequest{Email: "john@example.com", Password: "password123"}, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrEmailNotVerified)
+	assert.Nil(t, response)
+	mockJWT.AssertNotCalled(t, "GenerateTokens", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,222 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	authRepositories "api/internal/repositories/auth"
+	"api/internal/services/auth"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testJWTConfig returns the default token lifetimes with test HS256 secrets
+func testJWTConfig() config.JWTConfig {
+	cfg := config.Default().JWT
+	cfg.Secret = "test_secret_key"
+	cfg.RefreshSecret = "test_refresh_secret_key"
+	return cfg
+}
+
+func newTestJWTService(t *testing.T) auth.JWTService {
+	signingKeys, err := auth.LoadSigningKeys(testJWTConfig(), false)
+	require.NoError(t, err)
+	return auth.NewJWTService(authRepositories.NewMemoryTokenStore(), signingKeys, testJWTConfig())
+}
+
+func TestJWTService_ValidateToken_RejectsRefreshToken(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	_, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	_, err = jwtService.ValidateToken(context.Background(), refreshToken)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestJWTService_RevokeToken_RevokesOnlyThatSession(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	accessToken, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+	otherAccessToken, otherRefreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+
+	token, err := jwtService.ValidateToken(context.Background(), accessToken)
+	require.NoError(t, err)
+
+	// Act
+	err = jwtService.RevokeToken(context.Background(), token)
+
+	// Assert
+	assert.NoError(t, err)
+	_, err = jwtService.ValidateToken(context.Background(), accessToken)
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+	_, err = jwtService.ValidateToken(context.Background(), otherAccessToken)
+	assert.NoError(t, err)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked, "the refresh token of the revoked session")
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), otherRefreshToken, models.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RevokeAllTokens(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	accessToken, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+	otherUserToken, _, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 2, Email: "jane@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	err = jwtService.RevokeAllTokens(context.Background(), 1)
+
+	// Assert
+	assert.NoError(t, err)
+	_, err = jwtService.ValidateToken(context.Background(), accessToken)
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+	_, err = jwtService.ValidateToken(context.Background(), otherUserToken)
+	assert.NoError(t, err)
+
+	// Tokens issued after the revocation carry the new version
+	newAccessToken, _, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+	_, err = jwtService.ValidateToken(context.Background(), newAccessToken)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_GenerateTokens_EmbedsRoles(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	user := &models.User{ID: 1, Email: "john@example.com", Roles: []models.Role{{Name: models.RoleClerk}}}
+
+	// Act
+	accessToken, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	token, err := jwtService.ValidateToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	roles, err := jwtService.ExtractRoles(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.RoleClerk}, roles)
+}
+
+func TestJWTService_RotateRefreshToken_KeepsWarehouses(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	user := &models.User{ID: 1, Email: "john@example.com", Warehouses: []models.UserWarehouse{{UserID: 1, WarehouseID: 2}, {UserID: 1, WarehouseID: 5}}}
+	_, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	accessToken, _, _, err := jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	token, err := jwtService.ValidateToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	warehouseIDs, err := jwtService.ExtractWarehouseIDs(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{2, 5}, warehouseIDs)
+}
+
+func TestJWTService_RotateRefreshToken(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	_, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com", Roles: []models.Role{{Name: models.RoleViewer}}}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	accessToken, newRefreshToken, expiresIn, err := jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(900), expiresIn)
+	assert.NotEqual(t, refreshToken, newRefreshToken)
+	token, err := jwtService.ValidateToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	userID, err := jwtService.ExtractUserID(token)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+	roles, err := jwtService.ExtractRoles(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.RoleViewer}, roles)
+
+	// The rotated token stays in the same family
+	oldClaims := mustRefreshClaims(t, jwtService, refreshToken)
+	newClaims := mustRefreshClaims(t, jwtService, newRefreshToken)
+	assert.Equal(t, oldClaims.FamilyID, newClaims.FamilyID)
+}
+
+func TestJWTService_RotateRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	_, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+	_, rotatedToken, _, err := jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+	require.NoError(t, err)
+	_, otherSessionToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenReused)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), rotatedToken, models.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), otherSessionToken, models.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestJWTService_RevokeToken_RefreshTokenRevokesFamily(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	_, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+	_, rotatedToken, _, err := jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+	require.NoError(t, err)
+	token, err := jwtService.ValidateRefreshToken(context.Background(), refreshToken)
+	require.NoError(t, err)
+
+	// Act
+	err = jwtService.RevokeToken(context.Background(), token)
+
+	// Assert
+	assert.NoError(t, err)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), rotatedToken, models.ClientInfo{})
+	assert.ErrorIs(t, err, auth.ErrTokenRevoked)
+}
+
+func mustRefreshClaims(t *testing.T, jwtService auth.JWTService, refreshToken string) *auth.Claims {
+	t.Helper()
+	token, err := jwtService.ValidateRefreshToken(context.Background(), refreshToken)
+	require.NoError(t, err)
+	return token.Claims.(*auth.Claims)
+}
+
+func TestJWTService_MFATokenIsNotAnAccessToken(t *testing.T) {
+	// Arrange
+	jwtService := newTestJWTService(t)
+	mfaToken, err := jwtService.GenerateMFAToken(context.Background(), &models.User{ID: 1, Email: "admin@example.com"})
+	require.NoError(t, err)
+
+	// Act
+	_, err = jwtService.ValidateToken(context.Background(), mfaToken)
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	token, err := jwtService.ValidateMFAToken(context.Background(), mfaToken)
+	require.NoError(t, err)
+	assert.Equal(t, "mfa_pending", token.Claims.(*auth.Claims).Type)
+}
@@ -0,0 +1,119 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	authRepositories "api/internal/repositories/auth"
+	"api/internal/services/auth"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func newTestLoginAttemptService(t *testing.T) auth.LoginAttemptService {
+	cfg := config.Default().LoginAttempts
+	cfg.MaxAttempts = 3
+	cfg.MaxIPAttempts = 5
+	cfg.LockoutBase = 30 * time.Second
+	cfg.LockoutMax = 100 * time.Second
+	return auth.NewLoginAttemptService(authRepositories.NewMemoryLoginAttemptStore(), new(MockUserRepository), cfg)
+}
+
+func TestLoginAttemptService_LocksEmailWithBackoff(t *testing.T) {
+	// Arrange
+	service := newTestLoginAttemptService(t)
+
+	// Act
+	var locks []time.Duration
+	for i := 0; i < 5; i++ {
+		lock, err := service.RecordFailure(context.Background(), "john@example.com", "10.0.0.1")
+		require.NoError(t, err)
+		locks = append(locks, lock)
+	}
+
+	// Assert
+	assert.Equal(t, []time.Duration{0, 0, 30 * time.Second, 60 * time.Second, 100 * time.Second}, locks)
+	retryAfter, err := service.Check(context.Background(), "John@Example.com ", "10.0.0.2")
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(100*time.Second), float64(retryAfter), float64(time.Second))
+}
+
+func TestLoginAttemptService_LocksIPAcrossEmails(t *testing.T) {
+	// Arrange
+	service := newTestLoginAttemptService(t)
+	emails := []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+
+	// Act
+	for _, email := range emails {
+		_, err := service.RecordFailure(context.Background(), email, "10.0.0.1")
+		require.NoError(t, err)
+	}
+
+	// Assert
+	retryAfter, err := service.Check(context.Background(), "new@example.com", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	retryAfter, err = service.Check(context.Background(), "new@example.com", "10.0.0.2")
+	assert.NoError(t, err)
+	assert.Zero(t, retryAfter)
+}
+
+func TestLoginAttemptService_RecordSuccess_ResetsEmailOnly(t *testing.T) {
+	// Arrange
+	service := newTestLoginAttemptService(t)
+	for i := 0; i < 2; i++ {
+		_, err := service.RecordFailure(context.Background(), "john@example.com", "10.0.0.1")
+		require.NoError(t, err)
+	}
+
+	// Act
+	err := service.RecordSuccess(context.Background(), "john@example.com")
+
+	// Assert
+	require.NoError(t, err)
+	lock, err := service.RecordFailure(context.Background(), "john@example.com", "10.0.0.1")
+	assert.NoError(t, err)
+	assert.Zero(t, lock, "email count starts over after a successful sign-in")
+	for i := 0; i < 2; i++ {
+		lock, err = service.RecordFailure(context.Background(), "other@example.com", "10.0.0.1")
+		require.NoError(t, err)
+	}
+	assert.Greater(t, lock, time.Duration(0), "the IP count survives the successful sign-in")
+}
+
+func TestLoginAttemptService_Unlock(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	service := auth.NewLoginAttemptService(authRepositories.NewMemoryLoginAttemptStore(), mockUserRepo, config.Default().LoginAttempts)
+	mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+	for i := 0; i < 5; i++ {
+		_, err := service.RecordFailure(context.Background(), "john@example.com", "10.0.0.1")
+		require.NoError(t, err)
+	}
+
+	// Act
+	err := service.Unlock(context.Background(), 1)
+
+	// Assert
+	require.NoError(t, err)
+	retryAfter, err := service.Check(context.Background(), "john@example.com", "10.0.0.2")
+	assert.NoError(t, err)
+	assert.Zero(t, retryAfter)
+}
+
+func TestLoginAttemptService_Unlock_UserNotFound(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	service := auth.NewLoginAttemptService(authRepositories.NewMemoryLoginAttemptStore(), mockUserRepo, config.Default().LoginAttempts)
+	mockUserRepo.On("GetByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	err := service.Unlock(context.Background(), 9)
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrUserNotFound)
+}
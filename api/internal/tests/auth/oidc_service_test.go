@@ -0,0 +1,282 @@
+package auth_test
+
+import (
+	"api/internal/handlers/auth"
+	"api/internal/models"
+	authServices "api/internal/services/auth"
+	"api/pkg"
+	"api/pkg/oidctest"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockOIDCRepository is a mock implementation of OIDCRepository
+type MockOIDCRepository struct {
+	mock.Mock
+}
+
+func (m *MockOIDCRepository) SaveState(ctx context.Context, state *models.OIDCState) error {
+	args := m.Called(state)
+	return args.Error(0)
+}
+
+func (m *MockOIDCRepository) TakeState(ctx context.Context, stateHash string) (*models.OIDCState, error) {
+	args := m.Called(stateHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OIDCState), args.Error(1)
+}
+
+func (m *MockOIDCRepository) GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	args := m.Called(issuer, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserIdentity), args.Error(1)
+}
+
+func (m *MockOIDCRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+// oidcTest wires an OIDC service to an in-process provider and mocked repositories
+type oidcTest struct {
+	provider         *oidctest.Server
+	oidcRepo         *MockOIDCRepository
+	userRepo         *MockUserRepository
+	roleRepo         *MockRoleRepository
+	twoFactorService *MockTwoFactorService
+	service          authServices.OIDCService
+}
+
+func newOIDCTest(t *testing.T) *oidcTest {
+	provider, err := oidctest.NewServer("inventory-api", "client-secret")
+	require.NoError(t, err)
+	t.Cleanup(provider.Close)
+
+	test := &oidcTest{
+		provider:         provider,
+		oidcRepo:         new(MockOIDCRepository),
+		userRepo:         new(MockUserRepository),
+		roleRepo:         new(MockRoleRepository),
+		twoFactorService: new(MockTwoFactorService),
+	}
+	test.service = authServices.NewOIDCService(pkg.NewOIDCProvider(pkg.OIDCConfig{
+		IssuerURL:    provider.Issuer,
+		ClientID:     "inventory-api",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:3000/oidc/callback",
+	}), test.oidcRepo, test.userRepo, test.roleRepo, newTestJWTService(t), test.twoFactorService)
+	return test
+}
+
+// signIn starts a sign-in, approves it at the provider as email and returns the callback request
+func (o *oidcTest) signIn(t *testing.T, email string) (*models.OIDCCallbackRequest, *models.OIDCState) {
+	var saved *models.OIDCState
+	o.oidcRepo.On("SaveState", mock.AnythingOfType("*models.OIDCState")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*models.OIDCState)
+	}).Return(nil).Once()
+
+	response, err := o.service.AuthorizationURL(context.Background())
+	require.NoError(t, err)
+	code, state, err := o.provider.SignIn(response.AuthorizationURL, email)
+	require.NoError(t, err)
+	require.NotEmpty(t, code)
+
+	o.oidcRepo.On("TakeState", sha256Hex(state)).Return(saved, nil).Once()
+	return &models.OIDCCallbackRequest{Code: code, State: state}, saved
+}
+
+func TestOIDCService_AuthorizationURL_UsesPKCE(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	var saved *models.OIDCState
+	test.oidcRepo.On("SaveState", mock.AnythingOfType("*models.OIDCState")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*models.OIDCState)
+	}).Return(nil)
+
+	// Act
+	response, err := test.service.AuthorizationURL(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	authURL, err := url.Parse(response.AuthorizationURL)
+	require.NoError(t, err)
+	query := authURL.Query()
+	assert.Equal(t, test.provider.Issuer+"/authorize", authURL.Scheme+"://"+authURL.Host+authURL.Path)
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, pkg.PKCEChallenge(saved.CodeVerifier), query.Get("code_challenge"))
+	assert.Equal(t, saved.Nonce, query.Get("nonce"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+
+	// Only the hash of the state is stored
+	assert.Equal(t, sha256Hex(query.Get("state")), saved.StateHash)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), saved.ExpiresAt, time.Minute)
+}
+
+func TestOIDCService_Callback_CreatesUserOnFirstLogin(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	test.provider.AddUser(oidctest.User{Subject: "sub-123", Email: "new@example.com", Name: "New User", EmailVerified: true})
+	req, _ := test.signIn(t, "new@example.com")
+
+	test.oidcRepo.On("GetIdentity", test.provider.Issuer, "sub-123").Return(nil, gorm.ErrRecordNotFound)
+	test.userRepo.On("GetByEmail", "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+	test.roleRepo.On("GetByName", models.DefaultRole).Return(&models.Role{ID: 3, Name: models.DefaultRole}, nil)
+	var created *models.User
+	test.userRepo.On("Create", mock.AnythingOfType("*models.User")).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.User)
+		created.ID = 9
+	}).Return(nil)
+	test.oidcRepo.On("CreateIdentity", &models.UserIdentity{UserID: 9, Issuer: test.provider.Issuer, Subject: "sub-123"}).Return(nil)
+	test.twoFactorService.On("Enabled", uint(9)).Return(false, nil)
+
+	// Act
+	response, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "success", response.Message)
+	assert.NotEmpty(t, response.AccessToken)
+	assert.NotEmpty(t, response.RefreshToken)
+	assert.Equal(t, "New User", created.Name)
+	assert.Equal(t, []models.Role{{ID: 3, Name: models.DefaultRole}}, created.Roles)
+	assert.NotNil(t, created.EmailVerifiedAt)
+	assert.NotEmpty(t, created.Password)
+	test.oidcRepo.AssertExpectations(t)
+}
+
+func TestOIDCService_Callback_LinksExistingUserByEmail(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	req, _ := test.signIn(t, "john@example.com")
+
+	existing := &models.User{ID: 1, Email: "john@example.com"}
+	test.oidcRepo.On("GetIdentity", test.provider.Issuer, "user-john@example.com").Return(nil, gorm.ErrRecordNotFound)
+	test.userRepo.On("GetByEmail", "john@example.com").Return(existing, nil)
+	test.userRepo.On("Update", existing).Return(nil)
+	test.oidcRepo.On("CreateIdentity", mock.MatchedBy(func(identity *models.UserIdentity) bool {
+		return identity.UserID == 1
+	})).Return(nil)
+	test.twoFactorService.On("Enabled", uint(1)).Return(false, nil)
+
+	// Act
+	response, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), response.User.ID)
+	assert.NotNil(t, existing.EmailVerifiedAt, "the provider verified the email")
+	test.userRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestOIDCService_Callback_LinkedIdentityRequiresTwoFactor(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	req, _ := test.signIn(t, "john@example.com")
+
+	test.oidcRepo.On("GetIdentity", test.provider.Issuer, "user-john@example.com").Return(&models.UserIdentity{UserID: 1}, nil)
+	test.userRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+	test.twoFactorService.On("Enabled", uint(1)).Return(true, nil)
+
+	// Act
+	response, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "mfa_required", response.Message)
+	assert.NotEmpty(t, response.MFAToken)
+	assert.Empty(t, response.AccessToken)
+}
+
+func TestOIDCService_Callback_RejectsUnverifiedEmail(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	test.provider.AddUser(oidctest.User{Subject: "sub-456", Email: "john@example.com", EmailVerified: false})
+	req, _ := test.signIn(t, "john@example.com")
+	test.oidcRepo.On("GetIdentity", test.provider.Issuer, "sub-456").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	response, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, authServices.ErrOIDCEmailNotVerified)
+	assert.Nil(t, response)
+	test.userRepo.AssertNotCalled(t, "GetByEmail", mock.Anything)
+	test.oidcRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything)
+}
+
+func TestOIDCService_Callback_RejectsUnknownState(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	test.oidcRepo.On("TakeState", sha256Hex("forged")).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	response, err := test.service.Callback(context.Background(), &models.OIDCCallbackRequest{Code: "code", State: "forged"}, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, authServices.ErrInvalidOIDCState)
+	assert.Nil(t, response)
+}
+
+func TestOIDCService_Callback_RejectsNonceMismatch(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	req, state := test.signIn(t, "john@example.com")
+	state.Nonce = "nonce-of-another-sign-in"
+
+	// Act
+	response, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, authServices.ErrOIDCAuthenticationFailed)
+	assert.Nil(t, response)
+}
+
+func TestOIDCService_Callback_RejectsReusedCode(t *testing.T) {
+	// Arrange
+	test := newOIDCTest(t)
+	req, state := test.signIn(t, "john@example.com")
+	test.oidcRepo.On("GetIdentity", test.provider.Issuer, "user-john@example.com").Return(&models.UserIdentity{UserID: 1}, nil)
+	test.userRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+	test.twoFactorService.On("Enabled", uint(1)).Return(false, nil)
+	_, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// The state is single-use in the repository, so replay the code with a copy of it
+	test.oidcRepo.On("TakeState", sha256Hex(req.State)).Return(state, nil).Once()
+
+	// Act
+	response, err := test.service.Callback(context.Background(), req, models.ClientInfo{})
+
+	// Assert
+	assert.ErrorIs(t, err, authServices.ErrOIDCAuthenticationFailed)
+	assert.Nil(t, response)
+}
+
+func TestOIDCHandler_NotConfigured(t *testing.T) {
+	// Arrange
+	oidcService := authServices.NewOIDCService(nil, new(MockOIDCRepository), new(MockUserRepository), new(MockRoleRepository), new(MockJWTService), new(MockTwoFactorService))
+	app := fiber.New()
+	app.Get("/oidc/authorize", auth.NewOIDCHandler(oidcService).Authorize)
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/oidc/authorize", nil))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.False(t, oidcService.Enabled())
+}
@@ -0,0 +1,207 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"api/pkg"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MockPasswordResetRepository is a mock implementation of PasswordResetRepository
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetRepository) Create(ctx context.Context, token *models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetRepository) UseToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetRepository) DeleteUserTokens(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// recordingMailer keeps every message instead of delivering it
+type recordingMailer struct {
+	messages []pkg.MailMessage
+	err      error
+}
+
+func (m *recordingMailer) Send(message pkg.MailMessage) error {
+	m.messages = append(m.messages, message)
+	return m.err
+}
+
+// tokenFromMail extracts the token query parameter of the link in a mail
+func tokenFromMail(t *testing.T, message pkg.MailMessage) string {
+	t.Helper()
+	for _, field := range strings.Fields(message.Body) {
+		if link, err := url.Parse(field); err == nil && link.Query().Get("token") != "" {
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("no reset link in mail body: %q", message.Body)
+	return ""
+}
+
+func sha256Hex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestPasswordResetService_ForgotPassword_UnknownEmail(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mailer := &recordingMailer{}
+	service := auth.NewPasswordResetService(mockUserRepo, mockResetRepo, new(MockJWTService), mailer, config.Default().PasswordReset)
+
+	mockUserRepo.On("GetByEmail", "nobody@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	err := service.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: "nobody@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, mailer.messages)
+	mockResetRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestPasswordResetService_ForgotPassword_SendsLink(t *testing.T) {
+	// Arrange
+	cfg := config.Default().PasswordReset
+	cfg.URL = "https://app.example.com/reset"
+	cfg.TTL = 30 * time.Minute
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mailer := &recordingMailer{}
+	service := auth.NewPasswordResetService(mockUserRepo, mockResetRepo, new(MockJWTService), mailer, cfg)
+
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(&models.User{ID: 1, Name: "John", Email: "john@example.com"}, nil)
+	var stored *models.PasswordResetToken
+	mockResetRepo.On("Create", mock.AnythingOfType("*models.PasswordResetToken")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.PasswordResetToken)
+	}).Return(nil)
+
+	// Act
+	err := service.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: "john@example.com"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, mailer.messages, 1)
+	assert.Equal(t, "john@example.com", mailer.messages[0].To)
+	assert.Contains(t, mailer.messages[0].Body, "https://app.example.com/reset?token=")
+
+	// Only the hash of the mailed token is stored
+	token := tokenFromMail(t, mailer.messages[0])
+	require.NotNil(t, stored)
+	assert.Equal(t, uint(1), stored.UserID)
+	assert.Equal(t, sha256Hex(token), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, token)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), stored.ExpiresAt, time.Minute)
+}
+
+func TestPasswordResetService_ForgotPassword_MailFailureIsHidden(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mailer := &recordingMailer{err: errors.New("smtp unavailable")}
+	service := auth.NewPasswordResetService(mockUserRepo, mockResetRepo, new(MockJWTService), mailer, config.Default().PasswordReset)
+
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com"}, nil)
+	mockResetRepo.On("Create", mock.AnythingOfType("*models.PasswordResetToken")).Return(nil)
+
+	// Act
+	err := service.ForgotPassword(context.Background(), &models.ForgotPasswordRequest{Email: "john@example.com"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, mailer.messages, 1)
+}
+
+func TestPasswordResetService_ResetPassword_Success(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockResetRepo := new(MockPasswordResetRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewPasswordResetService(mockUserRepo, mockResetRepo, mockJWT, &recordingMailer{}, config.Default().PasswordReset)
+
+	mockResetRepo.On("UseToken", sha256Hex("reset-token")).Return(&models.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Password: "old-hash"}, nil)
+	var updated *models.User
+	mockUserRepo.On("Update", mock.AnythingOfType("*models.User")).Run(func(args mock.Arguments) {
+		updated = args.Get(0).(*models.User)
+	}).Return(nil)
+	mockJWT.On("RevokeAllTokens", uint(1)).Return(nil)
+	mockResetRepo.On("DeleteUserTokens", uint(1)).Return(nil)
+
+	// Act
+	err := service.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: "reset-token", Password: "newpassword"})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte("newpassword")))
+	mockJWT.AssertExpectations(t)
+	mockResetRepo.AssertExpectations(t)
+}
+
+func TestPasswordResetService_ResetPassword_InvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		record *models.PasswordResetToken
+		err    error
+	}{
+		{name: "unknown", err: gorm.ErrRecordNotFound},
+		{name: "used", record: &models.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt}},
+		{name: "expired", record: &models.PasswordResetToken{UserID: 1, ExpiresAt: time.Now().Add(-time.Minute)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockUserRepo := new(MockUserRepository)
+			mockResetRepo := new(MockPasswordResetRepository)
+			mockJWT := new(MockJWTService)
+			service := auth.NewPasswordResetService(mockUserRepo, mockResetRepo, mockJWT, &recordingMailer{}, config.Default().PasswordReset)
+
+			if tt.record != nil {
+				mockResetRepo.On("UseToken", sha256Hex("reset-token")).Return(tt.record, nil)
+			} else {
+				mockResetRepo.On("UseToken", sha256Hex("reset-token")).Return(nil, tt.err)
+			}
+
+			// Act
+			err := service.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: "reset-token", Password: "newpassword"})
+
+			// Assert
+			assert.ErrorIs(t, err, auth.ErrInvalidResetToken)
+			mockUserRepo.AssertNotCalled(t, "Update", mock.Anything)
+			mockJWT.AssertNotCalled(t, "RevokeAllTokens", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package auth_test
+
+import (
+	"api/internal/middlewares"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPermissionApp(permissions map[string]bool) *fiber.App {
+	app := fiber.New()
+	app.Post("/products", func(c *fiber.Ctx) error {
+		// Simulate JWTAuth resolving the permissions of the token's roles
+		if permissions != nil {
+			c.Locals("permissions", permissions)
+		}
+		return c.Next()
+	}, middlewares.RequirePermission(models.PermissionProductsWrite), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+	return app
+}
+
+func TestRequirePermission_Granted(t *testing.T) {
+	// Arrange
+	app := setupPermissionApp(map[string]bool{models.PermissionProductsWrite: true})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest("POST", "/products", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRequirePermission_Forbidden(t *testing.T) {
+	// Arrange
+	app := setupPermissionApp(map[string]bool{models.PermissionProductsRead: true})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest("POST", "/products", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestRequirePermission_WithoutJWTAuth(t *testing.T) {
+	// Arrange
+	app := setupPermissionApp(nil)
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest("POST", "/products", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+// warehouseScopeOf signs user in and returns the warehouse scope JWTAuth stores for the request
+func warehouseScopeOf(t *testing.T, user *models.User) models.WarehouseScope {
+	t.Helper()
+	jwtService := newTestJWTService(t)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("RolePermissions").Return(map[string][]string{}, nil)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, auth.NewRoleService(roleRepo, new(MockUserRepository), jwtService), auth.VerificationPolicyReadOnly)
+
+	var scope models.WarehouseScope
+	app := fiber.New()
+	app.Get("/me", jwtMiddleware.JWTAuth(), func(c *fiber.Ctx) error {
+		scope = c.Locals("warehouseScope").(models.WarehouseScope)
+		return c.SendStatus(http.StatusOK)
+	})
+
+	accessToken, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return scope
+}
+
+func TestJWTAuth_WarehouseScopeFromToken(t *testing.T) {
+	// Arrange
+	user := &models.User{
+		ID:         1,
+		Email:      "clerk@example.com",
+		Roles:      []models.Role{{Name: models.RoleClerk}},
+		Warehouses: []models.UserWarehouse{{UserID: 1, WarehouseID: 3}},
+	}
+
+	// Act
+	scope := warehouseScopeOf(t, user)
+
+	// Assert
+	assert.False(t, scope.All)
+	assert.True(t, scope.Allows(3))
+	assert.False(t, scope.Allows(4))
+}
+
+func TestJWTAuth_AdminBypassesWarehouseScope(t *testing.T) {
+	// Arrange
+	user := &models.User{ID: 1, Email: "admin@example.com", Roles: []models.Role{{Name: models.RoleAdmin}}}
+
+	// Act
+	scope := warehouseScopeOf(t, user)
+
+	// Assert
+	assert.True(t, scope.All)
+	assert.True(t, scope.Allows(4))
+}
+
+// permissionsOf signs user in and returns the status and permissions JWTAuth produces for the request under policy
+func permissionsOf(t *testing.T, user *models.User, policy auth.VerificationPolicy) (int, map[string]bool) {
+	t.Helper()
+	jwtService := newTestJWTService(t)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("RolePermissions").Return(map[string][]string{
+		models.RoleClerk: {models.PermissionProductsRead, models.PermissionProductsWrite},
+	}, nil)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, auth.NewRoleService(roleRepo, new(MockUserRepository), jwtService), policy)
+
+	var permissions map[string]bool
+	app := fiber.New()
+	app.Get("/me", jwtMiddleware.JWTAuth(), func(c *fiber.Ctx) error {
+		permissions = c.Locals("permissions").(map[string]bool)
+		return c.SendStatus(http.StatusOK)
+	})
+
+	accessToken, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp.StatusCode, permissions
+}
+
+func TestJWTAuth_UnverifiedEmailPolicy(t *testing.T) {
+	verifiedAt := time.Now()
+	tests := []struct {
+		name            string
+		policy          auth.VerificationPolicy
+		emailVerifiedAt *time.Time
+		wantStatus      int
+		wantPermissions map[string]bool
+	}{
+		{
+			name:            "read_only keeps read permissions",
+			policy:          auth.VerificationPolicyReadOnly,
+			wantStatus:      http.StatusOK,
+			wantPermissions: map[string]bool{models.PermissionProductsRead: true},
+		},
+		{
+			name:       "block rejects the token",
+			policy:     auth.VerificationPolicyBlock,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:            "allow keeps every permission",
+			policy:          auth.VerificationPolicyAllow,
+			wantStatus:      http.StatusOK,
+			wantPermissions: map[string]bool{models.PermissionProductsRead: true, models.PermissionProductsWrite: true},
+		},
+		{
+			name:            "verified users are not limited",
+			policy:          auth.VerificationPolicyBlock,
+			emailVerifiedAt: &verifiedAt,
+			wantStatus:      http.StatusOK,
+			wantPermissions: map[string]bool{models.PermissionProductsRead: true, models.PermissionProductsWrite: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			user := &models.User{ID: 1, Email: "clerk@example.com", EmailVerifiedAt: tt.emailVerifiedAt, Roles: []models.Role{{Name: models.RoleClerk}}}
+
+			// Act
+			status, permissions := permissionsOf(t, user, tt.policy)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantPermissions, permissions)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package auth_test
+
+import (
+	"api/internal/models"
+	"api/internal/services/auth"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRoleService_AssignRoles_Success(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockRoleRepo := new(MockRoleRepository)
+	mockJWT := new(MockJWTService)
+	roleService := auth.NewRoleService(mockRoleRepo, mockUserRepo, mockJWT)
+
+	roles := []models.Role{{ID: 2, Name: models.RoleWarehouseManager}}
+	mockRoleRepo.On("GetByNames", []string{models.RoleWarehouseManager}).Return(roles, nil)
+	mockUserRepo.On("GetByID", uint(7)).Return(&models.User{ID: 7, Roles: []models.Role{{ID: 4, Name: models.RoleViewer}}}, nil)
+	mockRoleRepo.On("ReplaceUserRoles", uint(7), roles).Return(nil)
+	mockJWT.On("RevokeAllTokens", uint(7)).Return(nil)
+
+	// Act
+	response, err := roleService.AssignRoles(context.Background(), 7, &models.AssignRolesRequest{Roles: []string{models.RoleWarehouseManager, models.RoleWarehouseManager}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{models.RoleWarehouseManager}, response.Roles)
+	mockRoleRepo.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestRoleService_AssignRoles_UnknownRole(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockRoleRepo := new(MockRoleRepository)
+	mockJWT := new(MockJWTService)
+	roleService := auth.NewRoleService(mockRoleRepo, mockUserRepo, mockJWT)
+
+	mockRoleRepo.On("GetByNames", []string{models.RoleClerk, "superuser"}).Return([]models.Role{{ID: 3, Name: models.RoleClerk}}, nil)
+
+	// Act
+	response, err := roleService.AssignRoles(context.Background(), 7, &models.AssignRolesRequest{Roles: []string{models.RoleClerk, "superuser"}})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrRoleNotFound)
+	assert.Contains(t, err.Error(), "superuser")
+	assert.Nil(t, response)
+	mockRoleRepo.AssertNotCalled(t, "ReplaceUserRoles", mock.Anything, mock.Anything)
+}
+
+func TestRoleService_AssignRoles_LastAdmin(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockRoleRepo := new(MockRoleRepository)
+	mockJWT := new(MockJWTService)
+	roleService := auth.NewRoleService(mockRoleRepo, mockUserRepo, mockJWT)
+
+	mockRoleRepo.On("GetByNames", []string{models.RoleViewer}).Return([]models.Role{{ID: 4, Name: models.RoleViewer}}, nil)
+	mockUserRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Roles: []models.Role{{ID: 1, Name: models.RoleAdmin}}}, nil)
+	mockRoleRepo.On("CountUsersWithRole", models.RoleAdmin).Return(int64(1), nil)
+
+	// Act
+	response, err := roleService.AssignRoles(context.Background(), 1, &models.AssignRolesRequest{Roles: []string{models.RoleViewer}})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrLastAdmin)
+	assert.Nil(t, response)
+	mockRoleRepo.AssertNotCalled(t, "ReplaceUserRoles", mock.Anything, mock.Anything)
+	mockJWT.AssertNotCalled(t, "RevokeAllTokens", mock.Anything)
+}
+
+func TestRoleService_Permissions(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockRoleRepo := new(MockRoleRepository)
+	mockJWT := new(MockJWTService)
+	roleService := auth.NewRoleService(mockRoleRepo, mockUserRepo, mockJWT)
+
+	mockRoleRepo.On("RolePermissions").Return(map[string][]string{
+		models.RoleViewer: {models.PermissionProductsRead},
+		models.RoleClerk:  {models.PermissionProductsRead, models.PermissionTransactionsWrite},
+	}, nil).Once()
+
+	// Act
+	viewer, err := roleService.Permissions(context.Background(), []string{models.RoleViewer})
+	assert.NoError(t, err)
+	combined, err := roleService.Permissions(context.Background(), []string{models.RoleViewer, models.RoleClerk, "unknown"})
+
+	// Assert: the mapping is loaded once and cached
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{models.PermissionProductsRead: true}, viewer)
+	assert.Equal(t, map[string]bool{models.PermissionProductsRead: true, models.PermissionTransactionsWrite: true}, combined)
+	mockRoleRepo.AssertExpectations(t)
+}
@@ -0,0 +1,312 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/handlers/auth"
+	"api/internal/middlewares"
+	"api/internal/models"
+	authRepositories "api/internal/repositories/auth"
+	authServices "api/internal/services/auth"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// newTestSessionServices returns a JWT service and a session service sharing one memory token store
+func newTestSessionServices(t *testing.T) (authServices.JWTService, authServices.SessionService) {
+	signingKeys, err := authServices.LoadSigningKeys(testJWTConfig(), false)
+	require.NoError(t, err)
+	tokenStore := authRepositories.NewMemoryTokenStore()
+	return authServices.NewJWTService(tokenStore, signingKeys, testJWTConfig()), authServices.NewSessionService(tokenStore)
+}
+
+func TestSessionService_ListRecordsClient(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	_, laptopRefresh, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{IPAddress: "10.0.0.1", UserAgent: "Firefox"})
+	require.NoError(t, err)
+	phoneAccess, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{IPAddress: "10.0.0.2", UserAgent: "Safari"})
+	require.NoError(t, err)
+	_, _, _, err = jwtService.GenerateTokens(context.Background(), &models.User{ID: 2, Email: "jane@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Refreshing moves the laptop session to the top with its new address
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), laptopRefresh, models.ClientInfo{IPAddress: "10.0.0.3", UserAgent: "Firefox"})
+	require.NoError(t, err)
+
+	token, err := jwtService.ValidateToken(context.Background(), phoneAccess)
+	require.NoError(t, err)
+	currentSessionID, err := jwtService.ExtractSessionID(token)
+	require.NoError(t, err)
+
+	// Act
+	sessions, err := sessionService.List(context.Background(), 1, currentSessionID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "10.0.0.3", sessions[0].IPAddress)
+	assert.Equal(t, "Firefox", sessions[0].UserAgent)
+	assert.False(t, sessions[0].Current)
+	assert.Equal(t, "Safari", sessions[1].UserAgent)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestSessionService_RevokeEndsAccessAndRefreshTokens(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	accessToken, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{UserAgent: "Firefox"})
+	require.NoError(t, err)
+	otherAccessToken, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{UserAgent: "Safari"})
+	require.NoError(t, err)
+	sessions, err := sessionService.List(context.Background(), 1, "")
+	require.NoError(t, err)
+	var firefox uint
+	for _, session := range sessions {
+		if session.UserAgent == "Firefox" {
+			firefox = session.ID
+		}
+	}
+
+	// Act
+	err = sessionService.Revoke(context.Background(), 1, firefox)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = jwtService.ValidateToken(context.Background(), accessToken)
+	assert.ErrorIs(t, err, authServices.ErrTokenRevoked)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+	assert.ErrorIs(t, err, authServices.ErrTokenRevoked)
+
+	_, err = jwtService.ValidateToken(context.Background(), otherAccessToken)
+	assert.NoError(t, err, "other sessions stay signed in")
+	sessions, err = sessionService.List(context.Background(), 1, "")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Safari", sessions[0].UserAgent)
+}
+
+func TestSessionService_RevokeOtherUsersSession(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	accessToken, _, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 2, Email: "jane@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+	sessions, err := sessionService.List(context.Background(), 2, "")
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	// Act
+	err = sessionService.Revoke(context.Background(), 1, sessions[0].ID)
+
+	// Assert
+	assert.ErrorIs(t, err, authServices.ErrSessionNotFound)
+	_, err = jwtService.ValidateToken(context.Background(), accessToken)
+	assert.NoError(t, err)
+}
+
+func TestSessionService_LogoutAllClearsSessions(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	_, _, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, jwtService.RevokeAllTokens(context.Background(), 1))
+
+	// Assert
+	sessions, err := sessionService.List(context.Background(), 1, "")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestTokenStore_PurgeExpiredKeepsWritesCheap(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	tokenStore := authRepositories.NewMemoryTokenStore()
+	require.NoError(t, tokenStore.RevokeToken(ctx, "expired-jti", 1, time.Now().Add(-time.Minute)))
+	require.NoError(t, tokenStore.SaveRefreshToken(ctx, &models.RefreshToken{JTI: "expired-refresh", UserID: 1, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Minute)}))
+
+	// Act
+	require.NoError(t, tokenStore.RevokeToken(ctx, "live-jti", 1, time.Now().Add(time.Hour)))
+	keptByWrite, err := tokenStore.IsTokenRevoked(ctx, "expired-jti")
+	require.NoError(t, err)
+	require.NoError(t, tokenStore.PurgeExpired(ctx))
+
+	// Assert
+	assert.True(t, keptByWrite, "writes no longer clean up expired rows")
+	expired, err := tokenStore.IsTokenRevoked(ctx, "expired-jti")
+	require.NoError(t, err)
+	assert.False(t, expired)
+	live, err := tokenStore.IsTokenRevoked(ctx, "live-jti")
+	require.NoError(t, err)
+	assert.True(t, live)
+	_, err = tokenStore.UseRefreshToken(ctx, "expired-refresh")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestTokenPurgeService_RunPurgesUntilCancelled(t *testing.T) {
+	// Arrange
+	tokenStore := authRepositories.NewMemoryTokenStore()
+	require.NoError(t, tokenStore.RevokeToken(context.Background(), "expired-jti", 1, time.Now().Add(-time.Minute)))
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		authServices.NewTokenPurgeService(tokenStore, 5*time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	// Assert
+	assert.Eventually(t, func() bool {
+		revoked, err := tokenStore.IsTokenRevoked(context.Background(), "expired-jti")
+		return err == nil && !revoked
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
+
+func TestAuthService_Logout_AccessTokenOnlyEndsSession(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	authService := authServices.NewAuthService(new(MockUserRepository), newMockRoleRepository(), jwtService, newMockEmailVerificationService(), authServices.VerificationPolicyReadOnly, newMockTwoFactorService())
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	accessToken, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+	_, otherRefreshToken, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act: the client sends no refresh_token
+	err = authService.Logout(context.Background(), 1, accessToken, "")
+
+	// Assert
+	require.NoError(t, err)
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), refreshToken, models.ClientInfo{})
+	assert.ErrorIs(t, err, authServices.ErrTokenRevoked)
+	sessions, err := sessionService.List(context.Background(), 1, "")
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1, "only the other session is left")
+	_, _, _, err = jwtService.RotateRefreshToken(context.Background(), otherRefreshToken, models.ClientInfo{})
+	assert.NoError(t, err)
+}
+
+func TestSessionHandler_RevokeSignsSessionOut(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("RolePermissions").Return(map[string][]string{}, nil)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, authServices.NewRoleService(roleRepo, new(MockUserRepository), jwtService), authServices.VerificationPolicyReadOnly)
+	sessionHandler := auth.NewSessionHandler(sessionService)
+
+	app := fiber.New()
+	app.Get("/sessions", jwtMiddleware.JWTAuth(), sessionHandler.List)
+	app.Delete("/sessions/:id", jwtMiddleware.JWTAuth(), sessionHandler.Revoke)
+
+	verifiedAt := time.Now()
+	user := &models.User{ID: 1, Email: "john@example.com", EmailVerifiedAt: &verifiedAt}
+	laptopToken, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{UserAgent: "Firefox"})
+	require.NoError(t, err)
+	stolenToken, _, _, err := jwtService.GenerateTokens(context.Background(), user, models.ClientInfo{UserAgent: "curl"})
+	require.NoError(t, err)
+
+	request := func(method, path, accessToken string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Act
+	resp := request(http.MethodGet, "/sessions", laptopToken)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Data []models.SessionResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 2)
+	var stolen models.SessionResponse
+	for _, session := range body.Data {
+		if session.UserAgent == "curl" {
+			stolen = session
+		} else {
+			assert.True(t, session.Current)
+		}
+	}
+	resp = request(http.MethodDelete, "/sessions/"+strconv.Itoa(int(stolen.ID)), laptopToken)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/sessions", stolenToken).StatusCode)
+	assert.Equal(t, http.StatusOK, request(http.MethodGet, "/sessions", laptopToken).StatusCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, request(http.MethodDelete, "/sessions/"+strconv.Itoa(int(stolen.ID)), laptopToken).StatusCode)
+}
+
+func TestSessionHandler_ListShowsForwardedClientIP(t *testing.T) {
+	// Arrange
+	jwtService, sessionService := newTestSessionServices(t)
+	roleRepo := new(MockRoleRepository)
+	roleRepo.On("RolePermissions").Return(map[string][]string{}, nil)
+	jwtMiddleware := middlewares.NewJWTMiddleware(jwtService, authServices.NewRoleService(roleRepo, new(MockUserRepository), jwtService), authServices.VerificationPolicyReadOnly)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	verifiedAt := time.Now()
+	userRepo := new(MockUserRepository)
+	userRepo.On("GetByEmail", "john@example.com").Return(&models.User{ID: 1, Email: "john@example.com", Password: string(hashedPassword), EmailVerifiedAt: &verifiedAt}, nil)
+	authService := authServices.NewAuthService(userRepo, newMockRoleRepository(), jwtService, newMockEmailVerificationService(), authServices.VerificationPolicyReadOnly, newMockTwoFactorService())
+	authHandler := auth.NewAuthHandler(authService, newTestLoginAttemptService(t))
+
+	// app.Test connects from 0.0.0.0, which plays the reverse proxy here
+	app := fiber.New(middlewares.TrustProxy(fiber.Config{}, config.ProxyConfig{Header: "X-Real-IP", TrustedProxies: []string{"0.0.0.0"}}))
+	app.Post("/signin", authHandler.SignIn)
+	app.Get("/sessions", jwtMiddleware.JWTAuth(), auth.NewSessionHandler(sessionService).List)
+
+	reqBody, err := json.Marshal(models.AuthRequest{Email: "john@example.com", Password: "password123"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/signin", bytes.NewReader(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Firefox")
+	req.Header.Set("X-Real-IP", "203.0.113.7")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var signIn struct {
+		Data models.AuthResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&signIn))
+
+	// Act
+	req = httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+signIn.Data.AccessToken)
+	resp, err = app.Test(req)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Data []models.SessionResponse `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "203.0.113.7", body.Data[0].IPAddress, "the client behind the proxy, not the proxy")
+	assert.Equal(t, "Firefox", body.Data[0].UserAgent)
+	assert.True(t, body.Data[0].Current)
+}
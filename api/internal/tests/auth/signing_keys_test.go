@@ -0,0 +1,181 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/handlers/auth"
+	"api/internal/models"
+	authRepositories "api/internal/repositories/auth"
+	authServices "api/internal/services/auth"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePEM writes a PEM block to a file in dir and returns its path
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+// writeEd25519Keys writes a PKCS#8 private key and a PKIX public key and returns their paths
+func writeEd25519Keys(t *testing.T, dir, name string) (privatePath, publicPath string) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return writePEM(t, dir, name+".key", "PRIVATE KEY", privateDER), writePEM(t, dir, name+".pub", "PUBLIC KEY", publicDER)
+}
+
+// newJWTServiceWithKeys loads the signing keys of cfg, which has the default token lifetimes
+func newJWTServiceWithKeys(t *testing.T, cfg config.JWTConfig) (authServices.JWTService, *authServices.SigningKeys) {
+	signingKeys, err := authServices.LoadSigningKeys(cfg, false)
+	require.NoError(t, err)
+	return authServices.NewJWTService(authRepositories.NewMemoryTokenStore(), signingKeys, cfg), signingKeys
+}
+
+func TestLoadSigningKeys_RefusesDefaultSecrets(t *testing.T) {
+	cfg := config.Default().JWT
+	cfg.Secret = "your-secret-key-change-in-production"
+
+	_, err := authServices.LoadSigningKeys(cfg, false)
+	assert.ErrorContains(t, err, "JWT_SECRET")
+
+	signingKeys, err := authServices.LoadSigningKeys(cfg, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "HS256", signingKeys.Algorithm())
+	assert.Empty(t, signingKeys.JWKS().Keys)
+}
+
+func TestJWTService_RS256SetsKeyID(t *testing.T) {
+	// Arrange
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	cfg := config.Default().JWT
+	cfg.PrivateKeyFile = writePEM(t, t.TempDir(), "jwt.key", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey))
+	jwtService, signingKeys := newJWTServiceWithKeys(t, cfg)
+
+	// Act
+	accessToken, refreshToken, _, err := jwtService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Assert
+	token, err := jwtService.ValidateToken(context.Background(), accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", token.Method.Alg())
+	jwks := signingKeys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, jwks.Keys[0].KeyID, token.Header["kid"])
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, "AQAB", jwks.Keys[0].Exponent)
+
+	_, err = jwtService.ValidateToken(context.Background(), refreshToken)
+	assert.ErrorIs(t, err, authServices.ErrInvalidToken)
+	_, err = jwtService.ValidateRefreshToken(context.Background(), refreshToken)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_EdDSAKeyRotation(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeEd25519Keys(t, dir, "old")
+	newPrivate, _ := writeEd25519Keys(t, dir, "new")
+	cfg := config.Default().JWT
+	cfg.PrivateKeyFile = oldPrivate
+	oldService, _ := newJWTServiceWithKeys(t, cfg)
+	oldToken, _, _, err := oldService.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+
+	// Act
+	cfg.PrivateKeyFile = newPrivate
+	withoutOldKey, _ := newJWTServiceWithKeys(t, cfg)
+	cfg.PublicKeyFiles = []string{oldPublic}
+	rotated, signingKeys := newJWTServiceWithKeys(t, cfg)
+
+	// Assert
+	_, err = withoutOldKey.ValidateToken(context.Background(), oldToken)
+	assert.ErrorIs(t, err, authServices.ErrInvalidToken)
+
+	token, err := rotated.ValidateToken(context.Background(), oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", token.Method.Alg())
+
+	newToken, _, _, err := rotated.GenerateTokens(context.Background(), &models.User{ID: 1, Email: "john@example.com"}, models.ClientInfo{})
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &authServices.Claims{})
+	require.NoError(t, err)
+	jwks := signingKeys.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, jwks.Keys[0].KeyID, parsed.Header["kid"], "the signing key is listed first")
+	assert.NotEqual(t, jwks.Keys[0].KeyID, token.Header["kid"])
+	assert.Equal(t, jwks.Keys[1].KeyID, token.Header["kid"])
+}
+
+func TestJWTService_RejectsHS256TokenWithAsymmetricKeys(t *testing.T) {
+	// Arrange
+	privatePath, publicPath := writeEd25519Keys(t, t.TempDir(), "jwt")
+	publicPEM, err := os.ReadFile(publicPath)
+	require.NoError(t, err)
+	cfg := config.Default().JWT
+	cfg.PrivateKeyFile = privatePath
+	jwtService, signingKeys := newJWTServiceWithKeys(t, cfg)
+
+	// The public key is known to everyone; it must not work as an HMAC secret
+	claims := authServices.Claims{UserID: 1, Type: "access", RegisteredClaims: jwt.RegisteredClaims{ID: "forged"}}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = signingKeys.JWKS().Keys[0].KeyID
+	forgedToken, err := forged.SignedString(publicPEM)
+	require.NoError(t, err)
+
+	// Act
+	_, err = jwtService.ValidateToken(context.Background(), forgedToken)
+
+	// Assert
+	assert.ErrorIs(t, err, authServices.ErrInvalidToken)
+}
+
+func TestJWKSHandler_ServesPublicKeys(t *testing.T) {
+	// Arrange
+	privatePath, _ := writeEd25519Keys(t, t.TempDir(), "jwt")
+	cfg := config.Default().JWT
+	cfg.PrivateKeyFile = privatePath
+	_, signingKeys := newJWTServiceWithKeys(t, cfg)
+
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", auth.NewJWKSHandler(signingKeys).JWKS)
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json"))
+	var body map[string][]map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body["keys"], 1)
+	key := body["keys"][0]
+	assert.Equal(t, "OKP", key["kty"])
+	assert.Equal(t, "Ed25519", key["crv"])
+	assert.Equal(t, "EdDSA", key["alg"])
+	assert.Equal(t, "sig", key["use"])
+	assert.NotEmpty(t, key["x"])
+	assert.NotContains(t, key, "d")
+}
@@ -0,0 +1,371 @@
+package auth_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/services/auth"
+	"api/pkg"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// MockTwoFactorRepository is a mock implementation of TwoFactorRepository
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) GetByUserID(ctx context.Context, userID uint) (*models.UserTOTP, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserTOTP), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) SavePending(ctx context.Context, totp *models.UserTOTP) error {
+	args := m.Called(totp)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Confirm(ctx context.Context, userID uint, confirmedAt time.Time, codes []models.RecoveryCode) error {
+	args := m.Called(userID, confirmedAt, codes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) MarkStepUsed(ctx context.Context, userID uint, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Delete(ctx context.Context, userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+// MockTwoFactorService is a mock implementation of TwoFactorService
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) Enabled(ctx context.Context, userID uint) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Enroll(ctx context.Context, userID uint) (*models.TwoFactorEnrollResponse, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TwoFactorEnrollResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Confirm(ctx context.Context, userID uint, req *models.TwoFactorCodeRequest) (*models.RecoveryCodesResponse, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RecoveryCodesResponse), args.Error(1)
+}
+
+func (m *MockTwoFactorService) Disable(ctx context.Context, userID uint, req *models.TwoFactorCodeRequest) error {
+	args := m.Called(userID, req)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) Verify(ctx context.Context, req *models.TwoFactorVerifyRequest, client models.ClientInfo) (*models.AuthResponse, error) {
+	args := m.Called(req, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuthResponse), args.Error(1)
+}
+
+// newMockTwoFactorService returns a two-factor service for users without two-factor sign-in
+func newMockTwoFactorService() *MockTwoFactorService {
+	twoFactorService := new(MockTwoFactorService)
+	twoFactorService.On("Enabled", mock.AnythingOfType("uint")).Return(false, nil).Maybe()
+	return twoFactorService
+}
+
+// confirmedTOTP returns a confirmed enrollment with a fresh secret
+func confirmedTOTP(t *testing.T, userID uint) *models.UserTOTP {
+	secret, err := pkg.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now().Add(-time.Hour)
+	return &models.UserTOTP{UserID: userID, Secret: secret, ConfirmedAt: &confirmedAt}
+}
+
+func currentTOTPCode(t *testing.T, secret string) string {
+	code, err := pkg.TOTPCode(secret, pkg.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1 secret "12345678901234567890", truncated to 6 digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+
+	for unix, expected := range vectors {
+		code, err := pkg.TOTPCode(secret, pkg.TOTPStep(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	mockRepo := new(MockUserRepository)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, mockRepo, new(MockJWTService), newTestLoginAttemptService(t), config.Default().TOTP)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "admin@example.com"}, nil)
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(nil, gorm.ErrRecordNotFound)
+	var stored *models.UserTOTP
+	mockTwoFactorRepo.On("SavePending", mock.AnythingOfType("*models.UserTOTP")).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(*models.UserTOTP)
+	}).Return(nil)
+
+	// Act
+	response, err := service.Enroll(context.Background(), 1)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Nil(t, stored.ConfirmedAt)
+	assert.Equal(t, stored.Secret, response.Secret)
+	assert.True(t, strings.HasPrefix(response.OTPAuthURI, "otpauth://totp/pseudo-app:admin@example.com?"))
+	assert.Contains(t, response.OTPAuthURI, "secret="+response.Secret)
+}
+
+func TestTwoFactorService_Enroll_AlreadyEnabled(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	mockRepo := new(MockUserRepository)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, mockRepo, new(MockJWTService), newTestLoginAttemptService(t), config.Default().TOTP)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "admin@example.com"}, nil)
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(confirmedTOTP(t, 1), nil)
+
+	// Act
+	response, err := service.Enroll(context.Background(), 1)
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrTwoFactorAlreadyEnabled)
+	assert.Nil(t, response)
+	mockTwoFactorRepo.AssertNotCalled(t, "SavePending", mock.Anything)
+}
+
+func TestTwoFactorService_Confirm_StoresHashedRecoveryCodes(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, new(MockUserRepository), new(MockJWTService), newTestLoginAttemptService(t), config.Default().TOTP)
+
+	pending := confirmedTOTP(t, 1)
+	pending.ConfirmedAt = nil
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(pending, nil)
+	mockTwoFactorRepo.On("MarkStepUsed", uint(1), mock.AnythingOfType("int64")).Return(true, nil)
+	var stored []models.RecoveryCode
+	mockTwoFactorRepo.On("Confirm", uint(1), mock.AnythingOfType("time.Time"), mock.AnythingOfType("[]models.RecoveryCode")).Run(func(args mock.Arguments) {
+		stored = args.Get(2).([]models.RecoveryCode)
+	}).Return(nil)
+
+	// Act
+	response, err := service.Confirm(context.Background(), 1, &models.TwoFactorCodeRequest{Code: currentTOTPCode(t, pending.Secret)})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, response.RecoveryCodes, 10)
+	require.Len(t, stored, 10)
+	for i, code := range response.RecoveryCodes {
+		assert.Equal(t, sha256Hex(strings.ReplaceAll(code, "-", "")), stored[i].CodeHash)
+		assert.NotContains(t, stored[i].CodeHash, code)
+	}
+}
+
+func TestTwoFactorService_Confirm_InvalidCode(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, new(MockUserRepository), new(MockJWTService), newTestLoginAttemptService(t), config.Default().TOTP)
+
+	pending := confirmedTOTP(t, 1)
+	pending.ConfirmedAt = nil
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(pending, nil)
+
+	// Act
+	response, err := service.Confirm(context.Background(), 1, &models.TwoFactorCodeRequest{Code: "not-a-code"})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrInvalidTwoFactorCode)
+	assert.Nil(t, response)
+	mockTwoFactorRepo.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTwoFactorService_Verify_IssuesTokens(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, mockRepo, mockJWT, newTestLoginAttemptService(t), config.Default().TOTP)
+
+	user := &models.User{ID: 1, Email: "admin@example.com"}
+	totp := confirmedTOTP(t, 1)
+	mfaToken := claimsToken(1, "mfa_pending")
+	mockJWT.On("ValidateMFAToken", "mfa-token").Return(mfaToken, nil)
+	mockJWT.On("ExtractUserID", mfaToken).Return(uint(1), nil)
+	mockJWT.On("RevokeToken", mfaToken).Return(nil)
+	mockJWT.On("GenerateTokens", user, mock.Anything).Return("access_token", "refresh_token", int64(900), nil)
+	mockRepo.On("GetByID", uint(1)).Return(user, nil)
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(totp, nil)
+	mockTwoFactorRepo.On("MarkStepUsed", uint(1), pkg.TOTPStep(time.Now())).Return(true, nil)
+
+	// Act
+	response, err := service.Verify(context.Background(), &models.TwoFactorVerifyRequest{MFAToken: "mfa-token", Code: currentTOTPCode(t, totp.Secret)}, models.ClientInfo{IPAddress: "10.0.0.1"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "access_token", response.AccessToken)
+	assert.Equal(t, "refresh_token", response.RefreshToken)
+	assert.Empty(t, response.MFAToken)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestTwoFactorService_Verify_RejectsReplayedCode(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, mockRepo, mockJWT, newTestLoginAttemptService(t), config.Default().TOTP)
+
+	totp := confirmedTOTP(t, 1)
+	mfaToken := claimsToken(1, "mfa_pending")
+	mockJWT.On("ValidateMFAToken", "mfa-token").Return(mfaToken, nil)
+	mockJWT.On("ExtractUserID", mfaToken).Return(uint(1), nil)
+	mockRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "admin@example.com"}, nil)
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(totp, nil)
+	mockTwoFactorRepo.On("MarkStepUsed", uint(1), mock.AnythingOfType("int64")).Return(false, nil)
+
+	// Act
+	response, err := service.Verify(context.Background(), &models.TwoFactorVerifyRequest{MFAToken: "mfa-token", Code: currentTOTPCode(t, totp.Secret)}, models.ClientInfo{IPAddress: "10.0.0.1"})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrInvalidTwoFactorCode)
+	assert.Nil(t, response)
+	mockJWT.AssertNotCalled(t, "GenerateTokens", mock.Anything, mock.Anything)
+}
+
+func TestTwoFactorService_Verify_AcceptsRecoveryCode(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, mockRepo, mockJWT, newTestLoginAttemptService(t), config.Default().TOTP)
+
+	user := &models.User{ID: 1, Email: "admin@example.com"}
+	mfaToken := claimsToken(1, "mfa_pending")
+	mockJWT.On("ValidateMFAToken", "mfa-token").Return(mfaToken, nil)
+	mockJWT.On("ExtractUserID", mfaToken).Return(uint(1), nil)
+	mockJWT.On("RevokeToken", mfaToken).Return(nil)
+	mockJWT.On("GenerateTokens", user, mock.Anything).Return("access_token", "refresh_token", int64(900), nil)
+	mockRepo.On("GetByID", uint(1)).Return(user, nil)
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(confirmedTOTP(t, 1), nil)
+	mockTwoFactorRepo.On("UseRecoveryCode", uint(1), sha256Hex("abcdefghijklmnop")).Return(true, nil)
+
+	// Act
+	response, err := service.Verify(context.Background(), &models.TwoFactorVerifyRequest{MFAToken: "mfa-token", Code: "ABCD-EFGH-IJKL-MNOP"}, models.ClientInfo{IPAddress: "10.0.0.1"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "access_token", response.AccessToken)
+}
+
+func TestTwoFactorService_Verify_LocksAfterRepeatedWrongCodes(t *testing.T) {
+	// Arrange
+	mockTwoFactorRepo := new(MockTwoFactorRepository)
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewTwoFactorService(mockTwoFactorRepo, mockRepo, mockJWT, newTestLoginAttemptService(t), config.Default().TOTP)
+
+	mfaToken := claimsToken(1, "mfa_pending")
+	mockJWT.On("ValidateMFAToken", "mfa-token").Return(mfaToken, nil)
+	mockJWT.On("ExtractUserID", mfaToken).Return(uint(1), nil)
+	mockRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1, Email: "admin@example.com"}, nil)
+	mockTwoFactorRepo.On("GetByUserID", uint(1)).Return(confirmedTOTP(t, 1), nil)
+	mockTwoFactorRepo.On("UseRecoveryCode", uint(1), mock.AnythingOfType("string")).Return(false, nil)
+	req := &models.TwoFactorVerifyRequest{MFAToken: "mfa-token", Code: "wrong-recovery-code"}
+
+	// Act
+	for i := 0; i < 2; i++ {
+		_, err := service.Verify(context.Background(), req, models.ClientInfo{IPAddress: "10.0.0.1"})
+		require.ErrorIs(t, err, auth.ErrInvalidTwoFactorCode)
+	}
+	_, lockingErr := service.Verify(context.Background(), req, models.ClientInfo{IPAddress: "10.0.0.1"})
+	_, err := service.Verify(context.Background(), req, models.ClientInfo{IPAddress: "10.0.0.1"})
+
+	// Assert
+	var lockedErr *auth.LockedError
+	require.ErrorAs(t, lockingErr, &lockedErr)
+	assert.Equal(t, 30*time.Second, lockedErr.RetryAfter)
+	require.ErrorAs(t, err, &lockedErr)
+	assert.Greater(t, lockedErr.RetryAfter, time.Duration(0))
+	mockTwoFactorRepo.AssertNumberOfCalls(t, "UseRecoveryCode", 3)
+}
+
+func TestTwoFactorService_Verify_InvalidMFAToken(t *testing.T) {
+	// Arrange
+	mockJWT := new(MockJWTService)
+	service := auth.NewTwoFactorService(new(MockTwoFactorRepository), new(MockUserRepository), mockJWT, newTestLoginAttemptService(t), config.Default().TOTP)
+
+	mockJWT.On("ValidateMFAToken", "access-token").Return(nil, auth.ErrInvalidToken)
+
+	// Act
+	response, err := service.Verify(context.Background(), &models.TwoFactorVerifyRequest{MFAToken: "access-token", Code: "123456"}, models.ClientInfo{IPAddress: "10.0.0.1"})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrInvalidMFAToken)
+	assert.Nil(t, response)
+}
+
+func TestAuthService_Login_TwoFactorReturnsMFAToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTService)
+	twoFactorService := new(MockTwoFactorService)
+	authService := auth.NewAuthService(mockRepo, newMockRoleRepository(), mockJWT, newMockEmailVerificationService(), auth.VerificationPolicyReadOnly, twoFactorService)
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	user := &models.User{ID: 1, Email: "admin@example.com", Password: string(hashedPassword)}
+	mockRepo.On("GetByEmail", user.Email).Return(user, nil)
+	twoFactorService.On("Enabled", uint(1)).Return(true, nil)
+	mockJWT.On("GenerateMFAToken", user).Return("mfa-token", nil)
+
+	// Act
+	response, err := authService.Login(context.Background(), &models.AuthRequest{Email: user.Email, Password: "password123"}, models.ClientInfo{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "mfa-token", response.MFAToken)
+	assert.Empty(t, response.AccessToken)
+	assert.Empty(t, response.RefreshToken)
+	mockJWT.AssertNotCalled(t, "GenerateTokens", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,90 @@
+package auth_test
+
+import (
+	"api/internal/models"
+	"api/internal/services/auth"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockUserWarehouseRepository is a mock implementation of UserWarehouseRepository
+type MockUserWarehouseRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserWarehouseRepository) GetUserWarehouseIDs(ctx context.Context, userID uint) ([]uint, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockUserWarehouseRepository) ReplaceUserWarehouses(ctx context.Context, userID uint, warehouseIDs []uint) error {
+	args := m.Called(userID, warehouseIDs)
+	return args.Error(0)
+}
+
+func (m *MockUserWarehouseRepository) ExistingWarehouseIDs(ctx context.Context, warehouseIDs []uint) ([]uint, error) {
+	args := m.Called(warehouseIDs)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func TestUserWarehouseService_AssignWarehouses_Success(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockUserWarehouseRepo := new(MockUserWarehouseRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewUserWarehouseService(mockUserWarehouseRepo, mockUserRepo, mockJWT)
+
+	mockUserWarehouseRepo.On("ExistingWarehouseIDs", []uint{1, 3}).Return([]uint{1, 3}, nil)
+	mockUserRepo.On("GetByID", uint(7)).Return(&models.User{ID: 7}, nil)
+	mockUserWarehouseRepo.On("ReplaceUserWarehouses", uint(7), []uint{1, 3}).Return(nil)
+	mockJWT.On("RevokeAllTokens", uint(7)).Return(nil)
+
+	// Act
+	response, err := service.AssignWarehouses(context.Background(), 7, &models.AssignWarehousesRequest{WarehouseIDs: []uint{3, 1, 3}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 3}, response.WarehouseIDs)
+	mockUserWarehouseRepo.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestUserWarehouseService_AssignWarehouses_UnknownWarehouse(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockUserWarehouseRepo := new(MockUserWarehouseRepository)
+	mockJWT := new(MockJWTService)
+	service := auth.NewUserWarehouseService(mockUserWarehouseRepo, mockUserRepo, mockJWT)
+
+	mockUserWarehouseRepo.On("ExistingWarehouseIDs", []uint{1, 9}).Return([]uint{1}, nil)
+
+	// Act
+	response, err := service.AssignWarehouses(context.Background(), 7, &models.AssignWarehousesRequest{WarehouseIDs: []uint{1, 9}})
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrWarehouseNotFound)
+	assert.Contains(t, err.Error(), "9")
+	assert.Nil(t, response)
+	mockUserWarehouseRepo.AssertNotCalled(t, "ReplaceUserWarehouses", mock.Anything, mock.Anything)
+	mockJWT.AssertNotCalled(t, "RevokeAllTokens", mock.Anything)
+}
+
+func TestUserWarehouseService_GetUserWarehouses_UserNotFound(t *testing.T) {
+	// Arrange
+	mockUserRepo := new(MockUserRepository)
+	mockUserWarehouseRepo := new(MockUserWarehouseRepository)
+	service := auth.NewUserWarehouseService(mockUserWarehouseRepo, mockUserRepo, new(MockJWTService))
+
+	mockUserRepo.On("GetByID", uint(7)).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	response, err := service.GetUserWarehouses(context.Background(), 7)
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrUserNotFound)
+	assert.Nil(t, response)
+}
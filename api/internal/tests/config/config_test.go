@@ -0,0 +1,381 @@
+package config_test
+
+import (
+	"api/config"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configKeys are cleared for every test so the developer's environment does not leak in
+var configKeys = []string{
+	"CONFIG_FILE", "APP_HOST", "APP_PORT", "APP_DEBUG", "APP_SHUTDOWN_TIMEOUT",
+	"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME", "DB_MAX_IDLE_CONNS", "DB_MAX_OPEN_CONNS", "DB_CONN_MAX_LIFETIME",
+	"JWT_SECRET", "JWT_REFRESH_SECRET", "JWT_PRIVATE_KEY_FILE", "JWT_PUBLIC_KEY_FILES", "JWT_ACCESS_TTL", "JWT_REFRESH_TTL",
+	"AUTH_TOKEN_STORE", "AUTH_LOGIN_ATTEMPT_STORE",
+	"CORS_ALLOW_ORIGINS", "CORS_ALLOW_METHODS", "CORS_ALLOW_HEADERS", "CORS_EXPOSE_HEADERS", "CORS_ALLOW_CREDENTIALS", "CORS_MAX_AGE", "CORS_GROUPS",
+	"UPLOAD_DIR", "UPLOAD_MAX_IMAGE_SIZE", "LOG_LEVEL", "LOG_FORMAT", "APP_LOGGER_LOCATION",
+	"LOG_STDOUT", "LOG_MAX_SIZE", "LOG_MAX_AGE", "LOG_MAX_BACKUPS", "LOG_SLOW_QUERY",
+	"MAIL_DRIVER", "MAIL_LOG_PATH", "MAIL_FROM", "SMTP_HOST", "SMTP_PORT", "SMTP_USERNAME", "SMTP_PASSWORD",
+	"PASSWORD_RESET_URL", "PASSWORD_RESET_TTL", "EMAIL_VERIFICATION_POLICY", "EMAIL_VERIFICATION_URL", "EMAIL_VERIFICATION_TTL",
+	"LOGIN_MAX_ATTEMPTS", "LOGIN_IP_MAX_ATTEMPTS", "LOGIN_LOCKOUT_BASE_SECONDS", "LOGIN_LOCKOUT_MAX_SECONDS", "LOGIN_ATTEMPT_WINDOW_MINUTES",
+	"TOTP_ISSUER", "OIDC_ISSUER_URL", "OIDC_CLIENT_ID", "OIDC_CLIENT_SECRET", "OIDC_REDIRECT_URL", "OIDC_SCOPES",
+	"PROXY_HEADER", "TRUSTED_PROXIES",
+}
+
+// setupConfigDir runs the test in an empty directory with every config key unset.
+// t.Setenv restores the keys afterwards, including the ones Load sets from .env.
+func setupConfigDir(t *testing.T) string {
+	for _, key := range configKeys {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+	return dir
+}
+
+func setRequiredDatabaseKeys(t *testing.T) {
+	t.Setenv("DB_HOST", "localhost")
+	t.Setenv("DB_USER", "root")
+	t.Setenv("DB_NAME", "pseudo")
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:8080", cfg.App.Address())
+	assert.Equal(t, 100, cfg.Database.MaxOpenConns)
+	assert.Equal(t, time.Hour, cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, 15*time.Minute, cfg.JWT.AccessTTL)
+	assert.Equal(t, "database", cfg.Auth.TokenStore)
+	assert.Equal(t, []string{"*"}, cfg.CORS.AllowOrigins)
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	t.Setenv("DB_USER", "root")
+	t.Setenv("APP_PORT", "http")
+	t.Setenv("DB_MAX_IDLE_CONNS", "20")
+	t.Setenv("DB_MAX_OPEN_CONNS", "5")
+	t.Setenv("JWT_ACCESS_TTL", "15m")
+	t.Setenv("AUTH_TOKEN_STORE", "redis")
+	t.Setenv("LOG_STDOUT", "false")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	assert.Nil(t, cfg)
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		`APP_PORT must be a whole number, got "http"`,
+		"DB_HOST is required",
+		"DB_NAME is required",
+		"DB_MAX_IDLE_CONNS (20) must not exceed DB_MAX_OPEN_CONNS (5)",
+		`JWT_ACCESS_TTL must be a positive whole number of minutes, got "15m"`,
+		`AUTH_TOKEN_STORE must be one of database, memory, got "redis"`,
+		"LOG_STDOUT cannot be false without APP_LOGGER_LOCATION, logs would go nowhere",
+	}, validationErr.Problems)
+}
+
+func TestLoad_SourcePrecedence(t *testing.T) {
+	// Arrange
+	dir := setupConfigDir(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_HOST=dotenv-host\nDB_USER=dotenv-user\nCONFIG_FILE=app.yaml\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(`
+database:
+  host: yaml-host
+  name: yaml-db
+  max_open_conns: 50
+  conn_max_lifetime: 30m
+cors:
+  allow_origins:
+    - https://app.example.com
+    - https://admin.example.com
+`), 0600))
+	t.Setenv("DB_USER", "env-user")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "env-user", cfg.Database.User, "environment beats .env")
+	assert.Equal(t, "dotenv-host", cfg.Database.Host, ".env beats the config file")
+	assert.Equal(t, "yaml-db", cfg.Database.Name)
+	assert.Equal(t, 50, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 30*time.Minute, cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, cfg.CORS.AllowOrigins)
+
+	sources := map[string]string{}
+	for _, setting := range cfg.Settings() {
+		sources[setting.Key] = setting.Source
+	}
+	assert.Equal(t, "env", sources["DB_USER"])
+	assert.Equal(t, ".env", sources["DB_HOST"])
+	assert.Equal(t, "app.yaml", sources["DB_NAME"])
+	assert.Equal(t, "default", sources["DB_PORT"])
+}
+
+func TestLoad_RejectsUnknownFileKeys(t *testing.T) {
+	// Arrange
+	dir := setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte("database:\n  max_open_conn: 50\n"), 0600))
+	t.Setenv("CONFIG_FILE", "app.yaml")
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	assert.ErrorContains(t, err, `unknown key "database.max_open_conn" in app.yaml`)
+}
+
+func TestLoad_CORSGroupInheritsPolicy(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://*.example.com,http://localhost:3000")
+	t.Setenv("CORS_MAX_AGE", "600")
+	t.Setenv("CORS_GROUPS", "auth")
+	t.Setenv("CORS_AUTH_PATH", "/api/v1/auth")
+	t.Setenv("CORS_AUTH_ALLOW_ORIGINS", "https://app.example.com")
+	t.Setenv("CORS_AUTH_ALLOW_CREDENTIALS", "true")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://*.example.com", "http://localhost:3000"}, cfg.CORS.AllowOrigins)
+	require.Len(t, cfg.CORS.Groups, 1)
+	group := cfg.CORS.Groups[0]
+	assert.Equal(t, "/api/v1/auth", group.Path)
+	assert.Equal(t, []string{"https://app.example.com"}, group.AllowOrigins)
+	assert.True(t, group.AllowCredentials)
+	assert.Equal(t, 600, group.MaxAge, "inherited from CORS_MAX_AGE")
+	assert.Equal(t, cfg.CORS.AllowMethods, group.AllowMethods)
+}
+
+func TestLoad_RejectsInsecureCORS(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_GROUPS", "auth,Admin")
+	t.Setenv("CORS_AUTH_PATH", "api/v1/auth")
+	t.Setenv("CORS_AUTH_ALLOW_ORIGINS", "app.example.com,https://app.*.com")
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		"CORS_ALLOW_ORIGINS cannot contain * when CORS_ALLOW_CREDENTIALS is true; list the origins instead",
+		`CORS_AUTH_PATH must start with /, got "api/v1/auth"`,
+		`CORS_AUTH_ALLOW_ORIGINS has invalid origin "app.example.com", expected scheme://host[:port] such as https://app.example.com or https://*.example.com`,
+		`CORS_AUTH_ALLOW_ORIGINS has invalid origin "https://app.*.com", expected scheme://host[:port] such as https://app.example.com or https://*.example.com`,
+		`CORS_GROUPS has invalid group name "Admin", use lowercase letters, digits and _`,
+	}, validationErr.Problems)
+}
+
+func TestLoad_RejectsWildcardWithOtherOrigins(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	t.Setenv("CORS_ALLOW_ORIGINS", "*,https://app.example.com")
+	t.Setenv("CORS_GROUPS", "auth")
+	t.Setenv("CORS_AUTH_PATH", "/api/v1/auth")
+	t.Setenv("CORS_AUTH_ALLOW_ORIGINS", "https://app.example.com")
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		"CORS_ALLOW_ORIGINS cannot combine * with other origins; use * alone or list the origins",
+	}, validationErr.Problems, "the auth group overrides the origins and stays valid")
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	t.Setenv("PROXY_HEADER", "X-Real-IP")
+	t.Setenv("TRUSTED_PROXIES", "172.16.0.0/12, 10.0.0.5")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "X-Real-IP", cfg.Proxy.Header)
+	assert.Equal(t, []string{"172.16.0.0/12", "10.0.0.5"}, cfg.Proxy.TrustedProxies)
+}
+
+func TestLoad_RejectsUntrustedProxyHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		proxies string
+		want    []string
+	}{
+		{
+			name:   "header without trusted proxies",
+			header: "X-Real-IP",
+			want:   []string{"TRUSTED_PROXIES is required when PROXY_HEADER is set, otherwise any client could set its own IP"},
+		},
+		{
+			name:    "trusted proxies without header",
+			proxies: "172.16.0.0/12",
+			want:    []string{"PROXY_HEADER is required when TRUSTED_PROXIES is set"},
+		},
+		{
+			name:    "invalid entry",
+			header:  "X-Real-IP",
+			proxies: "172.16.0.0/40,nginx",
+			want: []string{
+				`TRUSTED_PROXIES has invalid entry "172.16.0.0/40", expected an IP address or CIDR range such as 172.16.0.0/12`,
+				`TRUSTED_PROXIES has invalid entry "nginx", expected an IP address or CIDR range such as 172.16.0.0/12`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			setupConfigDir(t)
+			setRequiredDatabaseKeys(t)
+			t.Setenv("PROXY_HEADER", tt.header)
+			t.Setenv("TRUSTED_PROXIES", tt.proxies)
+
+			// Act
+			_, err := config.Load()
+
+			// Assert
+			var validationErr *config.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.want, validationErr.Problems)
+		})
+	}
+}
+
+func TestLoad_FeatureSettings(t *testing.T) {
+	// Arrange
+	dir := setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app.yaml"), []byte(`
+mail:
+  driver: smtp
+  from: no-reply@example.com
+  smtp_host: smtp.example.com
+  smtp_port: 2525
+password_reset:
+  url: https://app.example.com/reset
+  ttl: 30
+email_verification:
+  policy: block
+login_attempts:
+  max_attempts: 3
+  lockout_base_seconds: 10
+totp:
+  issuer: Pseudo
+oidc:
+  issuer_url: https://accounts.example.com
+  client_id: pseudo
+  redirect_url: https://api.example.com/api/v1/auth/oidc/callback
+  scopes: [openid, email]
+`), 0600))
+	t.Setenv("CONFIG_FILE", "app.yaml")
+	t.Setenv("SMTP_PASSWORD", "mail-secret")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "smtp", cfg.Mail.Driver)
+	assert.Equal(t, 2525, cfg.Mail.SMTPPort)
+	assert.Equal(t, "mail-secret", cfg.Mail.SMTPPassword)
+	assert.Equal(t, 30*time.Minute, cfg.PasswordReset.TTL)
+	assert.Equal(t, "block", cfg.EmailVerification.Policy)
+	assert.Equal(t, 24*time.Hour, cfg.EmailVerification.TTL)
+	assert.Equal(t, 3, cfg.LoginAttempts.MaxAttempts)
+	assert.Equal(t, 10*time.Second, cfg.LoginAttempts.LockoutBase)
+	assert.Equal(t, "Pseudo", cfg.TOTP.Issuer)
+	assert.Equal(t, []string{"openid", "email"}, cfg.OIDC.Scopes)
+	assert.NotContains(t, cfg.String(), "mail-secret")
+}
+
+func TestLoad_ReportsFeatureProblems(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	t.Setenv("MAIL_DRIVER", "smtp")
+	t.Setenv("SMTP_PORT", "70000")
+	t.Setenv("PASSWORD_RESET_URL", "app.example.com/reset")
+	t.Setenv("EMAIL_VERIFICATION_POLICY", "blok")
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "abc")
+	t.Setenv("LOGIN_LOCKOUT_BASE_SECONDS", "600")
+	t.Setenv("LOGIN_LOCKOUT_MAX_SECONDS", "60")
+	t.Setenv("OIDC_ISSUER_URL", "https://accounts.example.com")
+	t.Setenv("OIDC_SCOPES", "email profile")
+
+	// Act
+	_, err := config.Load()
+
+	// Assert
+	var validationErr *config.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ElementsMatch(t, []string{
+		"SMTP_PORT must be between 1 and 65535, got 70000",
+		"SMTP_HOST is required when MAIL_DRIVER is smtp",
+		"MAIL_FROM is required when MAIL_DRIVER is smtp",
+		`PASSWORD_RESET_URL must be an absolute http or https URL, got "app.example.com/reset"`,
+		`EMAIL_VERIFICATION_POLICY must be one of allow, read_only, block, got "blok"`,
+		`LOGIN_MAX_ATTEMPTS must be a whole number, got "abc"`,
+		"LOGIN_LOCKOUT_BASE_SECONDS (600) must not exceed LOGIN_LOCKOUT_MAX_SECONDS (60)",
+		"OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set",
+		"OIDC_REDIRECT_URL is required when OIDC_ISSUER_URL is set",
+		`OIDC_SCOPES must include openid, got "email profile"`,
+	}, validationErr.Problems)
+}
+
+func TestConfig_StringRedactsSecrets(t *testing.T) {
+	// Arrange
+	setupConfigDir(t)
+	setRequiredDatabaseKeys(t)
+	t.Setenv("DB_PASSWORD", "hunter2")
+	t.Setenv("JWT_SECRET", "signing-secret")
+
+	// Act
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	effective := cfg.String()
+
+	// Assert
+	assert.NotContains(t, effective, "hunter2")
+	assert.NotContains(t, effective, "signing-secret")
+	assert.Contains(t, effective, "DB_PASSWORD")
+	assert.Contains(t, effective, "******")
+	assert.Contains(t, effective, "DB_HOST")
+	assert.Equal(t, "hunter2", cfg.Database.Password)
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"api/config"
+	"api/internal/middlewares"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCORSTestApp() *fiber.App {
+	cors := config.Default().CORS
+	cors.AllowOrigins = []string{"https://*.example.com", "http://localhost:3000"}
+	cors.Groups = []config.CORSGroup{{
+		Name:       "auth",
+		Path:       "/api/v1/auth",
+		CORSPolicy: cors.CORSPolicy,
+	}}
+	cors.Groups[0].AllowOrigins = []string{"https://app.example.com"}
+	cors.Groups[0].AllowCredentials = true
+
+	app := fiber.New()
+	app.Use(middlewares.NewCORS(cors))
+	app.Get("/api/v1/products", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	app.Post("/api/v1/auth/login", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	return app
+}
+
+func corsRequest(t *testing.T, app *fiber.App, method, path, origin string) *http.Response {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestCORS_AllowsWildcardSubdomain(t *testing.T) {
+	// Arrange
+	app := newCORSTestApp()
+
+	// Act
+	allowed := corsRequest(t, app, http.MethodGet, "/api/v1/products", "https://shop.example.com")
+	rejected := corsRequest(t, app, http.MethodGet, "/api/v1/products", "https://example.org")
+
+	// Assert
+	assert.Equal(t, "https://shop.example.com", allowed.Header.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rejected.Header.Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_GroupOverridesPolicy(t *testing.T) {
+	// Arrange
+	app := newCORSTestApp()
+
+	// Act
+	allowed := corsRequest(t, app, http.MethodOptions, "/api/v1/auth/login", "https://app.example.com")
+	rejected := corsRequest(t, app, http.MethodOptions, "/api/v1/auth/login", "https://shop.example.com")
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, allowed.StatusCode)
+	assert.Equal(t, "https://app.example.com", allowed.Header.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", allowed.Header.Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, rejected.Header.Get("Access-Control-Allow-Origin"), "the top-level origins do not apply to the group")
+}
+
+func TestCORS_GroupMatchesWholePathSegments(t *testing.T) {
+	// Arrange
+	app := newCORSTestApp()
+
+	// Act
+	resp := corsRequest(t, app, http.MethodGet, "/api/v1/authors", "https://shop.example.com")
+
+	// Assert
+	assert.Equal(t, "https://shop.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,111 @@
+package database_test
+
+import (
+	"api/internal/services/database"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMigrationFiles(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0644))
+	}
+}
+
+func TestLoadMigrations_SortsAndPairsFiles(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeMigrationFiles(t, dir,
+		"000002_add_stock.up.sql", "000002_add_stock.down.sql",
+		"000001_baseline.up.sql", "000001_baseline.down.sql",
+		"000003_no_down.up.sql", "README.md",
+	)
+
+	// Act
+	migrations, err := database.LoadMigrations(dir)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, uint64(1), migrations[0].Version)
+	assert.Equal(t, "baseline", migrations[0].Name)
+	assert.Equal(t, filepath.Join(dir, "000002_add_stock.down.sql"), migrations[1].DownPath)
+	assert.Empty(t, migrations[2].DownPath)
+}
+
+func TestLoadMigrations_RejectsConflictsAndMissingUp(t *testing.T) {
+	// Arrange
+	conflict := t.TempDir()
+	writeMigrationFiles(t, conflict, "000001_one.up.sql", "000001_two.up.sql")
+	downOnly := t.TempDir()
+	writeMigrationFiles(t, downOnly, "000001_one.down.sql")
+
+	// Act
+	_, conflictErr := database.LoadMigrations(conflict)
+	_, downOnlyErr := database.LoadMigrations(downOnly)
+
+	// Assert
+	assert.ErrorContains(t, conflictErr, "used by both")
+	assert.ErrorContains(t, downOnlyErr, "has no up file")
+}
+
+func TestLoadMigrations_RepositoryMigrationsAreValid(t *testing.T) {
+	// Act
+	migrations, err := database.LoadMigrations("../../../database/migrations")
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, uint64(i+1), migration.Version, "versions must be contiguous")
+		assert.NotEmpty(t, migration.DownPath, "migration %d_%s needs a down file", migration.Version, migration.Name)
+	}
+}
+
+func TestCreateMigration_NumbersAfterNewest(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeMigrationFiles(t, dir, "000004_create_transfers.up.sql")
+
+	// Act
+	migration, err := database.CreateMigration(dir, "Add Supplier-Table")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), migration.Version)
+	assert.Equal(t, filepath.Join(dir, "000005_add_supplier_table.up.sql"), migration.UpPath)
+	assert.FileExists(t, migration.UpPath)
+	assert.FileExists(t, migration.DownPath)
+}
+
+func TestCreateMigration_InvalidName(t *testing.T) {
+	// Act
+	_, err := database.CreateMigration(t.TempDir(), "drop; table")
+
+	// Assert
+	assert.ErrorIs(t, err, database.ErrInvalidMigrationName)
+}
+
+func TestSplitStatements_IgnoresCommentsAndQuotedSemicolons(t *testing.T) {
+	// Arrange
+	script := `-- header; not a statement
+CREATE TABLE a (id INT); # trailing comment;
+/* block; comment */
+INSERT INTO a (name) VALUES ('x;y'), ("it\"s;");
+
+-- only a comment
+`
+
+	// Act
+	statements := database.SplitStatements(script)
+
+	// Assert
+	require.Len(t, statements, 2)
+	assert.Equal(t, "CREATE TABLE a (id INT)", statements[0])
+	assert.Equal(t, `INSERT INTO a (name) VALUES ('x;y'), ("it\"s;")`, statements[1])
+}
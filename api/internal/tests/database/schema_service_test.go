@@ -0,0 +1,44 @@
+package database_test
+
+import (
+	"api/internal/services/database"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareColumns_Matching(t *testing.T) {
+	// Act
+	differences := database.CompareColumns("products", []string{"id", "name", "deleted_at"}, []string{"ID", "name", "deleted_at"})
+
+	// Assert
+	assert.Empty(t, differences)
+}
+
+func TestCompareColumns_ReportsBothDirections(t *testing.T) {
+	// Arrange: the pseudo.sql transactions table against the model
+	modelColumns := []string{"id", "user_id", "warehouse_id", "product_id", "type", "quantity", "created_at"}
+	tableColumns := []string{"id", "user_id", "warehouse_id", "product_id", "quantity", "total_price", "date", "created_at"}
+
+	// Act
+	differences := database.CompareColumns("transactions", modelColumns, tableColumns)
+
+	// Assert
+	require.Len(t, differences, 3)
+	assert.Equal(t, "transactions.date: not defined in model", differences[0].String())
+	assert.Equal(t, "transactions.total_price: not defined in model", differences[1].String())
+	assert.Equal(t, "transactions.type: missing in database", differences[2].String())
+}
+
+func TestSchemaDriftError_ListsDifferences(t *testing.T) {
+	// Arrange
+	err := &database.SchemaDriftError{Differences: []database.SchemaDifference{
+		{Table: "transfers", Issue: "table is missing"},
+		{Table: "warehouses", Column: "deleted_at", Issue: "missing in database"},
+	}}
+
+	// Assert
+	assert.Contains(t, err.Error(), "  - transfers: table is missing")
+	assert.Contains(t, err.Error(), "  - warehouses.deleted_at: missing in database")
+}
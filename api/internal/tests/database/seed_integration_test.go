@@ -0,0 +1,120 @@
+package database_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/services/database"
+	"api/pkg"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+const seedPassword = "seed-secret-123"
+
+type SeedIntegrationTestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	service *database.SeedService
+}
+
+func (suite *SeedIntegrationTestSuite) SetupSuite() {
+	// InitDatabase exits the process when it cannot connect, so bail out early without a database
+	if os.Getenv("SKIP_INTEGRATION_TESTS") == "true" || os.Getenv("DB_HOST") == "" || os.Getenv("DB_NAME") == "" {
+		suite.T().Skip("integration tests need DB_HOST and DB_NAME")
+	}
+
+	cfg, err := config.Load()
+	suite.Require().NoError(err)
+	config.InitDatabase(cfg)
+	suite.db = config.GetDB()
+
+	suite.Require().NoError(suite.db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.Warehouse{}, &models.UserWarehouse{}, &models.Product{}, &models.Transaction{}, &models.StockBalance{}))
+	suite.service = database.NewSeedService(suite.db)
+}
+
+func (suite *SeedIntegrationTestSuite) SetupTest() {
+	// Clean up database before each test
+	suite.db.Exec("DELETE FROM stock_balances")
+	suite.db.Exec("DELETE FROM transactions")
+	suite.db.Exec("DELETE FROM products")
+	suite.db.Exec("DELETE FROM user_warehouses")
+	suite.db.Exec("DELETE FROM warehouses")
+	suite.db.Exec("DELETE FROM user_roles")
+	suite.db.Exec("DELETE FROM users")
+
+	// Migration 000007 seeds the roles, AutoMigrate does not
+	for _, name := range []string{models.RoleAdmin, models.RoleClerk} {
+		suite.Require().NoError(suite.db.FirstOrCreate(&models.Role{}, models.Role{Name: name}).Error)
+	}
+}
+
+func (suite *SeedIntegrationTestSuite) count(model interface{}, conditions ...interface{}) int64 {
+	var total int64
+	db := suite.db.Unscoped().Model(model)
+	if len(conditions) > 0 {
+		db = db.Where(conditions[0], conditions[1:]...)
+	}
+	suite.Require().NoError(db.Count(&total).Error)
+	return total
+}
+
+func (suite *SeedIntegrationTestSuite) TestRun_HashesPasswords() {
+	// Act
+	_, err := suite.service.Run(database.SeedSetDemo, database.SeedOptions{Password: seedPassword})
+
+	// Assert
+	suite.Require().NoError(err)
+	var users []models.User
+	suite.Require().NoError(suite.db.Find(&users).Error)
+	suite.Require().Len(users, 2)
+	for _, user := range users {
+		suite.NotEqual(seedPassword, user.Password, "password of %s is stored in plain text", user.Email)
+		suite.True(pkg.CheckPasswordHash(seedPassword, user.Password), "password of %s is not a bcrypt hash of the seed password", user.Email)
+		suite.False(pkg.CheckPasswordHash("password", user.Password))
+	}
+}
+
+func (suite *SeedIntegrationTestSuite) TestRun_IsIdempotent() {
+	// Arrange
+	first, err := suite.service.Run(database.SeedSetDemo, database.SeedOptions{Password: seedPassword})
+	suite.Require().NoError(err)
+	users, products, transactions := suite.count(&models.User{}), suite.count(&models.Product{}), suite.count(&models.Transaction{})
+
+	// Act
+	second, err := suite.service.Run(database.SeedSetDemo, database.SeedOptions{Password: seedPassword})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(database.SeedResult{Users: 2, Warehouses: 2, Products: 3, Transactions: 3}, *first)
+	suite.Equal(database.SeedResult{}, *second)
+	suite.Equal(users, suite.count(&models.User{}))
+	suite.Equal(products, suite.count(&models.Product{}))
+	suite.Equal(transactions, suite.count(&models.Transaction{}))
+}
+
+func (suite *SeedIntegrationTestSuite) TestRun_LoadTestCreatesDocumentedRows() {
+	// Act: -size 1 means 1000 load-test products with one receipt and one issue each
+	result, err := suite.service.Run(database.SeedSetLoadTest, database.SeedOptions{Password: seedPassword, LoadTestSize: 1})
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(database.SeedResult{Users: 2, Warehouses: 2, Products: 1003, Transactions: 2003}, *result)
+	suite.Equal(int64(1000), suite.count(&models.Product{}, "name LIKE ?", "Load Test Product %"))
+	suite.Equal(int64(1003), suite.count(&models.Product{}))
+	suite.Equal(int64(2003), suite.count(&models.Transaction{}))
+	suite.Equal(int64(1000), suite.count(&models.Transaction{}, "type = ? AND product_id IN (?)", models.TransactionTypeOut,
+		suite.db.Model(&models.Product{}).Select("id").Where("name LIKE ?", "Load Test Product %")))
+
+	// Re-running tops up to the requested size instead of adding another thousand
+	again, err := suite.service.Run(database.SeedSetLoadTest, database.SeedOptions{Password: seedPassword, LoadTestSize: 1})
+	suite.Require().NoError(err)
+	suite.Equal(database.SeedResult{}, *again)
+	suite.Equal(int64(1003), suite.count(&models.Product{}))
+}
+
+func TestSeedIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(SeedIntegrationTestSuite))
+}
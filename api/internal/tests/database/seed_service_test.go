@@ -0,0 +1,20 @@
+package database_test
+
+import (
+	"api/internal/services/database"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedService_Run_UnknownSet(t *testing.T) {
+	// Arrange: an unknown set must be rejected before any database access
+	service := database.NewSeedService(nil)
+
+	// Act
+	result, err := service.Run("production", database.SeedOptions{Password: "password"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, database.ErrUnknownSeedSet)
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"api/pkg"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle_StopsInReverseOrder(t *testing.T) {
+	// Arrange
+	lifecycle := pkg.NewLifecycle()
+	var stopped []string
+	lifecycle.OnStop("database", func(ctx context.Context) error {
+		stopped = append(stopped, "database")
+		return nil
+	})
+	lifecycle.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped = append(stopped, "worker")
+	})
+	lifecycle.OnStop("server", func(ctx context.Context) error {
+		stopped = append(stopped, "server")
+		return nil
+	})
+
+	// Act
+	err := lifecycle.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"server", "worker", "database"}, stopped)
+}
+
+func TestLifecycle_ContinuesAfterFailedHook(t *testing.T) {
+	// Arrange
+	lifecycle := pkg.NewLifecycle()
+	databaseClosed := false
+	lifecycle.OnStop("database", func(ctx context.Context) error {
+		databaseClosed = true
+		return nil
+	})
+	lifecycle.Go("stuck worker", func(ctx context.Context) {
+		time.Sleep(time.Second)
+	})
+	lifecycle.OnStop("server", func(ctx context.Context) error {
+		return errors.New("listener already closed")
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := lifecycle.Shutdown(ctx)
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to stop server: listener already closed")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the worker ignored its context")
+	assert.True(t, databaseClosed)
+}
+
+func TestLifecycle_DrainsInFlightRequests(t *testing.T) {
+	// Arrange
+	started := make(chan struct{})
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.SendString("done")
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go app.Listener(listener)
+
+	lifecycle := pkg.NewLifecycle()
+	lifecycle.OnStop("HTTP server", app.ShutdownWithContext)
+
+	type result struct {
+		body string
+		err  error
+	}
+	response := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			response <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		response <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	// Act
+	err = lifecycle.Shutdown(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	got := <-response
+	require.NoError(t, got.err)
+	assert.Equal(t, "done", got.body)
+	_, err = net.DialTimeout("tcp", listener.Addr().String(), time.Second)
+	assert.Error(t, err, "the server stopped accepting connections")
+}
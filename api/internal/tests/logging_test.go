@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"api/internal/middlewares"
+	"api/pkg"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newBufferLogger logs JSON lines at debug level into the returned buffer
+func newBufferLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(pkg.NewContextHandler(handler)), &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func newLoggedApp(logger *slog.Logger) *fiber.App {
+	app := fiber.New()
+	app.Use(middlewares.RequestID())
+	app.Use(middlewares.RequestLogger(logger))
+	return app
+}
+
+func TestRequestLogger_LogsRequestFields(t *testing.T) {
+	// Arrange
+	logger, buf := newBufferLogger()
+	app := newLoggedApp(logger)
+	app.Get("/products/:id", func(c *fiber.Ctx) error {
+		// What the JWT middleware does after authenticating
+		pkg.AddLogAttrs(c.UserContext(), slog.Uint64("user_id", 7))
+		logger.InfoContext(c.UserContext(), "loading product")
+		return c.SendString("ok")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/products/42", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Equal(t, "req-123", line["request_id"])
+		assert.Equal(t, float64(7), line["user_id"])
+		assert.Equal(t, "/products/42", line["path"])
+	}
+	request := lines[1]
+	assert.Equal(t, "request", request["msg"])
+	assert.Equal(t, "INFO", request["level"])
+	assert.Equal(t, "/products/:id", request["route"])
+	assert.Equal(t, float64(http.StatusOK), request["status"])
+	assert.Contains(t, request, "latency")
+}
+
+func TestRequestLogger_LogsErrorsWithFinalStatus(t *testing.T) {
+	// Arrange
+	logger, buf := newBufferLogger()
+	app := newLoggedApp(logger)
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("database unavailable")
+	})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	lines := logLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), lines[0]["status"])
+	assert.Equal(t, "database unavailable", lines[0]["error"])
+	assert.NotEmpty(t, lines[0]["request_id"], "generated when the client sends none")
+}
+
+func TestRotatingFile_RotatesBySizeAndKeepsBackups(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "logs", "app.log")
+	file, err := pkg.NewRotatingFile(pkg.RotatingFileConfig{Path: path, MaxSize: 20, MaxBackups: 2})
+	require.NoError(t, err)
+	defer file.Close()
+
+	// Act
+	for _, line := range []string{"first line 0001\n", "second line 002\n", "third line 0003\n", "fourth line 004\n"} {
+		_, err := file.Write([]byte(line))
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond) // distinct rotation timestamps
+	}
+
+	// Assert
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "fourth line 004\n", string(current))
+	backups, err := filepath.Glob(filepath.Join(dir, "logs", "app-*.log"))
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "the oldest backup is removed")
+	oldest, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "second line 002\n", string(oldest))
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := pkg.NewRotatingFile(pkg.RotatingFileConfig{Path: path, MaxAge: 20 * time.Millisecond})
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.Write([]byte("old\n"))
+	require.NoError(t, err)
+	time.Sleep(30 * time.Millisecond)
+
+	// Act
+	_, err = file.Write([]byte("new\n"))
+
+	// Assert
+	require.NoError(t, err)
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(current))
+	backups, err := filepath.Glob(strings.TrimSuffix(path, ".log") + "-*.log")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestRotatingFile_KeepsLoggingWhenRenameFails(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := pkg.NewRotatingFile(pkg.RotatingFileConfig{Path: path, MaxSize: 20})
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = file.Write([]byte("first line 0001\n"))
+	require.NoError(t, err)
+	// Renaming a file that is gone fails, e.g. after an operator deleted it
+	require.NoError(t, os.Remove(path))
+
+	// Act
+	n, rotateErr := file.Write([]byte("second line 002\n"))
+	_, err = file.Write([]byte("3rd\n"))
+
+	// Assert
+	require.Error(t, rotateErr)
+	assert.Equal(t, len("second line 002\n"), n, "the line is still written")
+	require.NoError(t, err)
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second line 002\n3rd\n", string(current))
+}
+
+func TestGormLogger_LogsFailedAndSlowQueries(t *testing.T) {
+	// Arrange
+	logger, buf := newBufferLogger()
+	gormLogger := pkg.NewGormLogger(logger, gormlogger.Warn, 100*time.Millisecond)
+	ctx := pkg.WithRequestID(context.Background(), "req-123")
+	query := func() (string, int64) { return "SELECT * FROM `products`", 0 }
+
+	// Act
+	gormLogger.Trace(ctx, time.Now(), query, errors.New("connection refused"))
+	gormLogger.Trace(ctx, time.Now(), query, gorm.ErrRecordNotFound)
+	gormLogger.Trace(ctx, time.Now().Add(-time.Second), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, nil)
+
+	// Assert
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2, "missing records and fast queries are not logged")
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.Equal(t, "connection refused", lines[0]["error"])
+	assert.Equal(t, "SELECT * FROM `products`", lines[0]["sql"])
+	assert.Equal(t, "req-123", lines[0]["request_id"])
+	assert.Equal(t, "WARN", lines[1]["level"])
+	assert.Equal(t, "slow query", lines[1]["msg"])
+}
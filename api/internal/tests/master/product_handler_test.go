@@ -0,0 +1,184 @@
+package master_test
+
+import (
+	"api/internal/handlers/master"
+	"api/internal/models"
+	masterServices "api/internal/services/master"
+	"api/pkg"
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockProductService is a mock implementation of ProductService
+type MockProductService struct {
+	mock.Mock
+}
+
+func (m *MockProductService) List(ctx context.Context, scope models.WarehouseScope, query *models.ListQuery) (*models.ProductListResponse, error) {
+	args := m.Called(scope, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductListResponse), args.Error(1)
+}
+
+func (m *MockProductService) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.ProductResponse, error) {
+	args := m.Called(scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) Create(ctx context.Context, req *models.ProductRequest, image *multipart.FileHeader) (*models.ProductResponse, error) {
+	args := m.Called(req, image)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) Update(ctx context.Context, scope models.WarehouseScope, id uint, req *models.ProductRequest, image *multipart.FileHeader) (*models.ProductResponse, error) {
+	args := m.Called(scope, id, req, image)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductResponse), args.Error(1)
+}
+
+func (m *MockProductService) Delete(ctx context.Context, scope models.WarehouseScope, id uint) error {
+	args := m.Called(scope, id)
+	return args.Error(0)
+}
+
+func (m *MockProductService) Restore(ctx context.Context, scope models.WarehouseScope, id uint) (*models.ProductResponse, error) {
+	args := m.Called(scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductResponse), args.Error(1)
+}
+
+func newProductMultipartRequest(method, url string, fields map[string]string, image []byte) *http.Request {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	if image != nil {
+		part, _ := writer.CreateFormFile("image", "photo.png")
+		part.Write(image)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(method, url, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestProductHandler_Create_Multipart(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockService := new(MockProductService)
+	handler := master.NewProductHandler(mockService)
+	app.Post("/products", handler.Create)
+
+	mockService.On("Create",
+		mock.MatchedBy(func(req *models.ProductRequest) bool {
+			return req.Name == "Product A" && req.Price != nil && *req.Price == 100
+		}),
+		mock.MatchedBy(func(image *multipart.FileHeader) bool {
+			return image != nil && image.Filename == "photo.png"
+		}),
+	).Return(&models.ProductResponse{ID: 1}, nil)
+
+	req := newProductMultipartRequest("POST", "/products", map[string]string{"name": "Product A", "price": "100"}, pngHeader)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_Create_MissingPrice(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockService := new(MockProductService)
+	handler := master.NewProductHandler(mockService)
+	app.Post("/products", handler.Create)
+
+	req := newProductMultipartRequest("POST", "/products", map[string]string{"name": "Product A"}, nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestProductHandler_Update_InvalidImage(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockService := new(MockProductService)
+	handler := master.NewProductHandler(mockService)
+	app.Put("/products/:id", handler.Update)
+
+	mockService.On("Update", mock.Anything, uint(1), mock.Anything, mock.Anything).Return(nil, pkg.ErrInvalidImageType)
+
+	req := newProductMultipartRequest("PUT", "/products/1", map[string]string{"name": "Product A", "price": "100"}, []byte("not an image"))
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, pkg.ErrInvalidImageType.Error(), response["error"])
+}
+
+func TestProductHandler_GetByID_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	app := fiber.New()
+	mockService := new(MockProductService)
+	handler := master.NewProductHandler(mockService)
+	scope := models.WarehouseScope{WarehouseIDs: []uint{1}}
+	app.Get("/products/:id", func(c *fiber.Ctx) error {
+		c.Locals("warehouseScope", scope)
+		return c.Next()
+	}, handler.Get)
+
+	mockService.On("GetByID", scope, uint(1)).Return(nil, masterServices.ErrWarehouseAccessDenied)
+
+	req := httptest.NewRequest("GET", "/products/1", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "Forbidden", body["error"])
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,88 @@
+package master_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	"api/internal/repositories/master"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type ProductRepositoryIntegrationTestSuite struct {
+	suite.Suite
+	db         *gorm.DB
+	repo       master.ProductRepository
+	product    models.Product
+	warehouses [2]models.Warehouse
+}
+
+func (suite *ProductRepositoryIntegrationTestSuite) SetupSuite() {
+	// InitDatabase exits the process when it cannot connect, so bail out early without a database
+	if os.Getenv("SKIP_INTEGRATION_TESTS") == "true" || os.Getenv("DB_HOST") == "" || os.Getenv("DB_NAME") == "" {
+		suite.T().Skip("integration tests need DB_HOST and DB_NAME")
+	}
+
+	cfg, err := config.Load()
+	suite.Require().NoError(err)
+	config.InitDatabase(cfg)
+	suite.db = config.GetDB()
+
+	suite.Require().NoError(suite.db.AutoMigrate(&models.Warehouse{}, &models.Product{}, &models.StockBalance{}))
+	suite.repo = master.NewProductRepository(suite.db)
+}
+
+func (suite *ProductRepositoryIntegrationTestSuite) SetupTest() {
+	// Clean up database before each test
+	suite.db.Exec("DELETE FROM stock_balances")
+	suite.db.Exec("DELETE FROM products")
+	suite.db.Exec("DELETE FROM warehouses")
+
+	// A product stocked in two warehouses: 5 in the first, 7 in the second
+	for i, name := range []string{"North Warehouse", "South Warehouse"} {
+		name := name
+		suite.warehouses[i] = models.Warehouse{Name: &name}
+		suite.Require().NoError(suite.db.Create(&suite.warehouses[i]).Error)
+	}
+	name, price, stock := "Product A", 100.0, 12.0
+	suite.product = models.Product{Name: &name, Price: &price, Stock: &stock}
+	suite.Require().NoError(suite.db.Create(&suite.product).Error)
+	for i, quantity := range []float64{5, 7} {
+		suite.Require().NoError(suite.db.Create(&models.StockBalance{ProductID: suite.product.ID, WarehouseID: suite.warehouses[i].ID, Quantity: quantity}).Error)
+	}
+}
+
+func (suite *ProductRepositoryIntegrationTestSuite) TestGetByID_StockIsLimitedToScope() {
+	// Act
+	all, allErr := suite.repo.GetByID(context.Background(), models.AllWarehouses(), suite.product.ID)
+	scoped, scopedErr := suite.repo.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{suite.warehouses[0].ID}}, suite.product.ID)
+
+	// Assert
+	suite.Require().NoError(allErr)
+	suite.Require().NoError(scopedErr)
+	suite.Equal(12.0, *all.Stock)
+	suite.Equal(5.0, *scoped.Stock, "stock of the unassigned warehouse must not be included")
+	suite.Equal("Product A", *scoped.Name)
+}
+
+func (suite *ProductRepositoryIntegrationTestSuite) TestList_StockIsLimitedToScope() {
+	// Arrange
+	query := &models.ListQuery{}
+	query.Normalize()
+
+	// Act
+	products, total, err := suite.repo.List(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{suite.warehouses[1].ID}}, query)
+
+	// Assert
+	suite.Require().NoError(err)
+	suite.Equal(int64(1), total)
+	suite.Require().Len(products, 1)
+	suite.Equal(7.0, *products[0].Stock)
+}
+
+func TestProductRepositoryIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductRepositoryIntegrationTestSuite))
+}
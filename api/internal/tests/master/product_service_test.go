@@ -0,0 +1,327 @@
+package master_test
+
+import (
+	"api/internal/models"
+	"api/internal/services/master"
+	"api/pkg"
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockProductRepository is a mock implementation of ProductRepository
+type MockProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockProductRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.ListQuery) ([]models.Product, int64, error) {
+	args := m.Called(scope, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Product), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockProductRepository) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByIDWithTrashed(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) Create(ctx context.Context, product *models.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, product *models.Product) error {
+	args := m.Called(product)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) StockWarehouseIDs(ctx context.Context, id uint) ([]uint, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+// pngHeader is the minimal PNG signature recognised by http.DetectContentType
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// newFileHeader builds a multipart.FileHeader holding content, as a parsed upload would
+func newFileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("image", filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	form, err := multipart.NewReader(body, writer.Boundary()).ReadForm(10 << 20)
+	require.NoError(t, err)
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["image"][0]
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestProductService_Create_WithImage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	// Act
+	response, err := service.Create(context.Background(), &models.ProductRequest{Name: "Product A", Price: floatPtr(100)},
+		newFileHeader(t, "photo.png", pngHeader))
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, response.Image)
+	assert.Equal(t, ".png", filepath.Ext(*response.Image))
+	assert.Equal(t, "/assets/images/products/"+*response.Image, *response.ImageURL)
+	assert.Equal(t, 0.0, *response.Stock)
+	assert.FileExists(t, filepath.Join(dir, *response.Image))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_Create_InvalidImageType(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	// Act
+	response, err := service.Create(context.Background(), &models.ProductRequest{Name: "Product A", Price: floatPtr(100)},
+		newFileHeader(t, "photo.png", []byte("#!/bin/sh\necho not an image\n")))
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, pkg.ErrInvalidImageType)
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestProductService_Create_ImageTooLarge(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, t.TempDir(), pkg.MaxImageSize)
+
+	content := append(append([]byte{}, pngHeader...), make([]byte, pkg.MaxImageSize)...)
+
+	// Act
+	_, err := service.Create(context.Background(), &models.ProductRequest{Name: "Product A", Price: floatPtr(100)},
+		newFileHeader(t, "big.png", content))
+
+	// Assert
+	assert.ErrorIs(t, err, pkg.ErrImageTooLarge)
+}
+
+func TestProductService_Create_RepositoryErrorRemovesImage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(errors.New("db down"))
+
+	// Act
+	_, err := service.Create(context.Background(), &models.ProductRequest{Name: "Product A", Price: floatPtr(100)},
+		newFileHeader(t, "photo.png", pngHeader))
+
+	// Assert
+	assert.Error(t, err)
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestProductService_Update_ReplacesImage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	oldImage := "old.png"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, oldImage), pngHeader, 0644))
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Image: &oldImage}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	// Act
+	response, err := service.Update(context.Background(), models.AllWarehouses(), 1, &models.ProductRequest{Name: "Product A", Price: floatPtr(120)},
+		newFileHeader(t, "new.png", pngHeader))
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, oldImage, *response.Image)
+	assert.NoFileExists(t, filepath.Join(dir, oldImage))
+	assert.FileExists(t, filepath.Join(dir, *response.Image))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_Update_RemoveImage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	oldImage := "old.png"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, oldImage), pngHeader, 0644))
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Image: &oldImage}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	// Act
+	response, err := service.Update(context.Background(), models.AllWarehouses(), 1, &models.ProductRequest{Name: "Product A", Price: floatPtr(120), RemoveImage: true}, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Nil(t, response.Image)
+	assert.NoFileExists(t, filepath.Join(dir, oldImage))
+}
+
+func TestProductService_Delete_RemovesImage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	image := "product.png"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, image), pngHeader, 0644))
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Image: &image}, nil)
+	mockRepo.On("Delete", uint(1)).Return(nil)
+
+	// Act
+	err := service.Delete(context.Background(), models.AllWarehouses(), 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, image))
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestProductService_Delete_KeepsImageWhenDeleteFails(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, dir, pkg.MaxImageSize)
+
+	image := "product.png"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, image), pngHeader, 0644))
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1, Image: &image}, nil)
+	mockRepo.On("Delete", uint(1)).Return(errors.New("database error"))
+
+	// Act
+	err := service.Delete(context.Background(), models.AllWarehouses(), 1)
+
+	// Assert
+	assert.Error(t, err)
+	assert.FileExists(t, filepath.Join(dir, image))
+}
+
+func TestProductService_GetByID_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, t.TempDir(), pkg.MaxImageSize)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1}, nil)
+	mockRepo.On("StockWarehouseIDs", uint(1)).Return([]uint{2, 3}, nil)
+
+	// Act
+	response, err := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{1}}, 1)
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, master.ErrWarehouseAccessDenied)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_GetByID_InWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, t.TempDir(), pkg.MaxImageSize)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1}, nil)
+	mockRepo.On("StockWarehouseIDs", uint(1)).Return([]uint{2, 3}, nil)
+
+	// Act
+	response, err := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{3}}, 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), response.ID)
+}
+
+func TestProductService_GetByID_UnstockedProductIsVisible(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, t.TempDir(), pkg.MaxImageSize)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1}, nil)
+	mockRepo.On("StockWarehouseIDs", uint(1)).Return([]uint{}, nil)
+
+	// Act
+	response, err := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{3}}, 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), response.ID)
+}
+
+func TestProductService_Delete_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockProductRepository)
+	service := master.NewProductService(mockRepo, t.TempDir(), pkg.MaxImageSize)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Product{ID: 1}, nil)
+	mockRepo.On("StockWarehouseIDs", uint(1)).Return([]uint{2}, nil)
+
+	// Act
+	err := service.Delete(context.Background(), models.WarehouseScope{}, 1)
+
+	// Assert
+	assert.ErrorIs(t, err, master.ErrWarehouseAccessDenied)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
@@ -0,0 +1,227 @@
+package master_test
+
+import (
+	"api/internal/handlers/master"
+	"api/internal/models"
+	masterServices "api/internal/services/master"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWarehouseService is a mock implementation of WarehouseService
+type MockWarehouseService struct {
+	mock.Mock
+}
+
+func (m *MockWarehouseService) List(ctx context.Context, query *models.ListQuery) (*models.WarehouseListResponse, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WarehouseListResponse), args.Error(1)
+}
+
+func (m *MockWarehouseService) GetByID(ctx context.Context, id uint) (*models.WarehouseResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WarehouseResponse), args.Error(1)
+}
+
+func (m *MockWarehouseService) Create(ctx context.Context, req *models.WarehouseRequest) (*models.WarehouseResponse, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WarehouseResponse), args.Error(1)
+}
+
+func (m *MockWarehouseService) Update(ctx context.Context, id uint, req *models.WarehouseRequest) (*models.WarehouseResponse, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WarehouseResponse), args.Error(1)
+}
+
+func (m *MockWarehouseService) Delete(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWarehouseService) Restore(ctx context.Context, id uint) (*models.WarehouseResponse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WarehouseResponse), args.Error(1)
+}
+
+func setupWarehouseApp(mockService *MockWarehouseService) *fiber.App {
+	app := fiber.New()
+	handler := master.NewWarehouseHandler(mockService)
+
+	app.Get("/warehouses", handler.List)
+	app.Get("/warehouses/:id", handler.Get)
+	app.Post("/warehouses", handler.Create)
+	app.Put("/warehouses/:id", handler.Update)
+	app.Delete("/warehouses/:id", handler.Delete)
+	app.Post("/warehouses/:id/restore", handler.Restore)
+	return app
+}
+
+func TestWarehouseHandler_List_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	expected := &models.WarehouseListResponse{
+		Items: []models.WarehouseResponse{{ID: 1, Name: stringPtr("Main Warehouse")}},
+		Meta:  models.PaginationMeta{Page: 1, Limit: 10, Total: 1, TotalPage: 1},
+	}
+	mockService.On("List", mock.MatchedBy(func(q *models.ListQuery) bool {
+		return q.Search == "main" && q.Trashed == "with"
+	})).Return(expected, nil)
+
+	req := httptest.NewRequest("GET", "/warehouses?search=main&trashed=with", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("X-Total-Count"))
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "success", response["message"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWarehouseHandler_List_InvalidTrashed(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	req := httptest.NewRequest("GET", "/warehouses?trashed=all", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertNotCalled(t, "List", mock.Anything)
+}
+
+func TestWarehouseHandler_Create_ValidationError(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	reqBody, _ := json.Marshal(models.WarehouseRequest{})
+	req := httptest.NewRequest("POST", "/warehouses", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "failed", response["message"])
+}
+
+func TestWarehouseHandler_Create_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	mockService.On("Create", &models.WarehouseRequest{Name: "Main Warehouse"}).
+		Return(&models.WarehouseResponse{ID: 1, Name: stringPtr("Main Warehouse")}, nil)
+
+	reqBody, _ := json.Marshal(models.WarehouseRequest{Name: "Main Warehouse"})
+	req := httptest.NewRequest("POST", "/warehouses", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWarehouseHandler_Get_NotFound(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	mockService.On("GetByID", uint(5)).Return(nil, masterServices.ErrWarehouseNotFound)
+
+	req := httptest.NewRequest("GET", "/warehouses/5", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "warehouse not found", response["error"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWarehouseHandler_Delete_InvalidID(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	req := httptest.NewRequest("DELETE", "/warehouses/abc", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestWarehouseHandler_Restore_Success(t *testing.T) {
+	// Arrange
+	mockService := new(MockWarehouseService)
+	app := setupWarehouseApp(mockService)
+
+	mockService.On("Restore", uint(3)).Return(&models.WarehouseResponse{ID: 3}, nil)
+
+	req := httptest.NewRequest("POST", "/warehouses/3/restore", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,167 @@
+package master_test
+
+import (
+	"api/internal/models"
+	"api/internal/services/master"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockWarehouseRepository is a mock implementation of WarehouseRepository
+type MockWarehouseRepository struct {
+	mock.Mock
+}
+
+func (m *MockWarehouseRepository) List(ctx context.Context, query *models.ListQuery) ([]models.Warehouse, int64, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Warehouse), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockWarehouseRepository) GetByID(ctx context.Context, id uint) (*models.Warehouse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) GetByIDWithTrashed(ctx context.Context, id uint) (*models.Warehouse, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Warehouse), args.Error(1)
+}
+
+func (m *MockWarehouseRepository) Create(ctx context.Context, warehouse *models.Warehouse) error {
+	args := m.Called(warehouse)
+	return args.Error(0)
+}
+
+func (m *MockWarehouseRepository) Update(ctx context.Context, warehouse *models.Warehouse) error {
+	args := m.Called(warehouse)
+	return args.Error(0)
+}
+
+func (m *MockWarehouseRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWarehouseRepository) Restore(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestWarehouseService_List_DefaultsPaging(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWarehouseRepository)
+	service := master.NewWarehouseService(mockRepo)
+
+	warehouses := []models.Warehouse{
+		{ID: 2, Name: stringPtr("Secondary Warehouse")},
+		{ID: 1, Name: stringPtr("Main Warehouse")},
+	}
+	mockRepo.On("List", mock.AnythingOfType("*models.ListQuery")).Return(warehouses, int64(12), nil)
+
+	// Act
+	response, err := service.List(context.Background(), &models.ListQuery{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, response.Items, 2)
+	assert.Equal(t, 1, response.Meta.Page)
+	assert.Equal(t, 10, response.Meta.Limit)
+	assert.Equal(t, int64(12), response.Meta.Total)
+	assert.Equal(t, int64(2), response.Meta.TotalPage)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWarehouseService_Update_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWarehouseRepository)
+	service := master.NewWarehouseService(mockRepo)
+
+	mockRepo.On("GetByID", uint(99)).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	response, err := service.Update(context.Background(), 99, &models.WarehouseRequest{Name: "Renamed"})
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, master.ErrWarehouseNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWarehouseService_Delete_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWarehouseRepository)
+	service := master.NewWarehouseService(mockRepo)
+
+	mockRepo.On("GetByID", uint(1)).Return(&models.Warehouse{ID: 1}, nil)
+	mockRepo.On("Delete", uint(1)).Return(nil)
+
+	// Act
+	err := service.Delete(context.Background(), 1)
+
+	// Assert
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWarehouseService_Restore_NotDeleted(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWarehouseRepository)
+	service := master.NewWarehouseService(mockRepo)
+
+	mockRepo.On("GetByIDWithTrashed", uint(1)).Return(&models.Warehouse{ID: 1}, nil)
+
+	// Act
+	response, err := service.Restore(context.Background(), 1)
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, master.ErrWarehouseNotDeleted)
+
+	mockRepo.AssertNotCalled(t, "Restore", uint(1))
+}
+
+func TestWarehouseService_Restore_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockWarehouseRepository)
+	service := master.NewWarehouseService(mockRepo)
+
+	deleted := &models.Warehouse{
+		ID:        1,
+		Name:      stringPtr("Main Warehouse"),
+		DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true},
+	}
+	mockRepo.On("GetByIDWithTrashed", uint(1)).Return(deleted, nil)
+	mockRepo.On("Restore", uint(1)).Return(nil)
+	mockRepo.On("GetByID", uint(1)).Return(&models.Warehouse{ID: 1, Name: stringPtr("Main Warehouse")}, nil)
+
+	// Act
+	response, err := service.Restore(context.Background(), 1)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), response.ID)
+	assert.Nil(t, response.DeletedAt)
+
+	mockRepo.AssertExpectations(t)
+}
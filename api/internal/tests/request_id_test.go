@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"api/internal/middlewares"
+	"api/pkg"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestIDApp() *fiber.App {
+	app := fiber.New()
+	app.Use(middlewares.RequestID())
+	app.Get("/context", func(c *fiber.Ctx) error {
+		return c.SendString(pkg.RequestIDFromContext(c.UserContext()))
+	})
+	app.Get("/failed", func(c *fiber.Ctx) error {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"message": "failed",
+			"error":   "Invalid request body",
+		})
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "success"})
+	})
+	return app
+}
+
+func requestWithID(t *testing.T, app *fiber.App, path, requestID string) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func TestRequestID_ReusesClientID(t *testing.T) {
+	// Arrange
+	app := newRequestIDApp()
+
+	// Act
+	resp, body := requestWithID(t, app, "/context", "client-123")
+
+	// Assert
+	assert.Equal(t, "client-123", resp.Header.Get("X-Request-ID"))
+	assert.Equal(t, "client-123", body, "stored in the request context")
+}
+
+func TestRequestID_ReplacesMissingOrInvalidID(t *testing.T) {
+	// Arrange
+	app := newRequestIDApp()
+
+	// Act
+	missing, missingBody := requestWithID(t, app, "/context", "")
+	invalid, _ := requestWithID(t, app, "/context", "bad id\"with quotes")
+	tooLong, _ := requestWithID(t, app, "/context", strings.Repeat("a", 129))
+
+	// Assert
+	assert.Len(t, missing.Header.Get("X-Request-ID"), 36)
+	assert.Equal(t, missing.Header.Get("X-Request-ID"), missingBody)
+	assert.Len(t, invalid.Header.Get("X-Request-ID"), 36)
+	assert.Len(t, tooLong.Header.Get("X-Request-ID"), 36)
+}
+
+func TestRequestID_AddedToErrorBodies(t *testing.T) {
+	// Arrange
+	app := newRequestIDApp()
+
+	// Act
+	_, failed := requestWithID(t, app, "/failed", "client-123")
+	_, ok := requestWithID(t, app, "/ok", "client-123")
+
+	// Assert
+	var body map[string]string
+	require.NoError(t, json.Unmarshal([]byte(failed), &body))
+	assert.Equal(t, map[string]string{
+		"message":    "failed",
+		"error":      "Invalid request body",
+		"request_id": "client-123",
+	}, body)
+	assert.JSONEq(t, `{"message":"success"}`, ok, "successful responses are unchanged")
+}
@@ -0,0 +1,121 @@
+package transaction_test
+
+import (
+	"api/config"
+	"api/internal/models"
+	transactionRepositories "api/internal/repositories/transaction"
+	"api/internal/services/transaction"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// postingDuringReconcile runs post after Reconcile has read the ledger and before it replaces the balances
+type postingDuringReconcile struct {
+	transactionRepositories.StockRepository
+	post func()
+}
+
+func (r *postingDuringReconcile) Atomic(ctx context.Context, fn func(repo transactionRepositories.StockRepository) error) error {
+	return r.StockRepository.Atomic(ctx, func(repo transactionRepositories.StockRepository) error {
+		return fn(&postingDuringReconcile{StockRepository: repo, post: r.post})
+	})
+}
+
+func (r *postingDuringReconcile) All(ctx context.Context) ([]models.StockBalance, error) {
+	r.post()
+	return r.StockRepository.All(ctx)
+}
+
+type StockIntegrationTestSuite struct {
+	suite.Suite
+	db *gorm.DB
+}
+
+func (suite *StockIntegrationTestSuite) SetupSuite() {
+	// InitDatabase exits the process when it cannot connect, so bail out early without a database
+	if os.Getenv("SKIP_INTEGRATION_TESTS") == "true" || os.Getenv("DB_HOST") == "" || os.Getenv("DB_NAME") == "" {
+		suite.T().Skip("integration tests need DB_HOST and DB_NAME")
+	}
+
+	cfg, err := config.Load()
+	suite.Require().NoError(err)
+	config.InitDatabase(cfg)
+	suite.db = config.GetDB()
+
+	suite.Require().NoError(suite.db.AutoMigrate(&models.User{}, &models.Warehouse{}, &models.Product{}, &models.Transaction{}, &models.StockBalance{}))
+}
+
+func (suite *StockIntegrationTestSuite) SetupTest() {
+	// Clean up database before each test
+	suite.db.Exec("DELETE FROM stock_balances")
+	suite.db.Exec("DELETE FROM transactions")
+	suite.db.Exec("DELETE FROM products")
+	suite.db.Exec("DELETE FROM warehouses")
+	suite.db.Exec("DELETE FROM users WHERE email = ?", "stock-integration@example.com")
+}
+
+func (suite *StockIntegrationTestSuite) TestReconcile_KeepsPostingMadeDuringReconcile() {
+	// Arrange
+	user := models.User{Name: "Stock", Email: "stock-integration@example.com", Password: "x"}
+	suite.Require().NoError(suite.db.Create(&user).Error)
+	warehouseName, productName := "Main", "Widget"
+	warehouse := models.Warehouse{Name: &warehouseName}
+	suite.Require().NoError(suite.db.Create(&warehouse).Error)
+	product := models.Product{Name: &productName}
+	suite.Require().NoError(suite.db.Create(&product).Error)
+
+	transactionService := transaction.NewTransactionService(transactionRepositories.NewTransactionRepository(suite.db))
+	ctx := context.Background()
+	posting := func(quantity float64) error {
+		_, err := transactionService.Create(ctx, models.AllWarehouses(), user.ID, &models.TransactionRequest{
+			WarehouseID: warehouse.ID,
+			ProductID:   product.ID,
+			Type:        models.TransactionTypeIn,
+			Quantity:    quantity,
+		})
+		return err
+	}
+	suite.Require().NoError(posting(10))
+
+	posted := make(chan error, 1)
+	stockRepo := &postingDuringReconcile{
+		StockRepository: transactionRepositories.NewStockRepository(suite.db),
+		post: func() {
+			go func() { posted <- posting(5) }()
+			// The posting has to wait for the product lock held by Reconcile
+			select {
+			case err := <-posted:
+				suite.Failf("posting was not blocked by reconcile", "err: %v", err)
+			case <-time.After(300 * time.Millisecond):
+			}
+		},
+	}
+
+	// Act
+	_, err := transaction.NewStockService(stockRepo).Reconcile(ctx, false)
+
+	// Assert
+	suite.Require().NoError(err)
+	select {
+	case err := <-posted:
+		suite.Require().NoError(err)
+	case <-time.After(10 * time.Second):
+		suite.FailNow("posting did not finish after reconcile committed")
+	}
+
+	var balance models.StockBalance
+	suite.Require().NoError(suite.db.Where("product_id = ? AND warehouse_id = ?", product.ID, warehouse.ID).First(&balance).Error)
+	suite.Equal(15.0, balance.Quantity)
+	suite.Require().NoError(suite.db.First(&product, product.ID).Error)
+	suite.Require().NotNil(product.Stock)
+	suite.Equal(15.0, *product.Stock)
+}
+
+func TestStockIntegrationTestSuite(t *testing.T) {
+	suite.Run(t, new(StockIntegrationTestSuite))
+}
@@ -0,0 +1,162 @@
+package transaction_test
+
+import (
+	"api/internal/models"
+	transactionRepositories "api/internal/repositories/transaction"
+	"api/internal/services/transaction"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStockRepository is a mock implementation of StockRepository.
+// Atomic runs the callback against the mock itself.
+type MockStockRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.StockQuery, paging *models.ListQuery) ([]models.StockBalance, int64, error) {
+	args := m.Called(scope, query, paging)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.StockBalance), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockStockRepository) All(ctx context.Context) ([]models.StockBalance, error) {
+	args := m.Called()
+	return args.Get(0).([]models.StockBalance), args.Error(1)
+}
+
+func (m *MockStockRepository) LedgerTotals(ctx context.Context) ([]models.StockBalance, error) {
+	args := m.Called()
+	return args.Get(0).([]models.StockBalance), args.Error(1)
+}
+
+func (m *MockStockRepository) LockProducts(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockStockRepository) Atomic(ctx context.Context, fn func(repo transactionRepositories.StockRepository) error) error {
+	return fn(m)
+}
+
+func (m *MockStockRepository) ReplaceAll(ctx context.Context, balances []models.StockBalance) error {
+	args := m.Called(balances)
+	return args.Error(0)
+}
+
+func (m *MockStockRepository) SyncAllProductStock(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestStockService_List_FiltersAndPaging(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockStockRepository)
+	service := transaction.NewStockService(mockRepo)
+
+	query := &models.StockQuery{WarehouseID: 1}
+	mockRepo.On("List", models.AllWarehouses(), query, mock.MatchedBy(func(p *models.ListQuery) bool {
+		return p.Page == 1 && p.Limit == 10
+	})).Return([]models.StockBalance{{ProductID: 2, WarehouseID: 1, Quantity: 4}}, int64(1), nil)
+
+	// Act
+	response, err := service.List(context.Background(), models.AllWarehouses(), query)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, response.Items, 1)
+	assert.Equal(t, 4.0, response.Items[0].Quantity)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStockService_Reconcile_DryRunReportsDifferences(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockStockRepository)
+	service := transaction.NewStockService(mockRepo)
+
+	mockRepo.On("LockProducts").Return(nil)
+	mockRepo.On("LedgerTotals").Return([]models.StockBalance{
+		{ProductID: 1, WarehouseID: 1, Quantity: 10},
+		{ProductID: 1, WarehouseID: 2, Quantity: 5},
+		{ProductID: 2, WarehouseID: 1, Quantity: -1},
+	}, nil)
+	mockRepo.On("All").Return([]models.StockBalance{
+		{ProductID: 1, WarehouseID: 1, Quantity: 10},
+		{ProductID: 1, WarehouseID: 2, Quantity: 3},
+		{ProductID: 3, WarehouseID: 2, Quantity: 7},
+	}, nil)
+
+	// Act
+	result, err := service.Reconcile(context.Background(), true)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.Applied)
+	assert.Equal(t, 3, result.Balances)
+	assert.ElementsMatch(t, []models.StockDifference{
+		{ProductID: 1, WarehouseID: 2, Recorded: 3, Ledger: 5},
+		{ProductID: 2, WarehouseID: 1, Recorded: 0, Ledger: -1},
+		{ProductID: 3, WarehouseID: 2, Recorded: 7, Ledger: 0},
+	}, result.Differences)
+	assert.Len(t, result.Negative, 1)
+	mockRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything)
+}
+
+func TestStockService_Reconcile_RebuildsBalances(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockStockRepository)
+	service := transaction.NewStockService(mockRepo)
+
+	ledger := []models.StockBalance{{ProductID: 1, WarehouseID: 1, Quantity: 10}}
+	mockRepo.On("LockProducts").Return(nil)
+	mockRepo.On("LedgerTotals").Return(ledger, nil)
+	mockRepo.On("All").Return([]models.StockBalance{}, nil)
+	mockRepo.On("ReplaceAll", []models.StockBalance{{ProductID: 1, WarehouseID: 1, Quantity: 10}}).Return(nil)
+	mockRepo.On("SyncAllProductStock").Return(nil)
+
+	// Act
+	result, err := service.Reconcile(context.Background(), false)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Applied)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestStockService_Reconcile_LocksProductsBeforeReadingLedger(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockStockRepository)
+	service := transaction.NewStockService(mockRepo)
+
+	mockRepo.On("LockProducts").Return(errors.New("lock wait timeout"))
+
+	// Act
+	result, err := service.Reconcile(context.Background(), false)
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, result)
+	mockRepo.AssertNotCalled(t, "LedgerTotals")
+	mockRepo.AssertNotCalled(t, "ReplaceAll", mock.Anything)
+}
+
+func TestStockService_List_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockStockRepository)
+	service := transaction.NewStockService(mockRepo)
+
+	// Act
+	response, err := service.List(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{2}}, &models.StockQuery{WarehouseID: 1})
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, transaction.ErrWarehouseAccessDenied)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
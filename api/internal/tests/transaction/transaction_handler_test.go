@@ -0,0 +1,188 @@
+package transaction_test
+
+import (
+	"api/internal/handlers/transaction"
+	"api/internal/models"
+	transactionServices "api/internal/services/transaction"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockTransactionService is a mock implementation of TransactionService
+type MockTransactionService struct {
+	mock.Mock
+}
+
+func (m *MockTransactionService) List(ctx context.Context, scope models.WarehouseScope, query *models.TransactionListQuery) (*models.TransactionListResponse, error) {
+	args := m.Called(scope, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransactionListResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.TransactionResponse, error) {
+	args := m.Called(scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) Create(ctx context.Context, scope models.WarehouseScope, userID uint, req *models.TransactionRequest) (*models.TransactionResponse, error) {
+	args := m.Called(scope, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) Reverse(ctx context.Context, scope models.WarehouseScope, userID uint, id uint) (*models.TransactionResponse, error) {
+	args := m.Called(scope, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TransactionResponse), args.Error(1)
+}
+
+func (m *MockTransactionService) Delete(ctx context.Context, scope models.WarehouseScope, id uint) error {
+	args := m.Called(scope, id)
+	return args.Error(0)
+}
+
+// clerkScope is the warehouse scope set by the stub auth middleware
+var clerkScope = models.WarehouseScope{WarehouseIDs: []uint{1, 2}}
+
+// setupTransactionApp registers handlers behind a stub auth middleware setting userID and the warehouse scope
+func setupTransactionApp(mockService *MockTransactionService) *fiber.App {
+	app := fiber.New()
+	handler := transaction.NewTransactionHandler(mockService)
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userID", "7")
+		c.Locals("warehouseScope", clerkScope)
+		return c.Next()
+	})
+	app.Get("/transactions", handler.List)
+	app.Get("/transactions/:id", handler.Get)
+	app.Post("/transactions", handler.Create)
+	app.Post("/transactions/:id/reverse", handler.Reverse)
+	app.Delete("/transactions/:id", handler.Delete)
+	return app
+}
+
+func TestTransactionHandler_List_Filters(t *testing.T) {
+	// Arrange
+	mockService := new(MockTransactionService)
+	app := setupTransactionApp(mockService)
+
+	mockService.On("List", clerkScope, mock.MatchedBy(func(q *models.TransactionListQuery) bool {
+		return q.WarehouseID == 1 && q.ProductID == 2 && q.Type == "out" && q.Page == 2
+	})).Return(&models.TransactionListResponse{Items: []models.TransactionResponse{}}, nil)
+
+	req := httptest.NewRequest("GET", "/transactions?warehouse_id=1&product_id=2&type=out&page=2", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_Create_InvalidType(t *testing.T) {
+	// Arrange
+	mockService := new(MockTransactionService)
+	app := setupTransactionApp(mockService)
+
+	reqBody, _ := json.Marshal(models.TransactionRequest{WarehouseID: 1, ProductID: 2, Type: "move", Quantity: 1})
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionHandler_Create_InsufficientStock(t *testing.T) {
+	// Arrange
+	mockService := new(MockTransactionService)
+	app := setupTransactionApp(mockService)
+
+	mockService.On("Create", clerkScope, uint(7), mock.AnythingOfType("*models.TransactionRequest")).
+		Return(nil, &transactionServices.InsufficientStockError{ProductID: 2, Available: 5, Requested: 8})
+
+	reqBody, _ := json.Marshal(models.TransactionRequest{WarehouseID: 1, ProductID: 2, Type: "out", Quantity: 8})
+	req := httptest.NewRequest("POST", "/transactions", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	var response map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&response)
+	assert.Equal(t, "failed", response["message"])
+	assert.Equal(t, "insufficient stock", response["error"])
+	details := response["details"].(map[string]interface{})
+	assert.Equal(t, 5.0, details["available"])
+	assert.Equal(t, 8.0, details["requested"])
+}
+
+func TestTransactionHandler_Reverse_AlreadyReversed(t *testing.T) {
+	// Arrange
+	mockService := new(MockTransactionService)
+	app := setupTransactionApp(mockService)
+
+	mockService.On("Reverse", clerkScope, uint(7), uint(4)).Return(nil, transactionServices.ErrTransactionAlreadyReversed)
+
+	req := httptest.NewRequest("POST", "/transactions/4/reverse", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_Get_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockService := new(MockTransactionService)
+	app := setupTransactionApp(mockService)
+
+	mockService.On("GetByID", clerkScope, uint(4)).Return(nil, transactionServices.ErrWarehouseAccessDenied)
+
+	req := httptest.NewRequest("GET", "/transactions/4", nil)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "Forbidden", body["error"])
+	assert.Equal(t, transactionServices.ErrWarehouseAccessDenied.Error(), body["details"])
+
+	mockService.AssertExpectations(t)
+}
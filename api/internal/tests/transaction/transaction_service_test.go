@@ -0,0 +1,348 @@
+package transaction_test
+
+import (
+	"api/internal/models"
+	transactionRepositories "api/internal/repositories/transaction"
+	"api/internal/services/transaction"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockTransactionRepository is a mock implementation of TransactionRepository.
+// Atomic runs the callback against the mock itself.
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.TransactionListQuery) ([]models.Transaction, int64, error) {
+	args := m.Called(scope, query)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Transaction), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTransactionRepository) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) Create(ctx context.Context, trx *models.Transaction) error {
+	args := m.Called(trx)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) HasReversal(ctx context.Context, id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) WarehouseExists(ctx context.Context, id uint) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTransactionRepository) Atomic(ctx context.Context, fn func(repo transactionRepositories.TransactionRepository) error) error {
+	return fn(m)
+}
+
+func (m *MockTransactionRepository) LockByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) LockProduct(ctx context.Context, id uint) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockTransactionRepository) LockBalance(ctx context.Context, productID, warehouseID uint) (*models.StockBalance, error) {
+	args := m.Called(productID, warehouseID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockBalance), args.Error(1)
+}
+
+func (m *MockTransactionRepository) SaveBalance(ctx context.Context, balance *models.StockBalance) error {
+	args := m.Called(balance)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) SyncProductStock(ctx context.Context, productID uint) error {
+	args := m.Called(productID)
+	return args.Error(0)
+}
+
+// expectBalance sets up a locked product and its warehouse balance
+func expectBalance(m *MockTransactionRepository, productID, warehouseID uint, quantity float64) {
+	m.On("LockProduct", productID).Return(&models.Product{ID: productID}, nil)
+	m.On("LockBalance", productID, warehouseID).Return(&models.StockBalance{
+		ProductID: productID, WarehouseID: warehouseID, Quantity: quantity,
+	}, nil)
+}
+
+// expectBalanceSaved expects the balance to be saved with quantity and the product total synced
+func expectBalanceSaved(m *MockTransactionRepository, productID, warehouseID uint, quantity float64) {
+	m.On("SaveBalance", mock.MatchedBy(func(b *models.StockBalance) bool {
+		return b.ProductID == productID && b.WarehouseID == warehouseID && b.Quantity == quantity
+	})).Return(nil)
+	m.On("SyncProductStock", productID).Return(nil)
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func uintPtr(u uint) *uint {
+	return &u
+}
+
+func TestTransactionService_Create_OutInsufficientStock(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("WarehouseExists", uint(1)).Return(true, nil)
+	expectBalance(mockRepo, 2, 1, 5)
+
+	// Act
+	response, err := service.Create(context.Background(), models.AllWarehouses(), 7, &models.TransactionRequest{WarehouseID: 1, ProductID: 2, Type: "out", Quantity: 8})
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, transaction.ErrInsufficientStock)
+
+	var stockErr *transaction.InsufficientStockError
+	require.True(t, errors.As(err, &stockErr))
+	assert.Equal(t, uint(1), stockErr.WarehouseID)
+	assert.Equal(t, 5.0, stockErr.Available)
+	assert.Equal(t, 8.0, stockErr.Requested)
+
+	mockRepo.AssertNotCalled(t, "SaveBalance", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestTransactionService_Create_OutAdjustsStock(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("WarehouseExists", uint(1)).Return(true, nil)
+	expectBalance(mockRepo, 2, 1, 10)
+	expectBalanceSaved(mockRepo, 2, 1, 6.5)
+	mockRepo.On("Create", mock.MatchedBy(func(trx *models.Transaction) bool {
+		return *trx.Type == "out" && *trx.Quantity == 3.5 && *trx.UserID == 7
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 11
+	}).Return(nil)
+	mockRepo.On("GetByID", uint(11)).Return(&models.Transaction{ID: 11, Type: stringPtr("out")}, nil)
+
+	// Act
+	response, err := service.Create(context.Background(), models.AllWarehouses(), 7, &models.TransactionRequest{WarehouseID: 1, ProductID: 2, Type: "out", Quantity: 3.5})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(11), response.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_Create_WarehouseNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("WarehouseExists", uint(9)).Return(false, nil)
+
+	// Act
+	_, err := service.Create(context.Background(), models.AllWarehouses(), 7, &models.TransactionRequest{WarehouseID: 9, ProductID: 2, Type: "in", Quantity: 1})
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrWarehouseNotFound)
+}
+
+func TestTransactionService_Delete_RestoresStock(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(&models.Transaction{
+		ID: 4, WarehouseID: uintPtr(1), ProductID: uintPtr(2), Type: stringPtr("out"), Quantity: floatPtr(3),
+	}, nil)
+	mockRepo.On("HasReversal", uint(4)).Return(false, nil)
+	expectBalance(mockRepo, 2, 1, 7)
+	expectBalanceSaved(mockRepo, 2, 1, 10)
+	mockRepo.On("Delete", uint(4)).Return(nil)
+
+	// Act
+	err := service.Delete(context.Background(), models.AllWarehouses(), 4)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_Delete_InRejectedWhenStockAlreadyUsed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(&models.Transaction{
+		ID: 4, WarehouseID: uintPtr(1), ProductID: uintPtr(2), Type: stringPtr("in"), Quantity: floatPtr(10),
+	}, nil)
+	mockRepo.On("HasReversal", uint(4)).Return(false, nil)
+	expectBalance(mockRepo, 2, 1, 4)
+
+	// Act
+	err := service.Delete(context.Background(), models.AllWarehouses(), 4)
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrInsufficientStock)
+	mockRepo.AssertNotCalled(t, "Delete", uint(4))
+}
+
+func TestTransactionService_Delete_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	err := service.Delete(context.Background(), models.AllWarehouses(), 4)
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrTransactionNotFound)
+}
+
+func TestTransactionService_Reverse_PostsOppositeMovement(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(&models.Transaction{
+		ID: 4, WarehouseID: uintPtr(1), ProductID: uintPtr(2), Type: stringPtr("out"), Quantity: floatPtr(3),
+	}, nil)
+	mockRepo.On("HasReversal", uint(4)).Return(false, nil)
+	expectBalance(mockRepo, 2, 1, 7)
+	expectBalanceSaved(mockRepo, 2, 1, 10)
+	mockRepo.On("Create", mock.MatchedBy(func(trx *models.Transaction) bool {
+		return *trx.Type == "in" && *trx.ReversalOf == 4
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transaction).ID = 5
+	}).Return(nil)
+	mockRepo.On("GetByID", uint(5)).Return(&models.Transaction{ID: 5, ReversalOf: uintPtr(4)}, nil)
+
+	// Act
+	response, err := service.Reverse(context.Background(), models.AllWarehouses(), 7, 4)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), *response.ReversalOf)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTransactionService_Reverse_AlreadyReversed(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(&models.Transaction{
+		ID: 4, ProductID: uintPtr(2), Type: stringPtr("out"), Quantity: floatPtr(3),
+	}, nil)
+	mockRepo.On("HasReversal", uint(4)).Return(true, nil)
+
+	// Act
+	_, err := service.Reverse(context.Background(), models.AllWarehouses(), 7, 4)
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrTransactionAlreadyReversed)
+	mockRepo.AssertNotCalled(t, "LockProduct", mock.Anything)
+}
+
+func TestTransactionService_Reverse_RejectsTransferLeg(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(&models.Transaction{ID: 4, TransferID: uintPtr(5)}, nil)
+
+	// Act
+	_, reverseErr := service.Reverse(context.Background(), models.AllWarehouses(), 7, 4)
+	deleteErr := service.Delete(context.Background(), models.AllWarehouses(), 4)
+
+	// Assert
+	assert.ErrorIs(t, reverseErr, transaction.ErrTransactionPartOfTransfer)
+	assert.ErrorIs(t, deleteErr, transaction.ErrTransactionPartOfTransfer)
+	mockRepo.AssertNotCalled(t, "HasReversal", mock.Anything)
+}
+
+func TestTransactionService_Create_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	// Act
+	_, err := service.Create(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{2}}, 7, &models.TransactionRequest{WarehouseID: 1, ProductID: 2, Type: "in", Quantity: 1})
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrWarehouseAccessDenied)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestTransactionService_GetByID_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("GetByID", uint(4)).Return(&models.Transaction{ID: 4, WarehouseID: uintPtr(1)}, nil)
+
+	// Act
+	_, deniedErr := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{2}}, 4)
+	response, allowedErr := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{1}}, 4)
+
+	// Assert
+	assert.ErrorIs(t, deniedErr, transaction.ErrWarehouseAccessDenied)
+	require.NoError(t, allowedErr)
+	assert.Equal(t, uint(4), response.ID)
+}
+
+func TestTransactionService_Delete_OutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTransactionRepository)
+	service := transaction.NewTransactionService(mockRepo)
+
+	mockRepo.On("LockByID", uint(4)).Return(&models.Transaction{ID: 4, WarehouseID: uintPtr(1), Type: stringPtr("in"), Quantity: floatPtr(2)}, nil)
+
+	// Act
+	err := service.Delete(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{2}}, 4)
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrWarehouseAccessDenied)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
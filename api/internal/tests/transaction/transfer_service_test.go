@@ -0,0 +1,254 @@
+package transaction_test
+
+import (
+	"api/internal/models"
+	transactionRepositories "api/internal/repositories/transaction"
+	"api/internal/services/transaction"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MockTransferRepository is a mock implementation of TransferRepository.
+// Atomic runs the callback against the mock itself and Movements returns the shared movement mock.
+type MockTransferRepository struct {
+	mock.Mock
+	movements *MockTransactionRepository
+}
+
+func newMockTransferRepository() *MockTransferRepository {
+	return &MockTransferRepository{movements: new(MockTransactionRepository)}
+}
+
+func (m *MockTransferRepository) List(ctx context.Context, scope models.WarehouseScope, query *models.TransferListQuery, paging *models.ListQuery) ([]models.Transfer, int64, error) {
+	args := m.Called(scope, query, paging)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]models.Transfer), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockTransferRepository) GetByID(ctx context.Context, scope models.WarehouseScope, id uint) (*models.Transfer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) Create(ctx context.Context, transfer *models.Transfer) error {
+	args := m.Called(transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) Update(ctx context.Context, transfer *models.Transfer) error {
+	args := m.Called(transfer)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) Atomic(ctx context.Context, fn func(repo transactionRepositories.TransferRepository) error) error {
+	return fn(m)
+}
+
+func (m *MockTransferRepository) LockByID(ctx context.Context, id uint) (*models.Transfer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) Movements() transactionRepositories.TransactionRepository {
+	return m.movements
+}
+
+// expectTransferLeg expects a transaction row for one side of transfer 5
+func expectTransferLeg(m *MockTransactionRepository, trxType string, warehouseID uint, quantity float64) {
+	m.On("Create", mock.MatchedBy(func(trx *models.Transaction) bool {
+		return *trx.Type == trxType && *trx.WarehouseID == warehouseID && *trx.Quantity == quantity &&
+			trx.TransferID != nil && *trx.TransferID == 5
+	})).Return(nil)
+}
+
+func TestTransferService_Create_MovesStockImmediately(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	movements := mockRepo.movements
+	service := transaction.NewTransferService(mockRepo)
+
+	movements.On("WarehouseExists", uint(1)).Return(true, nil)
+	movements.On("WarehouseExists", uint(2)).Return(true, nil)
+	expectBalance(movements, 3, 1, 10)
+	movements.On("LockBalance", uint(3), uint(2)).Return(&models.StockBalance{ProductID: 3, WarehouseID: 2, Quantity: 1}, nil)
+	expectBalanceSaved(movements, 3, 1, 6)
+	expectBalanceSaved(movements, 3, 2, 5)
+	expectTransferLeg(movements, "out", 1, 4)
+	expectTransferLeg(movements, "in", 2, 4)
+
+	mockRepo.On("Create", mock.MatchedBy(func(tr *models.Transfer) bool {
+		return tr.Quantity == 4 && tr.Status == models.TransferStatusInTransit
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transfer).ID = 5
+	}).Return(nil)
+	mockRepo.On("Update", mock.MatchedBy(func(tr *models.Transfer) bool {
+		return tr.Status == models.TransferStatusCompleted && *tr.ReceivedQuantity == 4 && *tr.ReceivedBy == 7
+	})).Return(nil)
+	mockRepo.On("GetByID", uint(5)).Return(&models.Transfer{ID: 5, Status: models.TransferStatusCompleted}, nil)
+
+	// Act
+	response, err := service.Create(context.Background(), models.AllWarehouses(), 7, &models.TransferRequest{ProductID: 3, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 4})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusCompleted, response.Status)
+
+	mockRepo.AssertExpectations(t)
+	movements.AssertExpectations(t)
+}
+
+func TestTransferService_Create_InTransitOnlyPostsOut(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	movements := mockRepo.movements
+	service := transaction.NewTransferService(mockRepo)
+
+	movements.On("WarehouseExists", uint(1)).Return(true, nil)
+	movements.On("WarehouseExists", uint(2)).Return(true, nil)
+	expectBalance(movements, 3, 1, 10)
+	expectBalanceSaved(movements, 3, 1, 6)
+	expectTransferLeg(movements, "out", 1, 4)
+
+	mockRepo.On("Create", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.Transfer).ID = 5
+	}).Return(nil)
+	mockRepo.On("GetByID", uint(5)).Return(&models.Transfer{ID: 5, Status: models.TransferStatusInTransit}, nil)
+
+	// Act
+	response, err := service.Create(context.Background(), models.AllWarehouses(), 7, &models.TransferRequest{ProductID: 3, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 4, InTransit: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusInTransit, response.Status)
+
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	movements.AssertExpectations(t)
+}
+
+func TestTransferService_Create_InsufficientStockAtSource(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	movements := mockRepo.movements
+	service := transaction.NewTransferService(mockRepo)
+
+	movements.On("WarehouseExists", uint(1)).Return(true, nil)
+	movements.On("WarehouseExists", uint(2)).Return(true, nil)
+	expectBalance(movements, 3, 1, 2)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+
+	// Act
+	response, err := service.Create(context.Background(), models.AllWarehouses(), 7, &models.TransferRequest{ProductID: 3, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 4})
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, transaction.ErrInsufficientStock)
+	movements.AssertNotCalled(t, "SaveBalance", mock.Anything)
+}
+
+func TestTransferService_Receive_Partial(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	movements := mockRepo.movements
+	service := transaction.NewTransferService(mockRepo)
+
+	mockRepo.On("LockByID", uint(5)).Return(&models.Transfer{
+		ID: 5, ProductID: 3, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 4, Status: models.TransferStatusInTransit,
+	}, nil)
+	expectBalance(movements, 3, 2, 0)
+	expectBalanceSaved(movements, 3, 2, 3)
+	expectTransferLeg(movements, "in", 2, 3)
+	mockRepo.On("Update", mock.MatchedBy(func(tr *models.Transfer) bool {
+		return tr.Status == models.TransferStatusCompleted && *tr.ReceivedQuantity == 3
+	})).Return(nil)
+	mockRepo.On("GetByID", uint(5)).Return(&models.Transfer{ID: 5, Status: models.TransferStatusCompleted}, nil)
+
+	// Act
+	_, err := service.Receive(context.Background(), models.AllWarehouses(), 8, 5, &models.TransferReceiveRequest{Quantity: floatPtr(3)})
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	movements.AssertExpectations(t)
+}
+
+func TestTransferService_Receive_RejectsExcessAndCompleted(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	service := transaction.NewTransferService(mockRepo)
+
+	mockRepo.On("LockByID", uint(5)).Return(&models.Transfer{ID: 5, Quantity: 4, Status: models.TransferStatusInTransit}, nil)
+	mockRepo.On("LockByID", uint(6)).Return(&models.Transfer{ID: 6, Quantity: 4, Status: models.TransferStatusCompleted}, nil)
+	mockRepo.On("LockByID", uint(9)).Return(nil, gorm.ErrRecordNotFound)
+
+	// Act
+	_, excessErr := service.Receive(context.Background(), models.AllWarehouses(), 8, 5, &models.TransferReceiveRequest{Quantity: floatPtr(5)})
+	_, completedErr := service.Receive(context.Background(), models.AllWarehouses(), 8, 6, &models.TransferReceiveRequest{Quantity: floatPtr(1)})
+	_, missingErr := service.Receive(context.Background(), models.AllWarehouses(), 8, 9, &models.TransferReceiveRequest{Quantity: floatPtr(1)})
+
+	// Assert
+	assert.ErrorIs(t, excessErr, transaction.ErrReceivedQuantityTooLarge)
+	assert.ErrorIs(t, completedErr, transaction.ErrTransferNotInTransit)
+	assert.ErrorIs(t, missingErr, transaction.ErrTransferNotFound)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestTransferService_Create_SourceOutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	service := transaction.NewTransferService(mockRepo)
+
+	// Act
+	_, err := service.Create(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{2}}, 7, &models.TransferRequest{ProductID: 3, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 4})
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrWarehouseAccessDenied)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestTransferService_Receive_DestinationOutsideWarehouseScope(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	service := transaction.NewTransferService(mockRepo)
+
+	mockRepo.On("LockByID", uint(5)).Return(&models.Transfer{
+		ID: 5, ProductID: 3, FromWarehouseID: 1, ToWarehouseID: 2, Quantity: 4, Status: models.TransferStatusInTransit,
+	}, nil)
+
+	// Act
+	_, err := service.Receive(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{1}}, 8, 5, &models.TransferReceiveRequest{Quantity: floatPtr(4)})
+
+	// Assert
+	assert.ErrorIs(t, err, transaction.ErrWarehouseAccessDenied)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestTransferService_GetByID_EitherWarehouseInScope(t *testing.T) {
+	// Arrange
+	mockRepo := newMockTransferRepository()
+	service := transaction.NewTransferService(mockRepo)
+
+	mockRepo.On("GetByID", uint(5)).Return(&models.Transfer{ID: 5, FromWarehouseID: 1, ToWarehouseID: 2}, nil)
+
+	// Act
+	_, sourceErr := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{1}}, 5)
+	_, destinationErr := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{2}}, 5)
+	_, otherErr := service.GetByID(context.Background(), models.WarehouseScope{WarehouseIDs: []uint{3}}, 5)
+
+	// Assert
+	assert.NoError(t, sourceErr)
+	assert.NoError(t, destinationErr)
+	assert.ErrorIs(t, otherErr, transaction.ErrWarehouseAccessDenied)
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormLogger routes GORM's logs through a slog logger, so SQL errors and slow queries share the
+// application's format, output and request attributes. Queries run with db.WithContext(ctx)
+// carry the attributes of ctx.
+type GormLogger struct {
+	logger        *slog.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger logs failed queries at error level and queries slower than slowThreshold at
+// warn level. With level gormlogger.Info every query is logged at debug level.
+func NewGormLogger(logger *slog.Logger, level gormlogger.LogLevel, slowThreshold time.Duration) *GormLogger {
+	return &GormLogger{logger: logger, level: level, slowThreshold: slowThreshold}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, data...), "component", "gorm")
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, data...), "component", "gorm")
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, data...), "component", "gorm")
+	}
+}
+
+// Trace logs one executed statement. Missing records are expected and not logged as errors.
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && l.level >= gormlogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "query failed", queryAttrs(sql, rows, elapsed, slog.String("error", err.Error()))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "slow query", queryAttrs(sql, rows, elapsed, slog.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "query", queryAttrs(sql, rows, elapsed)...)
+	}
+}
+
+func queryAttrs(sql string, rows int64, elapsed time.Duration, extra ...slog.Attr) []any {
+	attrs := []any{
+		slog.String("component", "gorm"),
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("latency", elapsed),
+	}
+	for _, attr := range extra {
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
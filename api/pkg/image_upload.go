@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// MaxImageSize is the default maximum image upload size in bytes (2 MB), see UPLOAD_MAX_IMAGE_SIZE
+const MaxImageSize = 2 << 20
+
+var (
+	ErrInvalidImageType = errors.New("image must be a jpeg, png, gif or webp file")
+	ErrImageTooLarge    = errors.New("image is too large")
+)
+
+// GetAllowedImageTypes returns map of allowed image MIME types to file extensions
+func GetAllowedImageTypes() map[string]string {
+	return map[string]string{
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/gif":  ".gif",
+		"image/webp": ".webp",
+	}
+}
+
+// ValidateImage checks the upload is at most maxSize bytes and sniffs the content type, returning
+// the file extension to use
+func ValidateImage(file *multipart.FileHeader, maxSize int64) (string, error) {
+	if file.Size > maxSize {
+		return "", fmt.Errorf("%w: the limit is %d bytes", ErrImageTooLarge, maxSize)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer src.Close()
+
+	// Detect MIME type from content instead of trusting the client header
+	header := make([]byte, 512)
+	n, err := io.ReadFull(src, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+
+	ext, ok := GetAllowedImageTypes()[http.DetectContentType(header[:n])]
+	if !ok {
+		return "", ErrInvalidImageType
+	}
+	return ext, nil
+}
+
+// SaveImage validates the uploaded image and stores it in dir under a generated name
+func SaveImage(file *multipart.FileHeader, dir string, maxSize int64) (string, error) {
+	ext, err := ValidateImage(file, maxSize)
+	if err != nil {
+		return "", err
+	}
+
+	name, err := generateFileName(ext)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create image folder: %w", err)
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open image: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create image file: %w", err)
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(filepath.Join(dir, name))
+		return "", fmt.Errorf("failed to write image file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(filepath.Join(dir, name))
+		return "", fmt.Errorf("failed to write image file: %w", err)
+	}
+
+	return name, nil
+}
+
+// DeleteImage removes a stored image from dir, ignoring files that no longer exist
+func DeleteImage(dir, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	// Only ever remove a plain file name inside dir
+	err := os.Remove(filepath.Join(dir, filepath.Base(name)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+	return nil
+}
+
+// GetImageURL returns the public URL of an image served from the /assets static route
+func GetImageURL(folderName, name string) string {
+	return "/assets/images/" + folderName + "/" + name
+}
+
+// generateFileName returns a random hex file name with the given extension
+func generateFileName(ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate file name: %w", err)
+	}
+	return hex.EncodeToString(buf) + ext, nil
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Lifecycle tracks what the server started so it can be stopped in order. Shutdown runs the
+// stop hooks in reverse registration order, like defer: register the database first and the
+// HTTP server last, and the server drains before the workers stop and the pool closes.
+type Lifecycle struct {
+	mu    sync.Mutex
+	hooks []stopHook
+}
+
+type stopHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// NewLifecycle creates an empty lifecycle
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// OnStop registers a hook to run during Shutdown. The hook should return once ctx is done.
+func (l *Lifecycle) OnStop(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, stopHook{name: name, stop: stop})
+}
+
+// Go runs a background worker until Shutdown reaches it. The worker must return when its
+// context is cancelled; Shutdown waits for it until the shutdown deadline.
+func (l *Lifecycle) Go(name string, worker func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker(ctx)
+	}()
+
+	l.OnStop(name, func(shutdownCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	})
+}
+
+// Shutdown runs every stop hook, last registered first, even when an earlier one fails, and
+// returns the joined errors. Hooks share ctx, so its deadline bounds the whole shutdown.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.hooks = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		log.Printf("Stopping %s", hook.name)
+		if err := hook.stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
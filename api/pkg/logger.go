@@ -0,0 +1,128 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// LoggerConfig holds the settings of NewLogger
+type LoggerConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // json or text
+	Stdout bool   // also write to stdout when File is set
+	File   RotatingFileConfig
+}
+
+// NewLogger creates a slog logger writing to stdout and/or a rotating file. The returned closer
+// closes the file; it is a no-op when logging to stdout only. Records include the attributes
+// added to their context with WithLogAttrs.
+func NewLogger(cfg LoggerConfig) (*slog.Logger, io.Closer, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+
+	// stdout first: io.MultiWriter stops at the first failing writer, e.g. the closed file
+	var writers []io.Writer
+	if cfg.File.Path == "" || cfg.Stdout {
+		writers = append(writers, os.Stdout)
+	}
+	var closer io.Closer = nopCloser{}
+	if cfg.File.Path != "" {
+		file, err := NewRotatingFile(cfg.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		writers = append(writers, file)
+		closer = file
+	}
+	out := io.MultiWriter(writers...)
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "json":
+		handler = slog.NewJSONHandler(out, options)
+	case "text":
+		handler = slog.NewTextHandler(out, options)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+	return slog.New(NewContextHandler(handler)), closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+type logAttrsKey struct{}
+
+// logAttrs is shared by every context derived from the one WithLogAttrs created, so attributes
+// added later, like the user ID after authentication, reach the whole request
+type logAttrs struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithLogAttrs returns a context whose log records carry attrs. Attributes added to a context
+// that already has some are shared with its parent, see AddLogAttrs.
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	if AddLogAttrs(ctx, attrs...) {
+		return ctx
+	}
+	return context.WithValue(ctx, logAttrsKey{}, &logAttrs{attrs: attrs})
+}
+
+// AddLogAttrs adds attrs to the attributes of ctx and reports whether ctx had any to add to
+func AddLogAttrs(ctx context.Context, attrs ...slog.Attr) bool {
+	fields, ok := ctx.Value(logAttrsKey{}).(*logAttrs)
+	if !ok {
+		return false
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	fields.attrs = append(fields.attrs, attrs...)
+	return true
+}
+
+// LogAttrs returns the attributes added to ctx
+func LogAttrs(ctx context.Context) []slog.Attr {
+	fields, ok := ctx.Value(logAttrsKey{}).(*logAttrs)
+	if !ok {
+		return nil
+	}
+	fields.mu.Lock()
+	defer fields.mu.Unlock()
+	return append([]slog.Attr(nil), fields.attrs...)
+}
+
+// ContextHandler adds the attributes of the record's context to every record
+type ContextHandler struct {
+	slog.Handler
+}
+
+// NewContextHandler wraps handler with ContextHandler
+func NewContextHandler(handler slog.Handler) *ContextHandler {
+	return &ContextHandler{Handler: handler}
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		record.AddAttrs(LogAttrs(ctx)...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
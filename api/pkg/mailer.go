@@ -0,0 +1,114 @@
+package pkg
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MailMessage is a plain-text email
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails
+type Mailer interface {
+	Send(message MailMessage) error
+}
+
+// SMTPConfig holds the SMTP server settings of SMTPMailer
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer sends emails through an SMTP server, using PLAIN auth when a username is set
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+func (m *SMTPMailer) Send(message MailMessage) error {
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	address := net.JoinHostPort(m.config.Host, m.config.Port)
+	if err := smtp.SendMail(address, auth, m.config.From, []string{message.To}, formatMail(m.config.From, message)); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", message.To, err)
+	}
+	return nil
+}
+
+// LogMailer writes emails to a file instead of sending them, for local development and tests.
+// With an empty path the emails go to the standard logger.
+type LogMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{path: path}
+}
+
+func (m *LogMailer) Send(message MailMessage) error {
+	content := formatMail("", message)
+	if m.path == "" {
+		log.Printf("Mail (not sent):\n%s", content)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("failed to create mail log directory: %w", err)
+	}
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "=== %s ===\n%s\n\n", time.Now().Format(time.RFC3339), content)
+	return err
+}
+
+// NewMailer returns an SMTPMailer for driver "smtp", otherwise a LogMailer writing to logPath
+func NewMailer(driver, logPath string, smtp SMTPConfig) Mailer {
+	if driver == "smtp" {
+		return NewSMTPMailer(smtp)
+	}
+	return NewLogMailer(logPath)
+}
+
+// formatMail renders message as an RFC 5322 plain-text email; header lines are stripped of line breaks
+func formatMail(from string, message MailMessage) []byte {
+	clean := strings.NewReplacer("\r", "", "\n", "")
+
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", clean.Replace(from))
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", clean.Replace(message.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", clean.Replace(message.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(message.Body)
+	return []byte(b.String())
+}
@@ -0,0 +1,372 @@
+package pkg
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrOIDCRejected means the provider refused the authorization code, e.g. it expired or was used
+	ErrOIDCRejected = errors.New("identity provider rejected the authorization code")
+	// ErrOIDCInvalidIDToken means the ID token failed signature or claim validation
+	ErrOIDCInvalidIDToken = errors.New("invalid ID token")
+)
+
+// oidcKeyRefreshInterval limits how often an unknown kid triggers a JWKS refetch
+const oidcKeyRefreshInterval = time.Minute
+
+// OIDCConfig holds the relying party settings of an OIDCProvider
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string // empty for public clients
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCClaims are the verified ID token claims used to find or create the user
+type OIDCClaims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// oidcMetadata is the part of the discovery document (OpenID Connect Discovery 1.0) we use
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider is an OpenID Connect relying party for the authorization code flow with PKCE.
+// The provider is discovered from its issuer URL on first use, so the API starts even when the
+// provider is unreachable.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the provider URL that starts a sign-in; the provider redirects back to
+// RedirectURL with a code and state
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeVerifier string) (string, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization_endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", PKCEChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems an authorization code at the token endpoint and returns the raw ID token
+func (p *OIDCProvider) Exchange(code, codeVerifier string) (string, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequest(http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		// client_secret_basic, with the form encoding RFC 6749 section 2.3.1 asks for
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("%w: %s %s", ErrOIDCRejected, body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, body.Error)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", ErrOIDCInvalidIDToken)
+	}
+	return body.IDToken, nil
+}
+
+// idTokenClaims are the ID token claims (OpenID Connect Core 1.0 section 2) we check or map
+type idTokenClaims struct {
+	Nonce           string   `json:"nonce"`
+	AuthorizedParty string   `json:"azp"`
+	Email           string   `json:"email"`
+	EmailVerified   oidcBool `json:"email_verified"`
+	Name            string   `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// oidcBool accepts true and "true"; some providers send email_verified as a string
+type oidcBool bool
+
+func (b *oidcBool) UnmarshalJSON(data []byte) error {
+	*b = oidcBool(strings.Trim(string(data), `"`) == "true")
+	return nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *OIDCProvider) VerifyIDToken(rawIDToken, nonce string) (*OIDCClaims, error) {
+	metadata, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, p.keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCInvalidIDToken, err)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCInvalidIDToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: azp does not match the client ID", ErrOIDCInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrOIDCInvalidIDToken)
+	}
+
+	return &OIDCClaims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}, nil
+}
+
+// GeneratePKCEVerifier returns a random code_verifier (RFC 7636) of 43 characters
+func GeneratePKCEVerifier() (string, error) {
+	return randomURLToken(32)
+}
+
+// GenerateOIDCNonce returns a random value for the state or nonce parameter
+func GenerateOIDCNonce() (string, error) {
+	return randomURLToken(32)
+}
+
+// PKCEChallenge returns the S256 code_challenge of a code_verifier
+func PKCEChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomURLToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// discover fetches and caches the discovery document of the issuer
+func (p *OIDCProvider) discover() (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata oidcMetadata
+	discoveryURL := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	// The issuer must match exactly, otherwise tokens of another issuer could pass the iss check
+	if metadata.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", metadata.Issuer, p.config.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing an endpoint")
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// keyFunc picks the provider key by kid, refetching the JWKS when the provider rotated its keys
+func (p *OIDCProvider) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.lookupKey(kid)
+	if !ok && time.Since(p.keysFetchedAt) > oidcKeyRefreshInterval {
+		if err := p.fetchKeys(); err != nil {
+			return nil, err
+		}
+		key, ok = p.lookupKey(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// lookupKey finds a key by kid; a token without kid may use the only key. The caller holds mu.
+func (p *OIDCProvider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// fetchKeys loads the provider's signing keys; the caller holds mu
+func (p *OIDCProvider) fetchKeys() error {
+	var set struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := p.getJSON(p.metadata.JWKSURI, &set); err != nil {
+		return fmt.Errorf("failed to fetch OIDC provider keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of other types, e.g. for encryption, are skipped rather than failing the set
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return nil
+}
+
+func (p *OIDCProvider) getJSON(url string, target interface{}) error {
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// oidcJWK is a public key of the provider's JWKS (RFC 7517)
+type oidcJWK struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+func (k oidcJWK) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+	}
+}
@@ -0,0 +1,330 @@
+// Package oidctest is a minimal OpenID Connect provider for tests and local development.
+// It approves every sign-in without a password, so it must never face real users.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	codeTTL    = time.Minute
+	idTokenTTL = 5 * time.Minute
+)
+
+// User is an account of the provider
+type User struct {
+	Subject       string
+	Email         string
+	Name          string
+	EmailVerified bool
+}
+
+// authCode is an issued authorization code waiting to be redeemed
+type authCode struct {
+	user          User
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	expiresAt     time.Time
+}
+
+// Provider serves discovery, authorize, token and JWKS endpoints for one client
+type Provider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // empty accepts public clients without authentication
+
+	key   *rsa.PrivateKey
+	keyID string
+
+	mu    sync.Mutex
+	users map[string]User // by email
+	codes map[string]authCode
+}
+
+// New creates a provider with a fresh RSA signing key
+func New(issuer, clientID, clientSecret string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := randomToken(8)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		keyID:        keyID,
+		users:        make(map[string]User),
+		codes:        make(map[string]authCode),
+	}, nil
+}
+
+// AddUser registers or replaces an account. Unknown emails signing in are created with a
+// verified email, so adding users is only needed to control the subject or verification.
+func (p *Provider) AddUser(user User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.users[strings.ToLower(user.Email)] = user
+}
+
+// Handler returns the HTTP handler of the provider endpoints
+func (p *Provider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	return mux
+}
+
+// Server is a Provider listening on a local httptest server
+type Server struct {
+	*Provider
+	server *httptest.Server
+}
+
+// NewServer starts a provider on a local port; its issuer is the server URL
+func NewServer(clientID, clientSecret string) (*Server, error) {
+	provider, err := New("", clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	server := httptest.NewServer(provider.Handler())
+	provider.Issuer = server.URL
+	return &Server{Provider: provider, server: server}, nil
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SignIn follows authorizationURL as the given email and returns the code and state the
+// provider redirects back with, like a browser would
+func (s *Server) SignIn(authorizationURL, email string) (code, state string, err error) {
+	authURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		return "", "", err
+	}
+	query := authURL.Query()
+	query.Set("login_hint", email)
+	authURL.RawQuery = query.Encode()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL.String())
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		return "", "", fmt.Errorf("authorize returned status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+var signInForm = template.Must(template.New("sign-in").Parse(`<!DOCTYPE html>
+<html><body>
+<h1>Test identity provider</h1>
+<form method="get" action="/authorize">
+{{range $name, $values := .}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<label>Email <input type="email" name="login_hint" required autofocus></label>
+<button type="submit">Sign in</button>
+</form>
+</body></html>`))
+
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != p.ClientID || query.Get("redirect_uri") == "" {
+		http.Error(w, "unknown client_id or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "only the code flow with S256 PKCE is supported", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(query.Get("login_hint")))
+	if email == "" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = signInForm.Execute(w, query)
+		return
+	}
+
+	p.mu.Lock()
+	user, ok := p.users[email]
+	if !ok {
+		user = User{Subject: "user-" + email, Email: email, Name: strings.Split(email, "@")[0], EmailVerified: true}
+		p.users[email] = user
+	}
+	code, err := randomToken(16)
+	if err == nil {
+		p.codes[code] = authCode{
+			user:          user,
+			redirectURI:   query.Get("redirect_uri"),
+			nonce:         query.Get("nonce"),
+			codeChallenge: query.Get("code_challenge"),
+			expiresAt:     time.Now().Add(codeTTL),
+		}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+	if !p.authenticateClient(r) {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	code, ok := p.codes[r.PostForm.Get("code")]
+	// Codes are single-use, even when the exchange below fails
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(code.expiresAt) || code.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(verifier[:]) != code.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	idToken, err := p.signIDToken(code.user, code.nonce)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	accessToken, err := randomToken(16)
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(idTokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+// authenticateClient accepts client_secret_basic and client_secret_post
+func (p *Provider) authenticateClient(r *http.Request) bool {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	return clientID == p.ClientID && clientSecret == p.ClientSecret
+}
+
+func (p *Provider) signIDToken(user User, nonce string) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            user.Subject,
+		"aud":            p.ClientID,
+		"azp":            p.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(idTokenTTL).Unix(),
+		"nonce":          nonce,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	})
+	token.Header["kid"] = p.keyID
+	return token.SignedString(p.key)
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	publicKey := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": p.keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", errors.New("failed to read random bytes")
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
@@ -0,0 +1,21 @@
+package pkg
+
+import (
+	"context"
+	"log/slog"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID; records logged with it, including
+// GORM's for queries run with db.WithContext, get a request_id attribute
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return WithLogAttrs(ctx, slog.String("request_id", requestID))
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or "" when there is none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
@@ -0,0 +1,139 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileConfig holds the settings of RotatingFile. Zero MaxSize or MaxAge disables that
+// trigger; zero MaxBackups keeps every rotated file.
+type RotatingFileConfig struct {
+	Path       string
+	MaxSize    int64         // bytes
+	MaxAge     time.Duration // rotate files older than this, e.g. daily
+	MaxBackups int
+}
+
+// RotatingFile is an append-only log file that is renamed to <name>-<timestamp>.log when it
+// grows past MaxSize or gets older than MaxAge. Rotated files keep the .log extension, so
+// cmd/cronjob still cleans them up.
+type RotatingFile struct {
+	config   RotatingFileConfig
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewRotatingFile opens or creates the file at cfg.Path, creating its directory if needed
+func NewRotatingFile(cfg RotatingFileConfig) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file := &RotatingFile{config: cfg}
+	if err := file.open(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+// Write appends p, rotating first when p would not fit or the file is too old.
+// A failed rotation is returned, but p is still written so logging carries on.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	tooBig := f.config.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.config.MaxSize
+	tooOld := f.config.MaxAge > 0 && time.Since(f.openedAt) >= f.config.MaxAge
+	if tooBig || tooOld {
+		rotateErr = f.rotate()
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// rotate renames the current file and starts a new one. The file is renamed while still open,
+// so a failed rename or open leaves a usable file behind.
+func (f *RotatingFile) rotate() error {
+	base := strings.TrimSuffix(f.config.Path, ".log")
+	rotated := fmt.Sprintf("%s-%s.log", base, time.Now().Format("20060102T150405.000"))
+	if err := os.Rename(f.config.Path, rotated); err != nil {
+		// Reopen the path, e.g. recreating a file that was deleted, or keep the current one
+		f.reopen()
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := f.reopen(); err != nil {
+		return err
+	}
+	return f.removeOldBackups(base)
+}
+
+// reopen opens a new handle on the path and closes the old one; the old one stays in use if opening fails
+func (f *RotatingFile) reopen() error {
+	current := f.file
+	if err := f.open(); err != nil {
+		return err
+	}
+	return current.Close()
+}
+
+// removeOldBackups keeps the newest MaxBackups rotated files; the timestamps sort by age
+func (f *RotatingFile) removeOldBackups(base string) error {
+	if f.config.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := filepath.Glob(base + "-*.log")
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for len(backups) > f.config.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			errs = append(errs, err)
+		}
+		backups = backups[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the file; later writes fail with os.ErrClosed
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -3,6 +3,7 @@ package pkg
 import (
 	"fmt"
 	"log"
+	"log/slog"
 	"runtime"
 )
 
@@ -81,17 +82,16 @@ func SafeExecuteWithCallback(fn func() error, onPanic func(interface{})) (err er
 	return fn()
 }
 
-// LogError logs error with context
+// LogError logs error with context at error level through the default slog logger
 func LogError(err error, context string) {
 	if err != nil {
-		log.Printf("ERROR [%s]: %v", context, err)
+		slog.Error(context, "error", err.Error())
 	}
 }
 
-// LogErrorf logs error with formatted context
+// LogErrorf logs error with formatted context at error level through the default slog logger
 func LogErrorf(err error, format string, args ...interface{}) {
 	if err != nil {
-		context := fmt.Sprintf(format, args...)
-		log.Printf("ERROR [%s]: %v", context, err)
+		slog.Error(fmt.Sprintf(format, args...), "error", err.Error())
 	}
 }
\ No newline at end of file